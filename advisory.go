@@ -0,0 +1,102 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// advisoryUnlockTimeout bounds the unlock after fn returns. It runs even if
+// the caller's context has ended, so it needs a limit of its own; when it is
+// hit the connection is closed, which drops the lock.
+const advisoryUnlockTimeout = 5 * time.Second
+
+// WithAdvisoryLock runs fn while holding the session-level advisory lock
+// key, waiting for the lock if another session holds it. The lock is taken
+// and released on one dedicated write-pool connection, which is what
+// pg_advisory_lock requires; fn is free to use db (or anything else) for its
+// own queries.
+//
+// The lock is always released when fn returns, even if fn fails or ctx is
+// cancelled. If the unlock itself fails, the connection is closed rather
+// than returned to the pool, which makes the server drop the lock.
+//
+// Example:
+//
+//	err := db.WithAdvisoryLock(ctx, migrationLockKey, func(ctx context.Context) error {
+//	    return runMigrations(ctx, db)
+//	})
+func (db *DB) WithAdvisoryLock(ctx context.Context, key int64, fn func(ctx context.Context) error) error {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+		conn.Release()
+		return fmt.Errorf("failed to acquire advisory lock %d: %w", key, err)
+	}
+	return runLocked(ctx, conn, key, fn)
+}
+
+// TryAdvisoryLock is WithAdvisoryLock without the wait: if the lock is free
+// it runs fn and reports true; if another session holds it, it returns false
+// straight away without running fn. Useful for jobs where only one instance
+// should run at a time and the others can simply skip.
+//
+// Example:
+//
+//	ran, err := db.TryAdvisoryLock(ctx, cleanupLockKey, func(ctx context.Context) error {
+//	    return purgeExpiredSessions(ctx, db)
+//	})
+//	if err == nil && !ran {
+//	    log.Print("cleanup already running elsewhere")
+//	}
+func (db *DB) TryAdvisoryLock(ctx context.Context, key int64, fn func(ctx context.Context) error) (bool, error) {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	var acquired bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		conn.Release()
+		return false, fmt.Errorf("failed to try advisory lock %d: %w", key, err)
+	}
+	if !acquired {
+		conn.Release()
+		return false, nil
+	}
+	return true, runLocked(ctx, conn, key, fn)
+}
+
+// runLocked runs fn and then releases the advisory lock key held by conn,
+// discarding conn if the unlock fails. The unlock and release are deferred,
+// so a panicking fn does not leak the connection or leave the lock held.
+func runLocked(ctx context.Context, conn *Conn, key int64, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if unlockErr := releaseLock(ctx, conn, key); unlockErr != nil {
+			err = errors.Join(err, unlockErr)
+		}
+	}()
+	return fn(ctx)
+}
+
+// releaseLock unlocks key and releases conn, closing it first if the unlock
+// fails.
+func releaseLock(ctx context.Context, conn *Conn, key int64) error {
+	defer conn.Release()
+
+	// Unlock even when ctx is already cancelled; leaving the lock held on a
+	// pooled connection would block every other session indefinitely.
+	unlockCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), advisoryUnlockTimeout)
+	defer cancel()
+	var unlocked bool
+	if err := conn.QueryRow(unlockCtx, "SELECT pg_advisory_unlock($1)", key).Scan(&unlocked); err != nil || !unlocked {
+		_ = conn.Conn.Conn().Close(unlockCtx)
+		if err == nil {
+			err = fmt.Errorf("lock was not held")
+		}
+		return fmt.Errorf("failed to release advisory lock %d: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAdvisoryLockNotConnected(t *testing.T) {
+	db := NewDB()
+	ctx := context.Background()
+	called := false
+	fn := func(ctx context.Context) error {
+		called = true
+		return nil
+	}
+
+	if err := db.WithAdvisoryLock(ctx, 1, fn); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected not connected error, got %v", err)
+	}
+	if ran, err := db.TryAdvisoryLock(ctx, 1, fn); ran || err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected not connected error, got %v, %v", ran, err)
+	}
+	if called {
+		t.Error("fn should not run without the lock")
+	}
+}
+
+func newAdvisoryTestDB(t *testing.T) *DB {
+	t.Helper()
+	db := NewDB()
+	db.writePool = newIsolatedTestPool(t)
+	db.readPool = db.writePool
+	return db
+}
+
+func TestWithAdvisoryLockSerializes(t *testing.T) {
+	db := newAdvisoryTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	const key = 731001
+
+	var running, runs atomic.Int32
+	var overlapped atomic.Bool
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- db.WithAdvisoryLock(ctx, key, func(ctx context.Context) error {
+				if running.Add(1) > 1 {
+					overlapped.Store(true)
+				}
+				time.Sleep(100 * time.Millisecond)
+				running.Add(-1)
+				runs.Add(1)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("WithAdvisoryLock failed: %v", err)
+		}
+	}
+	if runs.Load() != 2 {
+		t.Errorf("expected both callers to run, got %d", runs.Load())
+	}
+	if overlapped.Load() {
+		t.Error("expected callers to run one at a time")
+	}
+	if db.InFlight() != 0 {
+		t.Errorf("lock connections should be released, %d in flight", db.InFlight())
+	}
+}
+
+func TestTryAdvisoryLockSkipsWhenHeld(t *testing.T) {
+	db := newAdvisoryTestDB(t)
+	ctx := context.Background()
+	const key = 731002
+
+	held := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		_ = db.WithAdvisoryLock(ctx, key, func(ctx context.Context) error {
+			close(held)
+			<-done
+			return nil
+		})
+	}()
+	<-held
+
+	ran, err := db.TryAdvisoryLock(ctx, key, func(ctx context.Context) error {
+		t.Error("fn should not run while another session holds the lock")
+		return nil
+	})
+	if err != nil || ran {
+		t.Errorf("expected (false, nil) while the lock is held, got (%v, %v)", ran, err)
+	}
+	close(done)
+
+	fnErr := errors.New("job failed")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ran, err = db.TryAdvisoryLock(ctx, key, func(ctx context.Context) error { return fnErr })
+		if ran || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !ran || !errors.Is(err, fnErr) {
+		t.Errorf("expected fn to run once the lock is free and its error returned, got (%v, %v)", ran, err)
+	}
+}
+
+func TestWithAdvisoryLockReleasesOnPanic(t *testing.T) {
+	db := newAdvisoryTestDB(t)
+	ctx := context.Background()
+	const key = 731003
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("expected the panic to propagate, got %v", r)
+			}
+		}()
+		_ = db.WithAdvisoryLock(ctx, key, func(ctx context.Context) error {
+			panic("boom")
+		})
+	}()
+
+	if n := db.InFlight(); n != 0 {
+		t.Errorf("expected the connection to be released after the panic, got %d in flight", n)
+	}
+	ran, err := db.TryAdvisoryLock(ctx, key, func(ctx context.Context) error { return nil })
+	if err != nil || !ran {
+		t.Errorf("expected the lock to be free after the panic, got (%v, %v)", ran, err)
+	}
+}
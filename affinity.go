@@ -0,0 +1,207 @@
+package pgxkit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type connAffinityKey struct{}
+
+// affinityConn is the part of *pgxpool.Conn that connection affinity uses.
+type affinityConn interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+	Release()
+}
+
+// affinityPool is the part of the write pool that connection affinity uses.
+// Tests substitute a fake to observe acquire and fallback behavior.
+type affinityPool interface {
+	acquire(ctx context.Context) (affinityConn, error)
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// pgxpoolAffinity adapts *pgxpool.Pool to affinityPool.
+type pgxpoolAffinity struct {
+	*pgxpool.Pool
+}
+
+func (p pgxpoolAffinity) acquire(ctx context.Context) (affinityConn, error) {
+	return p.Acquire(ctx)
+}
+
+// pinnedConns tracks the connection affinities currently holding a
+// connection, so Shutdown can hand those connections back to the pool.
+type pinnedConns struct {
+	mu  sync.Mutex
+	set map[*connAffinity]struct{}
+}
+
+func (p *pinnedConns) add(a *connAffinity) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.set == nil {
+		p.set = make(map[*connAffinity]struct{})
+	}
+	p.set[a] = struct{}{}
+}
+
+func (p *pinnedConns) remove(a *connAffinity) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.set, a)
+}
+
+// releaseAll ends every affinity as if its release func had been called. A
+// connection still running a transaction goes back to the pool when that
+// transaction ends.
+func (p *pinnedConns) releaseAll() {
+	p.mu.Lock()
+	affinities := make([]*connAffinity, 0, len(p.set))
+	for a := range p.set {
+		affinities = append(affinities, a)
+	}
+	p.mu.Unlock()
+	for _, a := range affinities {
+		a.release()
+	}
+}
+
+// connAffinity pins one pool connection for the transactions of a single
+// request. The connection is acquired lazily by the first transaction and
+// returned to the pool by the release func from WithConnAffinity.
+type connAffinity struct {
+	db   *DB
+	pool affinityPool
+	// users holds pool open across a ReloadConfig until release.
+	users *sync.WaitGroup
+
+	mu       sync.Mutex
+	conn     affinityConn
+	busy     bool
+	released bool
+}
+
+// WithConnAffinity returns a context under which transactions started with
+// BeginTx prefer the same write-pool connection, and a release func that
+// returns that connection to the pool. Call release when the request is done:
+//
+//	ctx, release := db.WithConnAffinity(ctx)
+//	defer release()
+//
+// Reusing one connection keeps that connection's prepared-statement cache warm
+// across the request's transactions. If the pinned connection is still in use
+// by another transaction (or the context is used after release), BeginTx falls
+// back to the pool as usual.
+//
+// Tradeoffs: the pinned connection is unavailable to other requests until
+// release, even between transactions, so a request doing slow non-database
+// work holds a connection for that time and pool pressure rises. Only
+// transactions are affected; plain Query/Exec calls use the pool normally.
+// Leave it off unless profiling shows statement preparation is significant.
+//
+// The pinned connection is not an in-flight operation: Shutdown waits for
+// transactions running on it, not for release. Once those drain, Shutdown
+// returns idle pinned connections to the pool itself, so a request that has
+// not called release yet cannot keep the pool from closing.
+//
+// If ReloadConfig swaps the pools before release, the request keeps using the
+// old write pool, which stays open until release.
+//
+// Before Connect, ctx is returned unchanged.
+func (db *DB) WithConnAffinity(ctx context.Context) (context.Context, func()) {
+	db.mu.RLock()
+	pool := db.writePool
+	if pool == nil {
+		db.mu.RUnlock()
+		return ctx, func() {}
+	}
+	a := &connAffinity{db: db, pool: pgxpoolAffinity{pool}, users: db.holdPool(pool)}
+	db.mu.RUnlock()
+	return context.WithValue(ctx, connAffinityKey{}, a), a.release
+}
+
+func withConnAffinity(ctx context.Context, db *DB, pool affinityPool) (context.Context, func()) {
+	a := &connAffinity{db: db, pool: pool}
+	return context.WithValue(ctx, connAffinityKey{}, a), a.release
+}
+
+// beginWriteTx begins a transaction on pool, the write pool, honoring
+// connection affinity if ctx carries it. done, when non-nil, must be called
+// once the transaction is finalized.
+func (db *DB) beginWriteTx(ctx context.Context, pool *pgxpool.Pool, txOptions pgx.TxOptions) (tx pgx.Tx, done func(), err error) {
+	if a, ok := ctx.Value(connAffinityKey{}).(*connAffinity); ok && a.db == db {
+		return a.begin(ctx, txOptions)
+	}
+	if pool == nil {
+		return nil, nil, fmt.Errorf("database is not connected")
+	}
+	tx, err = pool.BeginTx(ctx, txOptions)
+	return tx, nil, err
+}
+
+func (a *connAffinity) begin(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, func(), error) {
+	a.mu.Lock()
+	if a.released || a.busy {
+		a.mu.Unlock()
+		tx, err := a.pool.BeginTx(ctx, txOptions)
+		return tx, nil, err
+	}
+	if a.conn == nil {
+		conn, err := a.pool.acquire(ctx)
+		if err != nil {
+			a.mu.Unlock()
+			return nil, nil, err
+		}
+		a.conn = conn
+		a.db.pinned.add(a)
+	}
+	a.busy = true
+	conn := a.conn
+	a.mu.Unlock()
+
+	tx, err := conn.BeginTx(ctx, txOptions)
+	if err != nil {
+		// The connection may be broken; drop it so the next transaction
+		// acquires a fresh one.
+		a.mu.Lock()
+		a.busy = false
+		a.conn = nil
+		a.mu.Unlock()
+		a.db.pinned.remove(a)
+		conn.Release()
+		return nil, nil, err
+	}
+	return tx, a.finish, nil
+}
+
+// finish marks the pinned connection idle again, releasing it if the request
+// already ended while the transaction was running.
+func (a *connAffinity) finish() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.busy = false
+	if a.released && a.conn != nil {
+		a.conn.Release()
+		a.conn = nil
+		a.db.pinned.remove(a)
+	}
+}
+
+func (a *connAffinity) release() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.released {
+		return
+	}
+	a.released = true
+	a.db.releasePool(a.users)
+	if a.conn != nil && !a.busy {
+		a.conn.Release()
+		a.conn = nil
+		a.db.pinned.remove(a)
+	}
+}
@@ -0,0 +1,197 @@
+package pgxkit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type fakeAffinityConn struct {
+	id       int
+	begins   int
+	released bool
+}
+
+func (c *fakeAffinityConn) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	c.begins++
+	return &mockTx{}, nil
+}
+
+func (c *fakeAffinityConn) Release() { c.released = true }
+
+type fakeAffinityPool struct {
+	conns     []*fakeAffinityConn
+	poolBegin int
+}
+
+func (p *fakeAffinityPool) acquire(ctx context.Context) (affinityConn, error) {
+	conn := &fakeAffinityConn{id: len(p.conns) + 1}
+	p.conns = append(p.conns, conn)
+	return conn, nil
+}
+
+func (p *fakeAffinityPool) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	p.poolBegin++
+	return &mockTx{}, nil
+}
+
+func TestConnAffinityReusesIdleConnection(t *testing.T) {
+	db := NewDB()
+	pool := &fakeAffinityPool{}
+	ctx, release := withConnAffinity(context.Background(), db, pool)
+
+	for i := 0; i < 3; i++ {
+		tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			t.Fatalf("BeginTx %d failed: %v", i, err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			t.Fatalf("Commit %d failed: %v", i, err)
+		}
+	}
+
+	if len(pool.conns) != 1 {
+		t.Fatalf("expected a single acquired connection, got %d", len(pool.conns))
+	}
+	if pool.conns[0].begins != 3 {
+		t.Errorf("expected all 3 transactions on the pinned connection, got %d", pool.conns[0].begins)
+	}
+	if pool.poolBegin != 0 {
+		t.Errorf("expected no pool fallback, got %d", pool.poolBegin)
+	}
+	if pool.conns[0].released {
+		t.Error("pinned connection should be held until release")
+	}
+
+	release()
+	if !pool.conns[0].released {
+		t.Error("release should return the pinned connection to the pool")
+	}
+}
+
+func TestConnAffinityFallsBackWhenBusy(t *testing.T) {
+	db := NewDB()
+	pool := &fakeAffinityPool{}
+	ctx, release := withConnAffinity(context.Background(), db, pool)
+	defer release()
+
+	first, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	second, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+
+	if pool.poolBegin != 1 {
+		t.Errorf("second transaction should fall back to the pool, got %d pool begins", pool.poolBegin)
+	}
+	_ = second.Rollback(ctx)
+	_ = first.Rollback(ctx)
+
+	third, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	_ = third.Commit(ctx)
+	if len(pool.conns) != 1 || pool.conns[0].begins != 2 {
+		t.Errorf("pinned connection should be reused once idle again, got %d conns", len(pool.conns))
+	}
+}
+
+func TestConnAffinityReleaseWhileBusy(t *testing.T) {
+	db := NewDB()
+	pool := &fakeAffinityPool{}
+	ctx, release := withConnAffinity(context.Background(), db, pool)
+
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	release()
+	if pool.conns[0].released {
+		t.Fatal("connection must not be released while its transaction is open")
+	}
+	_ = tx.Commit(ctx)
+	if !pool.conns[0].released {
+		t.Error("connection should be released once the transaction ends")
+	}
+
+	// After release the context falls back to the pool.
+	tx, err = db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	_ = tx.Rollback(ctx)
+	if pool.poolBegin != 1 || len(pool.conns) != 1 {
+		t.Errorf("expected pool fallback after release, got %d pool begins, %d conns", pool.poolBegin, len(pool.conns))
+	}
+	release() // second release is a no-op
+}
+
+func TestShutdownReleasesPinnedConnection(t *testing.T) {
+	db := NewDB()
+	pool := &fakeAffinityPool{}
+	ctx, release := withConnAffinity(context.Background(), db, pool)
+	defer release()
+
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if pool.conns[0].released {
+		t.Fatal("the idle connection should stay pinned until release or Shutdown")
+	}
+
+	if err := db.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if !pool.conns[0].released {
+		t.Error("Shutdown should return the idle pinned connection to the pool")
+	}
+	if len(db.pinned.set) != 0 {
+		t.Errorf("expected no pinned connections after Shutdown, got %d", len(db.pinned.set))
+	}
+}
+
+func TestWithConnAffinityNotConnected(t *testing.T) {
+	db := NewDB()
+	ctx := context.Background()
+	got, release := db.WithConnAffinity(ctx)
+	defer release()
+	if got != ctx {
+		t.Error("WithConnAffinity before Connect should return ctx unchanged")
+	}
+}
+
+func TestConnAffinityIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+	db := NewDB()
+	db.readPool = pool
+	db.writePool = pool
+
+	ctx, release := db.WithConnAffinity(context.Background())
+	defer release()
+
+	backendPID := func() uint32 {
+		tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			t.Fatalf("BeginTx failed: %v", err)
+		}
+		defer tx.Rollback(ctx)
+		var pid uint32
+		if err := tx.QueryRow(ctx, "SELECT pg_backend_pid()").Scan(&pid); err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		return pid
+	}
+
+	if first, second := backendPID(), backendPID(); first != second {
+		t.Errorf("transactions under affinity should share a backend, got %d and %d", first, second)
+	}
+}
@@ -0,0 +1,162 @@
+package pgxkit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SendBatch sends every query queued in b to the write pool in a single round
+// trip. It makes *DB usable as the DBTX of sqlc-generated :batchexec,
+// :batchmany and :batchone queries.
+//
+// BeforeOperation hooks run for each queued query before the batch is sent;
+// if one fails, nothing is sent and every result reports that error.
+// AfterOperation hooks run for each query as its result is read with Exec,
+// Query or QueryRow, and for any unread queries when the batch is closed. As
+// with QueryRow, the hook for a QueryRow result sees a nil error because the
+// row is only read at Scan.
+//
+// The results must be closed before the connection returns to the pool:
+//
+//	batch := &pgx.Batch{}
+//	batch.Queue("INSERT INTO audit (event) VALUES ($1)", "login")
+//	batch.Queue("UPDATE users SET last_login = now() WHERE id = $1", userID)
+//	if err := db.SendBatch(ctx, batch).Close(); err != nil {
+//	    return err
+//	}
+func (db *DB) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	pool, _, users, err := db.startOp(db.writeTarget)
+	if err != nil {
+		return &errBatchResults{err: err}
+	}
+	defer db.endOp(users)
+
+	ctx, cancel := db.operationContext(ctx)
+
+	hookCtxs, err := db.beforeBatch(ctx, b)
+	if err != nil {
+		cancel()
+		return &errBatchResults{err: err}
+	}
+
+	return &hookedBatchResults{
+		BatchResults: pool.SendBatch(ctx, b),
+		db:           db,
+		hookCtxs:     hookCtxs,
+		queries:      b.QueuedQueries,
+		cancel:       cancel,
+	}
+}
+
+// SendBatch sends every query queued in b within the transaction. Hooks fire
+// as for DB.SendBatch.
+func (t *Tx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	if t.finalized.Load() {
+		return &errBatchResults{err: ErrTxFinalized}
+	}
+	hookCtxs, err := t.db.beforeBatch(context.WithValue(ctx, txContextKey{}, t), b)
+	if err != nil {
+		return &errBatchResults{err: err}
+	}
+	for _, q := range b.QueuedQueries {
+		t.statementCount.Add(1)
+		t.recordStatement(q.SQL, q.Arguments)
+	}
+	return &hookedBatchResults{
+		BatchResults: t.tx.SendBatch(ctx, b),
+		db:           t.db,
+		hookCtxs:     hookCtxs,
+		queries:      b.QueuedQueries,
+		cancel:       func() {},
+	}
+}
+
+// beforeBatch applies rewrite hooks to every queued query, then runs the
+// BeforeOperation hooks for each under its own hook state, returning those
+// contexts for the matching AfterOperation calls.
+func (db *DB) beforeBatch(ctx context.Context, b *pgx.Batch) ([]context.Context, error) {
+	for _, q := range b.QueuedQueries {
+		sql, args, err := db.hooks.executeRewrite(ctx, q.SQL, q.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		q.SQL, q.Arguments = sql, args
+	}
+	ctxs := make([]context.Context, len(b.QueuedQueries))
+	for i, q := range b.QueuedQueries {
+		ctxs[i] = withHookState(ctx)
+		if err := db.hooks.executeBeforeOperation(ctxs[i], q.SQL, q.Arguments, pgconn.CommandTag{}, nil); err != nil {
+			return nil, fmt.Errorf("before operation hook failed: %w", err)
+		}
+	}
+	return ctxs, nil
+}
+
+// hookedBatchResults fires AfterOperation hooks as each queued query's result
+// is read, and releases the operation context on Close.
+type hookedBatchResults struct {
+	pgx.BatchResults
+	db       *DB
+	hookCtxs []context.Context
+	queries  []*pgx.QueuedQuery
+	next     int
+	cancel   context.CancelFunc
+}
+
+// afterNext runs the AfterOperation hooks for the next unread query.
+func (r *hookedBatchResults) afterNext(tag pgconn.CommandTag, err error) error {
+	if r.next >= len(r.queries) {
+		return nil
+	}
+	q, ctx := r.queries[r.next], r.hookCtxs[r.next]
+	r.next++
+	return r.db.hooks.executeAfterOperation(ctx, q.SQL, q.Arguments, tag, err)
+}
+
+func (r *hookedBatchResults) Exec() (pgconn.CommandTag, error) {
+	tag, err := r.BatchResults.Exec()
+	if hookErr := r.afterNext(tag, err); hookErr != nil && err == nil {
+		return tag, fmt.Errorf("after operation hook failed: %w", hookErr)
+	}
+	return tag, err
+}
+
+func (r *hookedBatchResults) Query() (pgx.Rows, error) {
+	rows, err := r.BatchResults.Query()
+	if hookErr := r.afterNext(pgconn.CommandTag{}, err); hookErr != nil && err == nil {
+		rows.Close()
+		return nil, fmt.Errorf("after operation hook failed: %w", hookErr)
+	}
+	return rows, err
+}
+
+func (r *hookedBatchResults) QueryRow() pgx.Row {
+	row := r.BatchResults.QueryRow()
+	if hookErr := r.afterNext(pgconn.CommandTag{}, nil); hookErr != nil {
+		return &shutdownRow{err: fmt.Errorf("after operation hook failed: %w", hookErr)}
+	}
+	return row
+}
+
+func (r *hookedBatchResults) Close() error {
+	err := r.BatchResults.Close()
+	for r.next < len(r.queries) {
+		_ = r.afterNext(pgconn.CommandTag{}, err)
+	}
+	r.cancel()
+	return err
+}
+
+// errBatchResults reports err for every result of a batch that was never
+// sent.
+type errBatchResults struct {
+	err error
+}
+
+func (r *errBatchResults) Exec() (pgconn.CommandTag, error) { return pgconn.CommandTag{}, r.err }
+func (r *errBatchResults) Query() (pgx.Rows, error)         { return nil, r.err }
+func (r *errBatchResults) QueryRow() pgx.Row                { return &shutdownRow{err: r.err} }
+func (r *errBatchResults) Close() error                     { return r.err }
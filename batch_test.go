@@ -0,0 +1,200 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeBatchResults answers Exec with a fixed tag and counts Close calls.
+type fakeBatchResults struct {
+	execs  int
+	closed int
+}
+
+func (r *fakeBatchResults) Exec() (pgconn.CommandTag, error) {
+	r.execs++
+	return pgconn.NewCommandTag("INSERT 0 1"), nil
+}
+func (r *fakeBatchResults) Query() (pgx.Rows, error) { return nil, errors.New("not supported") }
+func (r *fakeBatchResults) QueryRow() pgx.Row        { return &shutdownRow{err: errors.New("not supported")} }
+func (r *fakeBatchResults) Close() error {
+	r.closed++
+	return nil
+}
+
+func recordingHooks(db *DB) (before, after *[]string) {
+	before, after = &[]string{}, &[]string{}
+	db.hooks.addHook(BeforeOperation, func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+		*before = append(*before, sql)
+		return nil
+	})
+	db.hooks.addHook(AfterOperation, func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+		*after = append(*after, sql)
+		return nil
+	})
+	return before, after
+}
+
+func TestSendBatchNotConnected(t *testing.T) {
+	b := &pgx.Batch{}
+	b.Queue("SELECT 1")
+	br := NewDB().SendBatch(context.Background(), b)
+	if _, err := br.Exec(); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected not connected error from Exec, got %v", err)
+	}
+	if err := br.QueryRow().Scan(new(int)); err == nil {
+		t.Error("expected error from QueryRow")
+	}
+	if err := br.Close(); err == nil {
+		t.Error("expected error from Close")
+	}
+}
+
+func TestTxSendBatchFiresHooksPerQuery(t *testing.T) {
+	db := NewDB()
+	before, after := recordingHooks(db)
+	results := &fakeBatchResults{}
+	db.activeOps.Add(1)
+	tx := &Tx{tx: &mockTx{sendBatchFunc: func(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+		return results
+	}}, db: db}
+
+	b := &pgx.Batch{}
+	b.Queue("INSERT INTO a VALUES (1)")
+	b.Queue("INSERT INTO b VALUES (2)")
+	b.Queue("INSERT INTO c VALUES (3)")
+
+	br := tx.SendBatch(context.Background(), b)
+	if len(*before) != 3 {
+		t.Errorf("expected BeforeOperation for each queued query, got %v", *before)
+	}
+	if len(*after) != 0 {
+		t.Errorf("AfterOperation should wait for results, got %v", *after)
+	}
+
+	if _, err := br.Exec(); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if len(*after) != 1 || (*after)[0] != "INSERT INTO a VALUES (1)" {
+		t.Errorf("expected AfterOperation for the first query, got %v", *after)
+	}
+
+	if err := br.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if len(*after) != 3 || (*after)[2] != "INSERT INTO c VALUES (3)" {
+		t.Errorf("Close should fire AfterOperation for unread queries, got %v", *after)
+	}
+	if results.closed != 1 {
+		t.Errorf("expected underlying results closed once, got %d", results.closed)
+	}
+	if got := tx.Info().Statements; got != 3 {
+		t.Errorf("expected 3 statements counted, got %d", got)
+	}
+}
+
+func TestTxSendBatchBeforeHookErrorSendsNothing(t *testing.T) {
+	db := NewDB()
+	hookErr := errors.New("blocked")
+	db.hooks.addHook(BeforeOperation, func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+		if strings.HasPrefix(sql, "DELETE") {
+			return hookErr
+		}
+		return nil
+	})
+	sent := false
+	db.activeOps.Add(1)
+	tx := &Tx{tx: &mockTx{sendBatchFunc: func(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+		sent = true
+		return &fakeBatchResults{}
+	}}, db: db}
+
+	b := &pgx.Batch{}
+	b.Queue("SELECT 1")
+	b.Queue("DELETE FROM users")
+	br := tx.SendBatch(context.Background(), b)
+	if _, err := br.Exec(); !errors.Is(err, hookErr) {
+		t.Errorf("expected hook error from Exec, got %v", err)
+	}
+	if err := br.Close(); !errors.Is(err, hookErr) {
+		t.Errorf("expected hook error from Close, got %v", err)
+	}
+	if sent {
+		t.Error("batch should not be sent when a before hook fails")
+	}
+}
+
+func TestTxSendBatchAfterFinalization(t *testing.T) {
+	db := NewDB()
+	db.activeOps.Add(1)
+	tx := &Tx{tx: &mockTx{}, db: db}
+	_ = tx.Commit(context.Background())
+
+	b := &pgx.Batch{}
+	b.Queue("SELECT 1")
+	if err := tx.SendBatch(context.Background(), b).Close(); !errors.Is(err, ErrTxFinalized) {
+		t.Errorf("expected ErrTxFinalized, got %v", err)
+	}
+}
+
+func TestSendBatchIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+	ctx := context.Background()
+
+	db := NewDB()
+	db.readPool = pool
+	db.writePool = pool
+	_, after := recordingHooks(db)
+
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS batch_test (id SERIAL PRIMARY KEY, value TEXT)`)
+	if err != nil {
+		t.Fatalf("Failed to create test table: %v", err)
+	}
+	defer CleanupTestData("DROP TABLE IF EXISTS batch_test")
+
+	b := &pgx.Batch{}
+	b.Queue("INSERT INTO batch_test (value) VALUES ($1)", "a")
+	b.Queue("INSERT INTO batch_test (value) VALUES ($1)", "b")
+	b.Queue("SELECT value FROM batch_test ORDER BY value")
+	b.Queue("SELECT count(*) FROM batch_test")
+
+	br := db.SendBatch(ctx, b)
+	for i := 0; i < 2; i++ {
+		tag, err := br.Exec()
+		if err != nil {
+			t.Fatalf("insert %d failed: %v", i, err)
+		}
+		if tag.RowsAffected() != 1 {
+			t.Errorf("insert %d affected %d rows", i, tag.RowsAffected())
+		}
+	}
+	rows, err := br.Query()
+	if err != nil {
+		t.Fatalf("select failed: %v", err)
+	}
+	values, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		t.Fatalf("collect failed: %v", err)
+	}
+	if strings.Join(values, ",") != "a,b" {
+		t.Errorf("expected [a b], got %v", values)
+	}
+	var count int
+	if err := br.QueryRow().Scan(&count); err != nil || count != 2 {
+		t.Errorf("expected count 2, got %d, %v", count, err)
+	}
+	if err := br.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if len(*after) != 4 {
+		t.Errorf("expected AfterOperation for all 4 queries, got %d", len(*after))
+	}
+	if db.InFlight() != 0 {
+		t.Errorf("expected no in-flight operations, got %d", db.InFlight())
+	}
+}
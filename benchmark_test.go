@@ -275,3 +275,53 @@ func BenchmarkHookExecutionTime(b *testing.B) {
 		}
 	})
 }
+
+// BenchmarkOperationTracking measures the cost of the in-flight bookkeeping
+// that WithoutGracefulShutdownTracking removes.
+func BenchmarkOperationTracking(b *testing.B) {
+	for _, tc := range []struct {
+		name     string
+		disabled bool
+	}{{"Tracked", false}, {"Untracked", true}} {
+		b.Run(tc.name, func(b *testing.B) {
+			ops := &opTracker{disabled: tc.disabled}
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					ops.Add(1)
+					ops.Done()
+				}
+			})
+		})
+	}
+
+	ctx := context.Background()
+	pool := getTestPool()
+	if pool == nil {
+		b.Log("TEST_DATABASE_URL not set, skipping query benchmarks")
+		return
+	}
+	for _, tc := range []struct {
+		name     string
+		disabled bool
+	}{{"QueryRowTracked", false}, {"QueryRowUntracked", true}} {
+		b.Run(tc.name, func(b *testing.B) {
+			db := &DB{
+				readPool:  pool,
+				writePool: pool,
+				hooks:     newHooks(),
+			}
+			db.activeOps.disabled = tc.disabled
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				var result int
+				for pb.Next() {
+					if err := db.QueryRow(ctx, "SELECT 1").Scan(&result); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Conn is a write-pool connection checked out with DB.Acquire. It embeds
+// *pgxpool.Conn, so Exec, Query, CopyFrom, Conn() and the rest are available
+// directly. Statements run on it do not fire operation hooks, rewrite hooks
+// or query timeouts.
+type Conn struct {
+	*pgxpool.Conn
+	db       *DB
+	users    *sync.WaitGroup
+	released atomic.Bool
+}
+
+// Acquire checks out a connection from the write pool for work that needs a
+// raw connection: COPY, session-level advisory locks, LISTEN, or settings
+// that must persist across statements. Unlike acquiring from WritePool
+// directly, the connection counts as an in-flight operation, so Shutdown
+// waits for it.
+//
+// Callers must call Release exactly once when done; a connection that is
+// never released keeps Shutdown waiting until its context expires.
+//
+//	conn, err := db.Acquire(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	defer conn.Release()
+//
+//	_, err = conn.Exec(ctx, "SELECT pg_advisory_lock($1)", jobID)
+func (db *DB) Acquire(ctx context.Context) (*Conn, error) {
+	pool, _, users, err := db.startOp(db.writeTarget)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		db.endOp(users)
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	return &Conn{Conn: conn, db: db, users: users}, nil
+}
+
+// Release returns the connection to the pool and ends its in-flight
+// operation. Calls after the first are no-ops.
+func (c *Conn) Release() {
+	if !c.released.CompareAndSwap(false, true) {
+		return
+	}
+	c.Conn.Release()
+	c.db.endOp(c.users)
+}
+
+// Hijack takes the underlying connection out of the pool for good, ending
+// its in-flight operation. The caller becomes responsible for closing it.
+// Hijack panics if the connection was already released.
+func (c *Conn) Hijack() *pgx.Conn {
+	if !c.released.CompareAndSwap(false, true) {
+		panic("pgxkit: cannot hijack a released connection")
+	}
+	defer c.db.endOp(c.users)
+	return c.Conn.Hijack()
+}
+
+// Warmup opens MinConns connections on each pool up front, so the first
+// requests after Connect don't pay for connection setup. It holds up to
+// MinConns connections at once, which makes the pool dial them (running
+// OnConnect hooks), then releases them all to sit idle. Connections that
+// are already open count towards MinConns. A pool with MinConns of 0 is left
+// alone.
+//
+// Warmup respects ctx's deadline and counts as an in-flight operation for
+// Shutdown. Acquire errors are returned, joined, after every connection that
+// was acquired has been released.
+//
+// Example:
+//
+//	if err := db.Connect(ctx, dsn, pgxkit.WithMinConns(10)); err != nil {
+//	    return err
+//	}
+//	if err := db.Warmup(ctx); err != nil {
+//	    log.Printf("pool warmup incomplete: %v", err)
+//	}
+func (db *DB) Warmup(ctx context.Context) error {
+	db.mu.RLock()
+	if db.shutdown {
+		db.mu.RUnlock()
+		return fmt.Errorf("database is shutting down")
+	}
+	writePool, readPool := db.writePool, db.readPool
+	if writePool == nil {
+		db.mu.RUnlock()
+		return fmt.Errorf("database is not connected")
+	}
+	db.activeOps.Add(1)
+	users := db.holdPool(writePool)
+	var readUsers *sync.WaitGroup
+	if readPool != writePool {
+		readUsers = db.holdPool(readPool)
+	}
+	db.mu.RUnlock()
+	defer db.releasePool(readUsers)
+	defer db.endOp(users)
+
+	if err := warmPool(ctx, writePool); err != nil {
+		return fmt.Errorf("failed to warm up write pool: %w", err)
+	}
+	if readPool != nil && readPool != writePool {
+		if err := warmPool(ctx, readPool); err != nil {
+			return fmt.Errorf("failed to warm up read pool: %w", err)
+		}
+	}
+	return nil
+}
+
+// warmPool concurrently acquires MinConns connections from pool, then
+// releases them.
+func warmPool(ctx context.Context, pool *pgxpool.Pool) error {
+	n := int(pool.Config().MinConns)
+	conns := make([]*pgxpool.Conn, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conns[i], errs[i] = pool.Acquire(ctx)
+		}()
+	}
+	wg.Wait()
+
+	for _, conn := range conns {
+		if conn != nil {
+			conn.Release()
+		}
+	}
+	return errors.Join(errs...)
+}
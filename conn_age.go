@@ -0,0 +1,119 @@
+package pgxkit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// connAges records when each pooled connection was opened. pgxpool does not
+// expose connection creation times, so Connect wraps the pool's AfterConnect
+// and BeforeClose callbacks to maintain them.
+type connAges struct {
+	mu      sync.Mutex
+	created map[*pgx.Conn]time.Time
+}
+
+// configurePool makes config record its connections in a. Call it after the
+// connection hooks are configured, so a connection rejected by OnConnect is
+// never recorded.
+func (a *connAges) configurePool(config *pgxpool.Config) {
+	afterConnect := config.AfterConnect
+	beforeClose := config.BeforeClose
+
+	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		if afterConnect != nil {
+			if err := afterConnect(ctx, conn); err != nil {
+				return err
+			}
+		}
+		a.mu.Lock()
+		if a.created == nil {
+			a.created = make(map[*pgx.Conn]time.Time)
+		}
+		a.created[conn] = time.Now()
+		a.mu.Unlock()
+		return nil
+	}
+	config.BeforeClose = func(conn *pgx.Conn) {
+		a.mu.Lock()
+		delete(a.created, conn)
+		a.mu.Unlock()
+		if beforeClose != nil {
+			beforeClose(conn)
+		}
+	}
+}
+
+// agesAt returns the age of every recorded connection at now, youngest
+// first.
+func (a *connAges) agesAt(now time.Time) []time.Duration {
+	a.mu.Lock()
+	ages := make([]time.Duration, 0, len(a.created))
+	for _, created := range a.created {
+		ages = append(ages, now.Sub(created))
+	}
+	a.mu.Unlock()
+	slices.Sort(ages)
+	return ages
+}
+
+// ConnectionAges returns how long each connection currently in the pools has
+// been open, youngest first, covering both pools with ConnectReadWrite. It
+// helps tune WithMaxConnLifetime and WithMaxConnIdleTime: ages that never get
+// close to the lifetime mean connections are closed early, for example by
+// the idle timeout or by the server, and the pool is churning.
+//
+// The ages are tracked by pgxkit from the moment a connection passes its
+// OnConnect hooks, so they are best-effort: a connection being closed may
+// still be listed. SummarizeConnectionAges aggregates the result.
+//
+// Example:
+//
+//	ages, err := db.ConnectionAges()
+//	if err == nil {
+//	    s := pgxkit.SummarizeConnectionAges(ages)
+//	    log.Printf("%d conns, age min %v max %v avg %v", s.Count, s.Min, s.Max, s.Avg)
+//	}
+func (db *DB) ConnectionAges() ([]time.Duration, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.shutdown {
+		return nil, fmt.Errorf("database is shutting down")
+	}
+	if db.writePool == nil {
+		return nil, fmt.Errorf("database is not connected")
+	}
+	return db.connAges.agesAt(time.Now()), nil
+}
+
+// ConnectionAgeSummary aggregates connection ages; see
+// SummarizeConnectionAges.
+type ConnectionAgeSummary struct {
+	Count int
+	Min   time.Duration
+	Max   time.Duration
+	Avg   time.Duration
+}
+
+// SummarizeConnectionAges returns the count, minimum, maximum and mean of
+// ages, as returned by ConnectionAges. No ages give the zero summary.
+func SummarizeConnectionAges(ages []time.Duration) ConnectionAgeSummary {
+	if len(ages) == 0 {
+		return ConnectionAgeSummary{}
+	}
+	summary := ConnectionAgeSummary{Count: len(ages), Min: ages[0], Max: ages[0]}
+	var total time.Duration
+	for _, age := range ages {
+		summary.Min = min(summary.Min, age)
+		summary.Max = max(summary.Max, age)
+		total += age
+	}
+	summary.Avg = total / time.Duration(len(ages))
+	return summary
+}
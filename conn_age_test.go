@@ -0,0 +1,117 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestConnAgesAt(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var a connAges
+	a.created = map[*pgx.Conn]time.Time{
+		new(pgx.Conn): now.Add(-30 * time.Minute),
+		new(pgx.Conn): now.Add(-time.Minute),
+		new(pgx.Conn): now.Add(-2 * time.Hour),
+	}
+
+	ages := a.agesAt(now)
+	want := []time.Duration{time.Minute, 30 * time.Minute, 2 * time.Hour}
+	if len(ages) != len(want) {
+		t.Fatalf("expected %d ages, got %v", len(want), ages)
+	}
+	for i := range want {
+		if ages[i] != want[i] {
+			t.Errorf("expected ages %v, got %v", want, ages)
+			break
+		}
+	}
+
+	s := SummarizeConnectionAges(ages)
+	if s.Count != 3 || s.Min != time.Minute || s.Max != 2*time.Hour || s.Avg != 50*time.Minute+20*time.Second {
+		t.Errorf("unexpected summary %+v", s)
+	}
+	if s := SummarizeConnectionAges(nil); s != (ConnectionAgeSummary{}) {
+		t.Errorf("expected the zero summary for no ages, got %+v", s)
+	}
+}
+
+func TestConnAgesTracksPoolCallbacks(t *testing.T) {
+	config, err := pgxpool.ParseConfig(lazyDSN)
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	reject := errors.New("rejected")
+	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		return reject
+	}
+	var a connAges
+	a.configurePool(config)
+
+	if err := config.AfterConnect(context.Background(), new(pgx.Conn)); !errors.Is(err, reject) {
+		t.Fatalf("expected the wrapped AfterConnect error, got %v", err)
+	}
+	if n := len(a.agesAt(time.Now())); n != 0 {
+		t.Fatalf("a rejected connection should not be tracked, got %d", n)
+	}
+
+	conn := new(pgx.Conn)
+	a.created = map[*pgx.Conn]time.Time{conn: time.Now()}
+	config.BeforeClose(conn)
+	if n := len(a.agesAt(time.Now())); n != 0 {
+		t.Errorf("a closed connection should no longer be tracked, got %d", n)
+	}
+}
+
+func TestConnectionAgesRequiresConnection(t *testing.T) {
+	if _, err := NewDB().ConnectionAges(); err == nil {
+		t.Error("expected an error before Connect")
+	}
+
+	db := NewDB()
+	if err := db.Connect(context.Background(), lazyDSN); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	ages, err := db.ConnectionAges()
+	if err != nil || len(ages) != 0 {
+		t.Errorf("expected no ages on a pool that has not connected, got %v, %v", ages, err)
+	}
+	if err := db.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if _, err := db.ConnectionAges(); err == nil {
+		t.Error("expected an error after Shutdown")
+	}
+}
+
+func TestConnectionAgesIntegration(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+	ctx := context.Background()
+	db := NewDB()
+	if err := db.Connect(ctx, dsn, WithMinConns(2)); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer db.Shutdown(ctx)
+
+	if _, err := db.Exec(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	ages, err := db.ConnectionAges()
+	if err != nil {
+		t.Fatalf("ConnectionAges failed: %v", err)
+	}
+	if len(ages) == 0 {
+		t.Fatal("expected the connection used by Exec to be tracked")
+	}
+	if int32(len(ages)) > db.Stats().TotalConns() {
+		t.Errorf("tracked %d connections but the pool has %d", len(ages), db.Stats().TotalConns())
+	}
+}
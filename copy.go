@@ -0,0 +1,192 @@
+package pgxkit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CopyFormat is the input format of a COPY ... FROM STDIN stream.
+type CopyFormat int
+
+const (
+	// CopyFormatCSV is comma-separated values with no header line.
+	CopyFormatCSV CopyFormat = iota
+	// CopyFormatCSVHeader is CSV whose first line is a header, which the
+	// server skips.
+	CopyFormatCSVHeader
+	// CopyFormatText is PostgreSQL's tab-separated text format, with \N for
+	// NULL.
+	CopyFormatText
+)
+
+func (f CopyFormat) options() (string, error) {
+	switch f {
+	case CopyFormatCSV:
+		return "FORMAT csv", nil
+	case CopyFormatCSVHeader:
+		return "FORMAT csv, HEADER true", nil
+	case CopyFormatText:
+		return "FORMAT text", nil
+	}
+	return "", fmt.Errorf("unknown copy format %d", f)
+}
+
+// copyFromSQL builds the COPY statement for CopyFromReader.
+func copyFromSQL(tableName pgx.Identifier, columns []string, format CopyFormat) (string, error) {
+	options, err := format.options()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteString("COPY ")
+	b.WriteString(tableName.Sanitize())
+	if len(columns) > 0 {
+		quoted := make([]string, len(columns))
+		for i, col := range columns {
+			quoted[i] = pgx.Identifier{col}.Sanitize()
+		}
+		b.WriteString(" (" + strings.Join(quoted, ", ") + ")")
+	}
+	b.WriteString(" FROM STDIN WITH (" + options + ")")
+	return b.String(), nil
+}
+
+// CopyFromReader streams r into tableName with COPY ... FROM STDIN on a
+// write-pool connection and returns the number of rows copied. The data is
+// sent as it is read, so files far larger than memory can be imported:
+//
+//	f, err := os.Open("users.csv")
+//	if err != nil {
+//	    return err
+//	}
+//	defer f.Close()
+//	n, err := db.CopyFromReader(ctx, pgx.Identifier{"users"}, []string{"id", "email"}, pgxkit.CopyFormatCSVHeader, f)
+//
+// columns may be nil to load every column in table order. The import counts
+// as an in-flight operation for Shutdown and fires BeforeOperation and
+// AfterOperation hooks with the generated COPY statement; AfterOperation
+// receives the COPY command tag. A default query timeout (WithQueryTimeout)
+// applies to the whole import, so give large ones more time with
+// WithOperationTimeout. A failed import copies nothing.
+func (db *DB) CopyFromReader(ctx context.Context, tableName pgx.Identifier, columns []string, format CopyFormat, r io.Reader) (int64, error) {
+	sql, err := copyFromSQL(tableName, columns, format)
+	if err != nil {
+		return 0, err
+	}
+	return db.copyOperation(ctx, sql, func(ctx context.Context, conn *pgxpool.Conn) (pgconn.CommandTag, error) {
+		return conn.Conn().PgConn().CopyFrom(ctx, r, sql)
+	})
+}
+
+// CopyFrom copies the rows of src into tableName with pgx's binary COPY
+// protocol on a write-pool connection and returns the number of rows
+// copied. columns are required and must match the order of the values src
+// yields. Like CopyFromReader, it counts as an in-flight operation, fires
+// BeforeOperation and AfterOperation hooks (with a COPY ... FROM STDIN
+// BINARY statement and a COPY command tag), honors the default query
+// timeout and copies nothing if it fails.
+//
+// Example:
+//
+//	n, err := db.CopyFrom(ctx, pgx.Identifier{"users"}, []string{"id", "email"},
+//	    pgx.CopyFromRows([][]any{{1, "ada@example.com"}, {2, "grace@example.com"}}))
+func (db *DB) CopyFrom(ctx context.Context, tableName pgx.Identifier, columns []string, src pgx.CopyFromSource) (int64, error) {
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("copy: columns are required")
+	}
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = pgx.Identifier{col}.Sanitize()
+	}
+	sql := "COPY " + tableName.Sanitize() + " (" + strings.Join(quoted, ", ") + ") FROM STDIN BINARY"
+	return db.copyOperation(ctx, sql, func(ctx context.Context, conn *pgxpool.Conn) (pgconn.CommandTag, error) {
+		n, err := conn.Conn().CopyFrom(ctx, tableName, columns, src)
+		if err != nil {
+			return pgconn.CommandTag{}, err
+		}
+		return pgconn.NewCommandTag("COPY " + strconv.FormatInt(n, 10)), nil
+	})
+}
+
+// copyOperation runs copy on a write-pool connection as one operation
+// described to hooks by sql, and returns the number of rows copied.
+func (db *DB) copyOperation(ctx context.Context, sql string, copy func(context.Context, *pgxpool.Conn) (pgconn.CommandTag, error)) (int64, error) {
+	pool, _, users, err := db.startOp(db.writeTarget)
+	if err != nil {
+		return 0, err
+	}
+	defer db.endOp(users)
+
+	ctx, cancel := db.operationContext(ctx)
+	defer cancel()
+
+	ctx = withHookState(ctx)
+	if err := db.hooks.executeBeforeOperation(ctx, sql, nil, pgconn.CommandTag{}, nil); err != nil {
+		return 0, fmt.Errorf("before operation hook failed: %w", err)
+	}
+
+	var tag pgconn.CommandTag
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to acquire connection: %w", err)
+	} else {
+		tag, err = copy(ctx, conn)
+		conn.Release()
+	}
+
+	if hookErr := db.hooks.executeAfterOperation(ctx, sql, nil, tag, err); hookErr != nil {
+		if err == nil {
+			return tag.RowsAffected(), fmt.Errorf("after operation hook failed: %w", hookErr)
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// BulkInsert copies rows into table with CopyFrom, using extract to turn each
+// row into its values for columns, in order. It is much faster than one
+// INSERT per row for large slices:
+//
+//	n, err := pgxkit.BulkInsert(ctx, db, "events", events, []string{"id", "kind", "at"},
+//	    func(e Event) []any { return []any{e.ID, e.Kind, e.At} })
+//
+// The table may be schema-qualified and must be a plain identifier. A row
+// whose values don't match columns in number fails the whole copy, and
+// nothing is inserted. An empty rows does nothing.
+func BulkInsert[T any](ctx context.Context, db *DB, table string, rows []T, columns []string, extract func(T) []any) (int64, error) {
+	tableName, err := parseTableName(table)
+	if err != nil {
+		return 0, fmt.Errorf("bulk insert: %w", err)
+	}
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("bulk insert: columns are required")
+	}
+	if extract == nil {
+		return 0, fmt.Errorf("bulk insert: extract is required")
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return db.CopyFrom(ctx, tableName, columns, bulkInsertSource(rows, columns, extract))
+}
+
+// bulkInsertSource adapts rows to a pgx.CopyFromSource, checking that every
+// row yields one value per column.
+func bulkInsertSource[T any](rows []T, columns []string, extract func(T) []any) pgx.CopyFromSource {
+	return pgx.CopyFromSlice(len(rows), func(i int) ([]any, error) {
+		values := extract(rows[i])
+		if len(values) != len(columns) {
+			return nil, fmt.Errorf("bulk insert: row %d has %d values for %d columns", i, len(values), len(columns))
+		}
+		return values, nil
+	})
+}
@@ -0,0 +1,198 @@
+package pgxkit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestCopyFromSQL(t *testing.T) {
+	tests := []struct {
+		table   pgx.Identifier
+		columns []string
+		format  CopyFormat
+		want    string
+	}{
+		{pgx.Identifier{"users"}, []string{"id", "email"}, CopyFormatCSV,
+			`COPY "users" ("id", "email") FROM STDIN WITH (FORMAT csv)`},
+		{pgx.Identifier{"app", "users"}, nil, CopyFormatCSVHeader,
+			`COPY "app"."users" FROM STDIN WITH (FORMAT csv, HEADER true)`},
+		{pgx.Identifier{"users"}, []string{`we"ird`}, CopyFormatText,
+			`COPY "users" ("we""ird") FROM STDIN WITH (FORMAT text)`},
+	}
+	for _, tt := range tests {
+		got, err := copyFromSQL(tt.table, tt.columns, tt.format)
+		if err != nil || got != tt.want {
+			t.Errorf("copyFromSQL(%v, %v) = %q, %v; want %q", tt.table, tt.columns, got, err, tt.want)
+		}
+	}
+	if _, err := copyFromSQL(pgx.Identifier{"users"}, nil, CopyFormat(99)); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestCopyFromReaderNotConnected(t *testing.T) {
+	db := NewDB()
+	_, err := db.CopyFromReader(context.Background(), pgx.Identifier{"users"}, nil, CopyFormatCSV, strings.NewReader(""))
+	if err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected not connected error, got %v", err)
+	}
+}
+
+func TestCopyFromValidation(t *testing.T) {
+	db := NewDB()
+	src := pgx.CopyFromRows([][]any{{1}})
+	if _, err := db.CopyFrom(context.Background(), pgx.Identifier{"users"}, nil, src); err == nil {
+		t.Error("expected an error without columns")
+	}
+	_, err := db.CopyFrom(context.Background(), pgx.Identifier{"users"}, []string{"id"}, src)
+	if err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected not connected error, got %v", err)
+	}
+}
+
+func TestCopyFromReaderIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+	db := NewDB()
+	db.writePool = pool
+	db.readPool = pool
+	ctx := context.Background()
+
+	if _, err := db.Exec(ctx, "CREATE TABLE IF NOT EXISTS copy_import (id int PRIMARY KEY, email text, note text)"); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	t.Cleanup(func() { _, _ = db.Exec(context.Background(), "DROP TABLE IF EXISTS copy_import") })
+	if _, err := db.Exec(ctx, "TRUNCATE copy_import"); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	before, after := recordingHooks(db)
+
+	csv := "id,email\n1,a@example.com\n2,\"b,with comma@example.com\"\n3,c@example.com\n"
+	n, err := db.CopyFromReader(ctx, pgx.Identifier{"copy_import"}, []string{"id", "email"}, CopyFormatCSVHeader, strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("CopyFromReader failed: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 rows copied, got %d", n)
+	}
+
+	var count int
+	var email string
+	if err := db.QueryRow(ctx, "SELECT count(*), max(email) FILTER (WHERE id = 2) FROM copy_import").Scan(&count, &email); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 3 || email != "b,with comma@example.com" {
+		t.Errorf("expected 3 rows with quoted CSV parsed, got %d, %q", count, email)
+	}
+	if len(*before) == 0 || !strings.HasPrefix((*before)[0], "COPY ") || len(*after) == 0 {
+		t.Errorf("expected hooks to see the COPY statement, got %v / %v", *before, *after)
+	}
+
+	_, err = db.CopyFromReader(ctx, pgx.Identifier{"copy_import"}, []string{"id", "email"}, CopyFormatCSV, strings.NewReader("4,d@example.com\nnot-a-number,e@example.com\n"))
+	if err == nil {
+		t.Error("expected malformed input to fail")
+	}
+	if err := db.QueryRow(ctx, "SELECT count(*) FROM copy_import").Scan(&count); err != nil || count != 3 {
+		t.Errorf("a failed import should copy nothing, got %d rows, %v", count, err)
+	}
+}
+
+type bulkEvent struct {
+	ID   int
+	Kind string
+}
+
+func bulkEventValues(e bulkEvent) []any { return []any{e.ID, e.Kind} }
+
+func TestBulkInsertSource(t *testing.T) {
+	events := []bulkEvent{{1, "signup"}, {2, "login"}}
+	src := bulkInsertSource(events, []string{"id", "kind"}, bulkEventValues)
+
+	var got [][]any
+	for src.Next() {
+		values, err := src.Values()
+		if err != nil {
+			t.Fatalf("Values failed: %v", err)
+		}
+		got = append(got, values)
+	}
+	if len(got) != 2 || got[0][0] != 1 || got[1][1] != "login" {
+		t.Errorf("unexpected rows %v", got)
+	}
+
+	src = bulkInsertSource(events, []string{"id", "kind", "at"}, bulkEventValues)
+	src.Next()
+	if _, err := src.Values(); err == nil || !strings.Contains(err.Error(), "row 0 has 2 values for 3 columns") {
+		t.Errorf("expected a column count mismatch, got %v", err)
+	}
+}
+
+func TestBulkInsertValidation(t *testing.T) {
+	db := NewDB()
+	ctx := context.Background()
+	events := []bulkEvent{{1, "signup"}}
+
+	if _, err := BulkInsert(ctx, db, "events; --", events, []string{"id", "kind"}, bulkEventValues); err == nil {
+		t.Error("expected an error for an invalid table name")
+	}
+	if _, err := BulkInsert(ctx, db, "events", events, nil, bulkEventValues); err == nil {
+		t.Error("expected an error without columns")
+	}
+	if _, err := BulkInsert(ctx, db, "events", events, []string{"id", "kind"}, nil); err == nil {
+		t.Error("expected an error without extract")
+	}
+	if n, err := BulkInsert(ctx, db, "events", nil, []string{"id", "kind"}, bulkEventValues); n != 0 || err != nil {
+		t.Errorf("expected no rows to be a no-op, got %d, %v", n, err)
+	}
+	_, err := BulkInsert(ctx, db, "events", events, []string{"id", "kind"}, bulkEventValues)
+	if err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected not connected error, got %v", err)
+	}
+}
+
+func TestBulkInsertIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+	db := NewDB()
+	db.writePool = pool
+	db.readPool = pool
+	ctx := context.Background()
+
+	if _, err := db.Exec(ctx, "CREATE TABLE IF NOT EXISTS bulk_events (id int PRIMARY KEY, kind text NOT NULL)"); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	t.Cleanup(func() { _, _ = db.Exec(context.Background(), "DROP TABLE IF EXISTS bulk_events") })
+	if _, err := db.Exec(ctx, "TRUNCATE bulk_events"); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	before, after := recordingHooks(db)
+
+	events := make([]bulkEvent, 250)
+	for i := range events {
+		events[i] = bulkEvent{ID: i + 1, Kind: "signup"}
+	}
+	n, err := BulkInsert(ctx, db, "bulk_events", events, []string{"id", "kind"}, bulkEventValues)
+	if err != nil {
+		t.Fatalf("BulkInsert failed: %v", err)
+	}
+	if n != 250 {
+		t.Errorf("expected 250 rows copied, got %d", n)
+	}
+	if len(*before) != 1 || len(*after) != 1 || !strings.HasPrefix((*before)[0], "COPY ") {
+		t.Errorf("expected one hooked COPY operation, got %v / %v", *before, *after)
+	}
+
+	var count int
+	if err := db.QueryRow(ctx, "SELECT count(*) FROM bulk_events").Scan(&count); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 250 {
+		t.Errorf("expected 250 rows in the table, got %d", count)
+	}
+
+	events[1].ID = events[0].ID
+	if _, err := BulkInsert(ctx, db, "bulk_events", events[:2], []string{"id", "kind"}, bulkEventValues); err == nil {
+		t.Error("expected a duplicate key error")
+	}
+}
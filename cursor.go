@@ -0,0 +1,94 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrCursorClosed is returned by Cursor.Fetch after Close.
+var ErrCursorClosed = errors.New("cursor is closed")
+
+// Cursor is a server-side cursor opened with DeclareCursor. It owns the
+// transaction the cursor lives in; Close ends both.
+type Cursor struct {
+	tx     *Tx
+	name   string
+	closed bool
+}
+
+// DeclareCursor opens a server-side cursor for sql in a new transaction on
+// the write pool. Rows are then pulled in batches with Fetch or FetchCursor,
+// so a huge result set is processed without the client or a single result
+// set holding all of it at once:
+//
+//	cur, err := db.DeclareCursor(ctx, "export_orders", "SELECT id, total FROM orders ORDER BY id")
+//	if err != nil {
+//	    return err
+//	}
+//	defer cur.Close(ctx)
+//	for {
+//	    batch, err := pgxkit.FetchCursor(ctx, cur, 1000, pgx.RowToStructByPos[Order])
+//	    if err != nil || len(batch) == 0 {
+//	        return err
+//	    }
+//	    write(batch)
+//	}
+//
+// name must be a plain identifier. The transaction is held open, and counted
+// as in flight for Shutdown, until Close; statements on it fire the usual
+// operation hooks. A Cursor is not safe for concurrent use.
+func (db *DB) DeclareCursor(ctx context.Context, name, sql string, args ...interface{}) (*Cursor, error) {
+	if !identifierPattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid cursor name %q", name)
+	}
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	declare := "DECLARE " + pgx.Identifier{name}.Sanitize() + " NO SCROLL CURSOR FOR " + sql
+	if _, err := tx.Exec(ctx, declare, args...); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("failed to declare cursor %s: %w", name, err)
+	}
+	return &Cursor{tx: tx, name: name}, nil
+}
+
+// Fetch returns the next n rows from the cursor, or fewer once it nears the
+// end. Rows with no results mean the cursor is exhausted. The caller must
+// close the rows before the next Fetch.
+func (c *Cursor) Fetch(ctx context.Context, n int) (pgx.Rows, error) {
+	if c.closed {
+		return nil, ErrCursorClosed
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("fetch count must be positive, got %d", n)
+	}
+	return c.tx.Query(ctx, fmt.Sprintf("FETCH FORWARD %d FROM %s", n, pgx.Identifier{c.name}.Sanitize()))
+}
+
+// Close closes the cursor and commits its transaction. It is safe to call
+// more than once; calls after the first return nil.
+func (c *Cursor) Close(ctx context.Context) error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	if _, err := c.tx.Exec(ctx, "CLOSE "+pgx.Identifier{c.name}.Sanitize()); err != nil {
+		_ = c.tx.Rollback(ctx)
+		return fmt.Errorf("failed to close cursor %s: %w", c.name, err)
+	}
+	return c.tx.Commit(ctx)
+}
+
+// FetchCursor fetches the next n rows from c and scans each with scan. An
+// empty result means the cursor is exhausted.
+func FetchCursor[T any](ctx context.Context, c *Cursor, n int, scan pgx.RowToFunc[T]) ([]T, error) {
+	rows, err := c.Fetch(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, scan)
+}
@@ -0,0 +1,94 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestDeclareCursorValidation(t *testing.T) {
+	db := NewDB()
+	if _, err := db.DeclareCursor(context.Background(), "bad name; DROP", "SELECT 1"); err == nil || !strings.Contains(err.Error(), "invalid cursor name") {
+		t.Errorf("expected invalid cursor name error, got %v", err)
+	}
+}
+
+func TestCursorFetchAfterClose(t *testing.T) {
+	db := NewDB()
+	db.activeOps.Add(1)
+	cur := &Cursor{tx: &Tx{tx: &mockTx{}, db: db}, name: "c"}
+	if err := cur.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := cur.Close(context.Background()); err != nil {
+		t.Errorf("second Close should be a no-op, got %v", err)
+	}
+	if _, err := cur.Fetch(context.Background(), 10); !errors.Is(err, ErrCursorClosed) {
+		t.Errorf("expected ErrCursorClosed, got %v", err)
+	}
+	if db.InFlight() != 0 {
+		t.Errorf("Close should end the transaction, %d in flight", db.InFlight())
+	}
+}
+
+func TestCursorIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+	db := NewDB()
+	db.writePool = pool
+	db.readPool = pool
+	ctx := context.Background()
+
+	if _, err := db.Exec(ctx, "CREATE TABLE IF NOT EXISTS cursor_items (id int PRIMARY KEY)"); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	t.Cleanup(func() { _, _ = db.Exec(context.Background(), "DROP TABLE IF EXISTS cursor_items") })
+	if _, err := db.Exec(ctx, "TRUNCATE cursor_items; INSERT INTO cursor_items SELECT generate_series(1, 25)"); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	cur, err := db.DeclareCursor(ctx, "items_cursor", "SELECT id FROM cursor_items WHERE id > $1 ORDER BY id", 0)
+	if err != nil {
+		t.Fatalf("DeclareCursor failed: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	var sizes []int
+	var ids []int
+	for {
+		batch, err := FetchCursor(ctx, cur, 10, pgx.RowTo[int])
+		if err != nil {
+			t.Fatalf("FetchCursor failed: %v", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+		sizes = append(sizes, len(batch))
+		ids = append(ids, batch...)
+	}
+	if len(sizes) != 3 || sizes[0] != 10 || sizes[1] != 10 || sizes[2] != 5 {
+		t.Errorf("expected batches of 10, 10, 5, got %v", sizes)
+	}
+	if len(ids) != 25 || ids[0] != 1 || ids[24] != 25 {
+		t.Errorf("expected ids 1..25 in order, got %v", ids)
+	}
+	if db.InFlight() != 1 {
+		t.Errorf("the cursor's transaction should be in flight until Close, got %d", db.InFlight())
+	}
+
+	if err := cur.Close(ctx); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if db.InFlight() != 0 {
+		t.Errorf("Close should end the transaction, got %d in flight", db.InFlight())
+	}
+
+	if _, err := db.DeclareCursor(ctx, "broken", "SELECT * FROM no_such_table"); err == nil {
+		t.Error("expected declaring a cursor over a missing table to fail")
+	}
+	if db.InFlight() != 0 {
+		t.Errorf("a failed declare should roll back, got %d in flight", db.InFlight())
+	}
+}
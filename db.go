@@ -86,6 +86,7 @@ import (
 	"os"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -180,7 +181,48 @@ type DB struct {
 	goldenHook *assertGoldenHook
 	mu         sync.RWMutex
 	shutdown   bool
-	activeOps  sync.WaitGroup
+	activeOps  opTracker
+
+	label               string
+	queryTimeout        time.Duration
+	defaultQueryTimeout time.Duration
+	rollbackLog         *rollbackLog
+	maxOpenTx           int
+	openTx              atomic.Int64
+	// prepared maps statement names registered with Prepare to their SQL.
+	prepared map[string]string
+	// shutdownQueries are run by Shutdown; see AddShutdownQuery.
+	shutdownQueries []shutdownQuery
+
+	readFallbackToWrite bool
+	readReadiness       bool
+	readOnlyGuard       bool
+	autoReadRouting     bool
+	readUnhealthy       atomic.Bool
+	readHealthStop      chan struct{}
+	readHealthDone      chan struct{}
+
+	lastPoolRefresh atomic.Int64
+	poolRefreshes   atomic.Int64
+	connAges        connAges
+
+	// openPool opens pools for ReloadConfig the way Connect opened the
+	// current ones; reloadMu serializes reloads.
+	openPool func(context.Context, *pgxpool.Config) (*pgxpool.Pool, error)
+	reloadMu sync.Mutex
+	// poolUsers counts the work started on each current pool, so ReloadConfig
+	// closes a replaced pool only once that work has drained. It is only
+	// written with db.mu held for writing.
+	poolUsers map[*pgxpool.Pool]*sync.WaitGroup
+	// pinned holds the WithConnAffinity connections Shutdown releases.
+	pinned pinnedConns
+
+	monitorStop chan struct{}
+	monitors    sync.WaitGroup
+
+	// ping pings a pool for health checks; nil means (*pgxpool.Pool).Ping.
+	// Tests substitute it.
+	ping func(context.Context, *pgxpool.Pool) error
 }
 
 // ConnectOption configures a database connection.
@@ -195,8 +237,37 @@ type connectConfig struct {
 	readMinConns    int32
 	writeMaxConns   int32
 	writeMinConns   int32
+	queryTimeout    time.Duration
+	defaultTimeout  time.Duration
+	maxOpenTx       int
+	label           string
 	hooks           *hooks
 	poolConstructor PoolConstructor
+
+	statementCacheMode StatementCacheMode
+	queryExecMode      pgx.QueryExecMode
+	queryExecModeSet   bool
+	idleInTxTimeout    time.Duration
+	statementTimeout   time.Duration
+	applicationName    string
+	searchPath         string
+	typeRegistry       *TypeRegistry
+	rollbackLog        *rollbackLog
+
+	readFallbackToWrite     bool
+	readHealthCheckInterval time.Duration
+	readReadiness           bool
+	readOnlyGuard           bool
+	autoReadRouting         bool
+	requireDistinctPools    bool
+	disableOpTracking       bool
+	hookTracer              bool
+	refreshOnAdminShutdown  bool
+
+	connectRetry []RetryOption
+	// ping verifies a freshly created pool under WithConnectRetry; nil means
+	// (*pgxpool.Pool).Ping. Tests substitute it.
+	ping func(context.Context, *pgxpool.Pool) error
 }
 
 func newConnectConfig() *connectConfig {
@@ -270,6 +341,37 @@ func WithWriteMinConns(n int32) ConnectOption {
 	}
 }
 
+// WithQueryTimeout caps every Query, QueryRow and Exec issued through the DB
+// at d, including calls whose context already has a longer deadline. For a
+// timeout that only applies when the caller set no deadline, use
+// WithDefaultQueryTimeout. Individual calls can replace it with
+// WithOperationTimeout. Zero or negative durations are ignored (no cap).
+func WithQueryTimeout(d time.Duration) ConnectOption {
+	return func(c *connectConfig) {
+		if d > 0 {
+			c.queryTimeout = d
+		}
+	}
+}
+
+// WithDefaultQueryTimeout sets a timeout for Query, QueryRow and Exec calls
+// whose context carries no deadline of its own. Unlike WithQueryTimeout it
+// never touches a caller-supplied deadline, longer or shorter, so it acts as a
+// safety net for forgotten contexts (context.Background in a worker, say)
+// rather than a cap.
+//
+// Which timeout a call gets: a WithOperationTimeout value on its context
+// replaces both options. Otherwise WithQueryTimeout applies, and a call with
+// no deadline gets the shorter of WithQueryTimeout and
+// WithDefaultQueryTimeout. Zero or negative durations are ignored.
+func WithDefaultQueryTimeout(d time.Duration) ConnectOption {
+	return func(c *connectConfig) {
+		if d > 0 {
+			c.defaultTimeout = d
+		}
+	}
+}
+
 func WithBeforeOperation(fn HookFunc) ConnectOption {
 	return func(c *connectConfig) {
 		c.hooks.addHook(BeforeOperation, fn)
@@ -294,6 +396,14 @@ func WithAfterTransaction(fn HookFunc) ConnectOption {
 	}
 }
 
+// WithAfterCommitSuccess registers a hook that fires only after a transaction
+// commits successfully. See AfterCommitSuccess.
+func WithAfterCommitSuccess(fn HookFunc) ConnectOption {
+	return func(c *connectConfig) {
+		c.hooks.addHook(AfterCommitSuccess, fn)
+	}
+}
+
 func WithOnShutdown(fn HookFunc) ConnectOption {
 	return func(c *connectConfig) {
 		c.hooks.addHook(OnShutdown, fn)
@@ -324,6 +434,329 @@ func WithOnRelease(fn func(*pgx.Conn)) ConnectOption {
 	}
 }
 
+// StatementCacheMode selects how pgx caches statements on each connection.
+// The zero value leaves pgx's defaults untouched.
+type StatementCacheMode int
+
+const (
+	// StatementCacheModePrepare prepares and caches named statements per
+	// connection. This is pgx's default and the fastest mode against a direct
+	// PostgreSQL connection.
+	StatementCacheModePrepare StatementCacheMode = iota + 1
+
+	// StatementCacheModeDescribe caches only statement descriptions (argument
+	// and result types) and never creates named prepared statements.
+	StatementCacheModeDescribe
+
+	// StatementCacheModeNone disables both caches and executes every query
+	// without a named prepared statement. Use this behind PgBouncer in
+	// transaction pooling mode.
+	StatementCacheModeNone
+)
+
+// defaultStatementCacheCapacity matches pgx's default size for both the
+// statement and description caches.
+const defaultStatementCacheCapacity = 512
+
+// WithStatementCacheMode controls pgx's per-connection prepared statement
+// caching via ConnConfig.StatementCacheCapacity, DescriptionCacheCapacity and
+// DefaultQueryExecMode.
+//
+// pgx caches prepared statements per connection by default. Behind a pooler
+// that multiplexes server connections (PgBouncer in transaction or statement
+// pooling mode), a statement prepared on one server connection is missing on
+// the next, producing the classic "prepared statement does not exist" error.
+// Use StatementCacheModeNone there:
+//
+//	db.Connect(ctx, dsn, pgxkit.WithStatementCacheMode(pgxkit.StatementCacheModeNone))
+//
+// Unknown modes are ignored.
+func WithStatementCacheMode(mode StatementCacheMode) ConnectOption {
+	return func(c *connectConfig) {
+		switch mode {
+		case StatementCacheModePrepare, StatementCacheModeDescribe, StatementCacheModeNone:
+			c.statementCacheMode = mode
+		}
+	}
+}
+
+// WithDefaultQueryExecMode sets ConnConfig.DefaultQueryExecMode, choosing how
+// pgx sends queries without touching the statement cache sizes. It overrides
+// the exec mode chosen by WithStatementCacheMode, whichever order the options
+// are given in.
+//
+// Behind PgBouncer in transaction pooling mode use pgx.QueryExecModeExec or
+// pgx.QueryExecModeSimpleProtocol, since prepared statements don't survive
+// server connection changes. Simple protocol also works with poolers that
+// don't support the extended protocol at all, but it interpolates arguments
+// client-side and loses binary encoding. Don't pair
+// pgx.QueryExecModeCacheStatement or pgx.QueryExecModeCacheDescribe with
+// StatementCacheModeNone: with the caches disabled every query fails.
+//
+// Unknown modes are ignored.
+func WithDefaultQueryExecMode(mode pgx.QueryExecMode) ConnectOption {
+	return func(c *connectConfig) {
+		switch mode {
+		case pgx.QueryExecModeCacheStatement, pgx.QueryExecModeCacheDescribe, pgx.QueryExecModeDescribeExec,
+			pgx.QueryExecModeExec, pgx.QueryExecModeSimpleProtocol:
+			c.queryExecMode = mode
+			c.queryExecModeSet = true
+		}
+	}
+}
+
+// WithSimpleProtocol is shorthand for
+// WithDefaultQueryExecMode(pgx.QueryExecModeSimpleProtocol). It's the
+// simplest way to run behind PgBouncer in transaction pooling mode.
+//
+// Tradeoffs: no server-side prepared statements at all, so every query is
+// parsed and planned on each execution, and arguments are interpolated into
+// the SQL text client-side in text format instead of being sent as binary
+// parameters. Types pgx can't encode as text literals fail. Prefer
+// WithStatementCacheMode(StatementCacheModeNone) where the pooler supports
+// the extended protocol.
+func WithSimpleProtocol() ConnectOption {
+	return WithDefaultQueryExecMode(pgx.QueryExecModeSimpleProtocol)
+}
+
+// WithIdleInTransactionTimeout sets the idle_in_transaction_session_timeout
+// runtime parameter on every connection, so PostgreSQL terminates sessions
+// whose transaction has sat idle longer than d. This guards against leaked
+// transactions (a missing Commit/Rollback) holding locks indefinitely.
+// PostgreSQL works in whole milliseconds; sub-millisecond durations round down
+// and values that round to zero are ignored.
+func WithIdleInTransactionTimeout(d time.Duration) ConnectOption {
+	return func(c *connectConfig) {
+		if d >= time.Millisecond {
+			c.idleInTxTimeout = d
+		}
+	}
+}
+
+// WithStatementTimeout sets the statement_timeout runtime parameter on every
+// connection, so PostgreSQL cancels any statement running longer than d. This
+// is a server-side default; WithQueryTimeout and context deadlines still apply
+// on top of it. Like WithIdleInTransactionTimeout, durations round down to
+// whole milliseconds and values that round to zero are ignored.
+func WithStatementTimeout(d time.Duration) ConnectOption {
+	return func(c *connectConfig) {
+		if d >= time.Millisecond {
+			c.statementTimeout = d
+		}
+	}
+}
+
+// WithApplicationName sets the application_name runtime parameter on every
+// connection, which identifies the service in pg_stat_activity and server
+// logs. It overrides any application_name in the DSN. Empty names are ignored.
+func WithApplicationName(name string) ConnectOption {
+	return func(c *connectConfig) {
+		if name != "" {
+			c.applicationName = name
+		}
+	}
+}
+
+// WithSearchPath sets the search_path runtime parameter on every connection.
+// The value is passed through as-is, so list multiple schemas with commas
+// ("app, public") and quote names that need it. Empty paths are ignored.
+func WithSearchPath(path string) ConnectOption {
+	return func(c *connectConfig) {
+		if path != "" {
+			c.searchPath = path
+		}
+	}
+}
+
+// applyConnConfig copies per-connection settings onto a parsed pool config.
+// It is shared by Connect and ConnectReadWrite so both pools behave alike.
+func (c *connectConfig) applyConnConfig(config *pgxpool.Config) {
+	connConfig := config.ConnConfig
+	switch c.statementCacheMode {
+	case StatementCacheModePrepare:
+		connConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+		connConfig.StatementCacheCapacity = defaultStatementCacheCapacity
+		connConfig.DescriptionCacheCapacity = defaultStatementCacheCapacity
+	case StatementCacheModeDescribe:
+		connConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheDescribe
+		connConfig.StatementCacheCapacity = 0
+		connConfig.DescriptionCacheCapacity = defaultStatementCacheCapacity
+	case StatementCacheModeNone:
+		connConfig.DefaultQueryExecMode = pgx.QueryExecModeExec
+		connConfig.StatementCacheCapacity = 0
+		connConfig.DescriptionCacheCapacity = 0
+	}
+	if c.queryExecModeSet {
+		connConfig.DefaultQueryExecMode = c.queryExecMode
+	}
+	if c.idleInTxTimeout > 0 {
+		connConfig.RuntimeParams["idle_in_transaction_session_timeout"] = strconv.FormatInt(c.idleInTxTimeout.Milliseconds(), 10)
+	}
+	if c.statementTimeout > 0 {
+		connConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(c.statementTimeout.Milliseconds(), 10)
+	}
+	if c.applicationName != "" {
+		connConfig.RuntimeParams["application_name"] = c.applicationName
+	}
+	if c.searchPath != "" {
+		connConfig.RuntimeParams["search_path"] = c.searchPath
+	}
+	if c.hookTracer {
+		connConfig.Tracer = &hookTracer{hooks: c.hooks}
+	}
+	if reg := c.typeRegistry; reg != nil {
+		originalAfterConnect := config.AfterConnect
+		config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+			if originalAfterConnect != nil {
+				if err := originalAfterConnect(ctx, conn); err != nil {
+					return err
+				}
+			}
+			return reg.apply(ctx, conn)
+		}
+	}
+}
+
+// AddConnectionHookLive registers an OnConnect hook on an already-connected
+// DB. pgxpool only runs AfterConnect when it dials a new connection, so the
+// hook applies to connections established from now on; connections already in
+// the pool are not touched. Use WithOnConnect at Connect time for hooks that
+// must run on every connection.
+//
+// It returns an error rather than silently doing nothing when the DB is not
+// connected yet (Connect would discard the hook) or is shutting down.
+//
+// Example:
+//
+//	err := db.AddConnectionHookLive(func(conn *pgx.Conn) error {
+//	    _, err := conn.Exec(context.Background(), "SET statement_timeout = '5s'")
+//	    return err
+//	})
+func (db *DB) AddConnectionHookLive(fn func(*pgx.Conn) error) error {
+	if fn == nil {
+		return fmt.Errorf("connection hook cannot be nil")
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.shutdown {
+		return fmt.Errorf("database is shutting down")
+	}
+	if db.writePool == nil {
+		return fmt.Errorf("database is not connected: connection hooks added before Connect must be passed as ConnectOptions (e.g. WithOnConnect)")
+	}
+	db.hooks.connectionHooks.addOnConnect(fn)
+	return nil
+}
+
+// AddRewriteHook registers a hook that can replace the SQL and arguments of
+// every Query, QueryRow, Exec and their Read variants, of transaction
+// statements, and of each query in a batch (the queued queries are updated in
+// place). It may be called before or after Connect. Hooks run in the order
+// they were added and before any BeforeOperation hook, so logging and metrics
+// hooks see the statement as sent:
+//
+//	db.AddRewriteHook(func(ctx context.Context, sql string, args []interface{}) (string, []interface{}, error) {
+//	    return sql + " /* tenant=" + tenantID(ctx) + " */", args, nil
+//	})
+//
+// WithReadOnlyGuard and WithAutoReadRouting classify the original statement.
+// Statements run by name through ExecPrepared and QueryPrepared are not
+// rewritten.
+func (db *DB) AddRewriteHook(fn RewriteHook) error {
+	if fn == nil {
+		return fmt.Errorf("rewrite hook cannot be nil")
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.shutdown {
+		return fmt.Errorf("database is shutting down")
+	}
+	db.hooks.addRewriteHook(fn)
+	return nil
+}
+
+// HookCount returns how many operation-level hooks of hookType are registered.
+// It is meant for tests and diagnostics, e.g. asserting that a metrics hook
+// was registered exactly once.
+func (db *DB) HookCount(hookType HookType) int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.hooks.count(hookType)
+}
+
+// ConnectionHookCount returns how many connection hooks of kind are
+// registered. kind is "OnConnect", "OnDisconnect", "OnAcquire" or
+// "OnRelease", matching the With* options; any other kind returns 0.
+func (db *DB) ConnectionHookCount(kind string) int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.hooks.connectionHooks.count(kind)
+}
+
+// WithConnectRetry makes Connect and ConnectReadWrite retry pool creation and
+// an initial Ping with exponential backoff while the error is transient
+// (IsRetryableError), e.g. while Postgres is still starting in a container.
+// opts tune the backoff just like for RetryOperation; with none, the retry
+// defaults apply. Permanent errors such as bad credentials fail immediately.
+//
+// Without this option Connect doesn't ping, and the first query surfaces an
+// unreachable database.
+//
+// Example:
+//
+//	err := db.Connect(ctx, dsn, pgxkit.WithConnectRetry(
+//	    pgxkit.WithMaxRetries(10),
+//	    pgxkit.WithMaxDelay(5*time.Second),
+//	))
+func WithConnectRetry(opts ...RetryOption) ConnectOption {
+	return func(c *connectConfig) {
+		c.connectRetry = append([]RetryOption{}, opts...)
+	}
+}
+
+// applyPoolSizing applies the pool size and connection lifetime options to
+// config. maxConns and minConns are the per-pool overrides, such as
+// WithReadMaxConns, and take precedence over WithMaxConns and WithMinConns;
+// pass 0 in single pool mode.
+func (c *connectConfig) applyPoolSizing(config *pgxpool.Config, maxConns, minConns int32) {
+	if maxConns <= 0 {
+		maxConns = c.maxConns
+	}
+	if minConns <= 0 {
+		minConns = c.minConns
+	}
+	sizing := DBConfig{
+		MaxConns:        maxConns,
+		MinConns:        minConns,
+		MaxConnLifetime: c.maxConnLifetime,
+		MaxConnIdleTime: c.maxConnIdleTime,
+	}
+	sizing.Apply(config)
+}
+
+// openPool creates a pool through the configured constructor, retrying
+// transient failures when WithConnectRetry is set.
+func (c *connectConfig) openPool(ctx context.Context, config *pgxpool.Config) (*pgxpool.Pool, error) {
+	if c.connectRetry == nil {
+		return c.poolConstructor(ctx, config)
+	}
+	ping := c.ping
+	if ping == nil {
+		ping = func(ctx context.Context, pool *pgxpool.Pool) error { return pool.Ping(ctx) }
+	}
+	return Retry(ctx, func(ctx context.Context) (*pgxpool.Pool, error) {
+		pool, err := c.poolConstructor(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		if err := ping(ctx, pool); err != nil {
+			pool.Close()
+			return nil, err
+		}
+		return pool, nil
+	}, c.connectRetry...)
+}
+
 // PoolConstructor builds a *pgxpool.Pool from a fully-prepared *pgxpool.Config.
 // It matches the signature of pgxpool.NewWithConfig, which is the default.
 type PoolConstructor func(ctx context.Context, config *pgxpool.Config) (*pgxpool.Pool, error)
@@ -412,36 +845,42 @@ func (db *DB) Connect(ctx context.Context, dsn string, opts ...ConnectOption) er
 		opt(cfg)
 	}
 
-	if cfg.maxConns > 0 {
-		config.MaxConns = cfg.maxConns
-	}
-	if cfg.minConns > 0 {
-		config.MinConns = cfg.minConns
-	}
-	if cfg.maxConnLifetime > 0 {
-		config.MaxConnLifetime = cfg.maxConnLifetime
-	}
-	if cfg.maxConnIdleTime > 0 {
-		config.MaxConnIdleTime = cfg.maxConnIdleTime
-	}
+	cfg.applyPoolSizing(config, 0, 0)
+	cfg.applyConnConfig(config)
 
+	cfg.hooks.inheritRewriteHooks(db.hooks)
 	db.hooks = cfg.hooks
 	db.hooks.configurePool(config)
+	db.connAges.configurePool(config)
 
-	pool, err := cfg.poolConstructor(ctx, config)
+	pool, err := cfg.openPool(ctx, config)
 	if err != nil {
 		return fmt.Errorf("failed to create pool: %w", err)
 	}
 
+	if cfg.refreshOnAdminShutdown {
+		db.hooks.onOperationError = db.refreshOnShutdownError()
+	}
 	db.readPool = pool
 	db.writePool = pool
+	db.trackPools()
+	db.openPool = cfg.openPool
+	db.queryTimeout = cfg.queryTimeout
+	db.defaultQueryTimeout = cfg.defaultTimeout
+	db.rollbackLog = cfg.rollbackLog
+	db.label = cfg.label
+	db.readOnlyGuard = cfg.readOnlyGuard
+	db.autoReadRouting = cfg.autoReadRouting
+	db.maxOpenTx = cfg.maxOpenTx
+	db.activeOps.disabled = cfg.disableOpTracking
 
 	return nil
 }
 
 // ConnectReadWrite establishes database connections with separate read and write pools.
 // If readDSN or writeDSN is empty, it uses environment variables to construct the connection string.
-// Options are applied to both pools.
+// Options are applied to both pools. An empty readDSN therefore reads from the same
+// env-derived database as writes; use WithRequireDistinctPools to reject that.
 //
 // This is useful for applications that want to optimize read performance by routing
 // read queries to read replicas while ensuring writes go to the primary database.
@@ -483,71 +922,60 @@ func (db *DB) ConnectReadWrite(ctx context.Context, readDSN, writeDSN string, op
 		opt(cfg)
 	}
 
-	readMaxConns := cfg.maxConns
-	if cfg.readMaxConns > 0 {
-		readMaxConns = cfg.readMaxConns
-	}
-	if readMaxConns > 0 {
-		readConfig.MaxConns = readMaxConns
+	if cfg.requireDistinctPools && sameDatabase(readConfig, writeConfig) {
+		return fmt.Errorf("read and write DSNs both resolve to %s; WithRequireDistinctPools requires separate databases",
+			describeTarget(writeConfig))
 	}
 
-	readMinConns := cfg.minConns
-	if cfg.readMinConns > 0 {
-		readMinConns = cfg.readMinConns
-	}
-	if readMinConns > 0 {
-		readConfig.MinConns = readMinConns
-	}
-
-	writeMaxConns := cfg.maxConns
-	if cfg.writeMaxConns > 0 {
-		writeMaxConns = cfg.writeMaxConns
-	}
-	if writeMaxConns > 0 {
-		writeConfig.MaxConns = writeMaxConns
-	}
-
-	writeMinConns := cfg.minConns
-	if cfg.writeMinConns > 0 {
-		writeMinConns = cfg.writeMinConns
-	}
-	if writeMinConns > 0 {
-		writeConfig.MinConns = writeMinConns
-	}
-
-	if cfg.maxConnLifetime > 0 {
-		readConfig.MaxConnLifetime = cfg.maxConnLifetime
-		writeConfig.MaxConnLifetime = cfg.maxConnLifetime
-	}
-	if cfg.maxConnIdleTime > 0 {
-		readConfig.MaxConnIdleTime = cfg.maxConnIdleTime
-		writeConfig.MaxConnIdleTime = cfg.maxConnIdleTime
-	}
+	cfg.applyPoolSizing(readConfig, cfg.readMaxConns, cfg.readMinConns)
+	cfg.applyPoolSizing(writeConfig, cfg.writeMaxConns, cfg.writeMinConns)
+	cfg.applyConnConfig(readConfig)
+	cfg.applyConnConfig(writeConfig)
 
+	cfg.hooks.inheritRewriteHooks(db.hooks)
 	db.hooks = cfg.hooks
 	db.hooks.configurePool(readConfig)
 	db.hooks.configurePool(writeConfig)
+	db.connAges.configurePool(readConfig)
+	db.connAges.configurePool(writeConfig)
 
-	readPool, err := cfg.poolConstructor(ctx, readConfig)
+	readPool, err := cfg.openPool(ctx, readConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create read pool: %w", err)
 	}
 
-	writePool, err := cfg.poolConstructor(ctx, writeConfig)
+	writePool, err := cfg.openPool(ctx, writeConfig)
 	if err != nil {
 		readPool.Close()
 		return fmt.Errorf("failed to create write pool: %w", err)
 	}
 
+	if cfg.refreshOnAdminShutdown {
+		db.hooks.onOperationError = db.refreshOnShutdownError()
+	}
 	db.readPool = readPool
 	db.writePool = writePool
+	db.trackPools()
+	db.openPool = cfg.openPool
+	db.queryTimeout = cfg.queryTimeout
+	db.defaultQueryTimeout = cfg.defaultTimeout
+	db.rollbackLog = cfg.rollbackLog
+	db.label = cfg.label
+	db.readOnlyGuard = cfg.readOnlyGuard
+	db.autoReadRouting = cfg.autoReadRouting
+	db.maxOpenTx = cfg.maxOpenTx
+	db.activeOps.disabled = cfg.disableOpTracking
+	db.readFallbackToWrite = cfg.readFallbackToWrite
+	db.readReadiness = cfg.readReadiness
+	db.startReadHealthCheck(cfg.readHealthCheckInterval)
 
 	return nil
 }
 
 // Query executes a query using the write pool (safe by default).
 // This ensures consistency by always using the primary database connection.
-// Use ReadQuery for read-only queries that can benefit from read replicas.
+// Use ReadQuery for read-only queries that can benefit from read replicas,
+// or WithAutoReadRouting to route plain SELECTs there automatically.
 //
 // Example:
 //
@@ -557,19 +985,20 @@ func (db *DB) ConnectReadWrite(ctx context.Context, readDSN, writeDSN string, op
 //	}
 //	defer rows.Close()
 func (db *DB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
-	return db.executeQuery(ctx, db.writePool, sql, args...)
+	return db.executeQuery(ctx, func() (*pgxpool.Pool, PoolSelector) { return db.queryTarget(ctx, sql) }, sql, args...)
 }
 
 // QueryRow executes a query that returns a single row using the write pool.
 // This ensures consistency by always using the primary database connection.
-// Use ReadQueryRow for read-only queries that can benefit from read replicas.
+// Use ReadQueryRow for read-only queries that can benefit from read replicas,
+// or WithAutoReadRouting to route plain SELECTs there automatically.
 //
 // Example:
 //
 //	var userID int
 //	err := db.QueryRow(ctx, "SELECT id FROM users WHERE email = $1", email).Scan(&userID)
 func (db *DB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
-	return db.executeQueryRow(ctx, db.writePool, sql, args...)
+	return db.executeQueryRow(ctx, func() (*pgxpool.Pool, PoolSelector) { return db.queryTarget(ctx, sql) }, sql, args...)
 }
 
 // Exec executes a statement using the write pool.
@@ -583,12 +1012,13 @@ func (db *DB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx
 //	}
 //	fmt.Printf("Inserted %d rows\n", tag.RowsAffected())
 func (db *DB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
-	return db.executeExec(ctx, db.writePool, sql, args...)
+	return db.executeExec(ctx, db.writeTarget, sql, args...)
 }
 
 // ReadQuery executes a query using the read pool (explicit optimization).
 // This method routes the query to read replicas when available, improving performance
 // for read-heavy workloads. Only use this for queries that can tolerate read replica lag.
+// With WithReadFallbackToWrite, the write pool is used while the replica is unhealthy.
 //
 // Example:
 //
@@ -598,7 +1028,12 @@ func (db *DB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn
 //	}
 //	defer rows.Close()
 func (db *DB) ReadQuery(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
-	return db.executeQuery(ctx, db.readPool, sql, args...)
+	if db.readOnlyGuard {
+		if err := checkReadOnly(sql); err != nil {
+			return nil, err
+		}
+	}
+	return db.executeQuery(ctx, db.readTarget, sql, args...)
 }
 
 // ReadQueryRow executes a query that returns a single row using the read pool.
@@ -610,7 +1045,12 @@ func (db *DB) ReadQuery(ctx context.Context, sql string, args ...interface{}) (p
 //	var count int
 //	err := db.ReadQueryRow(ctx, "SELECT COUNT(*) FROM users").Scan(&count)
 func (db *DB) ReadQueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
-	return db.executeQueryRow(ctx, db.readPool, sql, args...)
+	if db.readOnlyGuard {
+		if err := checkReadOnly(sql); err != nil {
+			return &shutdownRow{err: err}
+		}
+	}
+	return db.executeQueryRow(ctx, db.readTarget, sql, args...)
 }
 
 // BeginTx starts a transaction using the write pool.
@@ -637,33 +1077,62 @@ func (db *DB) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (*Tx, error)
 		db.mu.RUnlock()
 		return nil, fmt.Errorf("database is shutting down")
 	}
+	pool := db.writePool
+	db.activeOps.Add(1)
+	users := db.holdPool(pool)
 	db.mu.RUnlock()
+	// The transaction holds its connection once begun, which keeps the pool
+	// open on its own.
+	defer db.releasePool(users)
+
+	holdsSlot := false
+	if db.maxOpenTx > 0 {
+		if !db.reserveTxSlot() {
+			db.activeOps.Done()
+			return nil, fmt.Errorf("%w: limit is %d", ErrTooManyOpenTransactions, db.maxOpenTx)
+		}
+		holdsSlot = true
+	}
 
 	if err := db.hooks.executeBeforeTransaction(ctx, "", nil, pgconn.CommandTag{}, nil); err != nil {
+		if holdsSlot {
+			db.openTx.Add(-1)
+		}
+		db.activeOps.Done()
 		return nil, fmt.Errorf("before transaction hook failed: %w", err)
 	}
 
-	pgxTx, err := db.writePool.BeginTx(ctx, txOptions)
+	pgxTx, done, err := db.beginWriteTx(withoutTracer(ctx), pool, txOptions)
 	if err != nil {
+		if holdsSlot {
+			db.openTx.Add(-1)
+		}
+		db.activeOps.Done()
 		if hookErr := db.hooks.executeAfterTransaction(ctx, "", nil, pgconn.CommandTag{}, err); hookErr != nil {
 			return nil, errors.Join(err, fmt.Errorf("after transaction hook failed: %w", hookErr))
 		}
 		return nil, err
 	}
 
-	db.activeOps.Add(1)
-	return &Tx{tx: pgxTx, db: db}, nil
+	tx := &Tx{tx: pgxTx, db: db, done: done, startedAt: time.Now(), options: txOptions, holdsSlot: holdsSlot}
+	if db.rollbackLog != nil {
+		tx.statements = &txStatements{limit: db.rollbackLog.limit}
+	}
+	return tx, nil
 }
 
 // Shutdown gracefully shuts down the database connections.
 // It waits for active operations to complete, respecting the context timeout.
-// If the context times out, shutdown proceeds anyway to prevent hanging.
+// If the context times out, shutdown proceeds anyway to prevent hanging and
+// returns a *ShutdownTimeoutError with the number of operations still in flight.
 //
 // The shutdown process:
 // 1. Marks the database as shutting down (new operations will fail)
 // 2. Waits for active operations to complete (respects context timeout)
-// 3. Executes OnShutdown hooks
-// 4. Closes connection pools
+// 3. Returns idle connections pinned by WithConnAffinity to the pool
+// 4. Runs statements registered with AddShutdownQuery
+// 5. Executes OnShutdown hooks, with a ShutdownSummary in their context
+// 6. Closes connection pools
 //
 // Example:
 //
@@ -685,13 +1154,25 @@ func (db *DB) Shutdown(ctx context.Context) error {
 		close(done)
 	}()
 
+	var timeoutErr error
+	var summary ShutdownSummary
 	select {
 	case <-done:
 	case <-ctx.Done():
+		if n := db.InFlight(); n > 0 {
+			timeoutErr = &ShutdownTimeoutError{InFlight: n}
+			summary = ShutdownSummary{TimedOut: true, InFlight: n}
+		}
 	}
 
-	if err := db.hooks.executeOnShutdown(ctx, "", nil, pgconn.CommandTag{}, nil); err != nil {
-		return fmt.Errorf("shutdown hook failed: %w", err)
+	db.pinned.releaseAll()
+	db.stopReadHealthCheck()
+	db.stopMonitors()
+	db.runShutdownQueries(ctx)
+
+	hookCtx := context.WithValue(ctx, shutdownSummaryKey{}, summary)
+	if err := db.hooks.executeOnShutdown(hookCtx, "", nil, pgconn.CommandTag{}, nil); err != nil {
+		return errors.Join(timeoutErr, fmt.Errorf("shutdown hook failed: %w", err))
 	}
 
 	if db.readPool != nil && db.readPool != db.writePool {
@@ -701,7 +1182,7 @@ func (db *DB) Shutdown(ctx context.Context) error {
 		db.writePool.Close()
 	}
 
-	return nil
+	return timeoutErr
 }
 
 // Stats returns statistics for the write pool.
@@ -716,10 +1197,11 @@ func (db *DB) Shutdown(ctx context.Context) error {
 //	    log.Printf("Idle connections: %d", stats.IdleConns())
 //	}
 func (db *DB) Stats() *pgxpool.Stat {
-	if db.writePool == nil {
+	pool := db.WritePool()
+	if pool == nil {
 		return nil
 	}
-	return db.writePool.Stat()
+	return pool.Stat()
 }
 
 // ReadStats returns statistics for the read pool.
@@ -733,21 +1215,26 @@ func (db *DB) Stats() *pgxpool.Stat {
 //	    log.Printf("Read pool active connections: %d", stats.AcquiredConns())
 //	}
 func (db *DB) ReadStats() *pgxpool.Stat {
-	if db.readPool == nil {
+	pool := db.ReadPool()
+	if pool == nil {
 		return nil
 	}
-	return db.readPool.Stat()
+	return pool.Stat()
 }
 
 // WritePool returns the underlying write connection pool.
 // Useful for integrating with code generation tools like sqlc.
 func (db *DB) WritePool() *pgxpool.Pool {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 	return db.writePool
 }
 
 // ReadPool returns the underlying read connection pool.
 // Returns nil if no separate read pool is configured.
 func (db *DB) ReadPool() *pgxpool.Pool {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 	return db.readPool
 }
 
@@ -763,27 +1250,56 @@ func (db *DB) ReadPool() *pgxpool.Pool {
 //	    return
 //	}
 func (db *DB) HealthCheck(ctx context.Context) error {
+	return db.pingPool(ctx, false)
+}
+
+// ReadHealthCheck is HealthCheck for the read pool. In single pool mode it
+// pings the same pool as HealthCheck.
+func (db *DB) ReadHealthCheck(ctx context.Context) error {
+	return db.pingPool(ctx, true)
+}
+
+func (db *DB) pingPool(ctx context.Context, read bool) error {
+	_, err := db.timedPing(ctx, read)
+	return err
+}
+
+// timedPing pings the write (or read) pool and returns how long the ping took.
+// The latency is zero when the pool could not be pinged at all.
+func (db *DB) timedPing(ctx context.Context, read bool) (time.Duration, error) {
 	if ctx == nil {
-		return fmt.Errorf("context cannot be nil")
+		return 0, fmt.Errorf("context cannot be nil")
 	}
 
 	db.mu.RLock()
 	if db.shutdown {
 		db.mu.RUnlock()
-		return fmt.Errorf("database is shutting down")
+		return 0, fmt.Errorf("database is shutting down")
 	}
-	if db.writePool == nil {
+	pool := db.writePool
+	if read {
+		pool = db.readPool
+	}
+	if pool == nil {
 		db.mu.RUnlock()
-		return fmt.Errorf("database is not connected")
+		return 0, fmt.Errorf("database is not connected")
 	}
-	pool := db.writePool
+	users := db.holdPool(pool)
 	db.mu.RUnlock()
+	defer db.releasePool(users)
 
-	return pool.Ping(ctx)
+	ping := db.ping
+	if ping == nil {
+		ping = func(ctx context.Context, pool *pgxpool.Pool) error { return pool.Ping(ctx) }
+	}
+	start := time.Now()
+	err := ping(ctx, pool)
+	return time.Since(start), err
 }
 
 // IsReady checks if the database connection is ready to accept queries.
-// This is a convenience method that returns true if HealthCheck() succeeds.
+// This is a convenience method that returns true if HealthCheck() succeeds,
+// and, with WithReadReadiness, ReadHealthCheck() too.
 // It's useful for readiness probes and quick status checks.
 //
 // Example:
@@ -792,25 +1308,33 @@ func (db *DB) HealthCheck(ctx context.Context) error {
 //	    log.Println("Database is ready to accept queries")
 //	}
 func (db *DB) IsReady(ctx context.Context) bool {
-	return db.HealthCheck(ctx) == nil
+	if db.HealthCheck(ctx) != nil {
+		return false
+	}
+	if db.readReadiness && db.readPool != db.writePool {
+		return db.ReadHealthCheck(ctx) == nil
+	}
+	return true
 }
 
-func (db *DB) executeQuery(ctx context.Context, pool *pgxpool.Pool, sql string, args ...interface{}) (pgx.Rows, error) {
-	db.mu.RLock()
-	if db.shutdown {
-		db.mu.RUnlock()
-		return nil, fmt.Errorf("database is shutting down")
-	}
-	if pool == nil {
-		db.mu.RUnlock()
-		return nil, fmt.Errorf("database is not connected")
+func (db *DB) executeQuery(ctx context.Context, pick func() (*pgxpool.Pool, PoolSelector), sql string, args ...interface{}) (pgx.Rows, error) {
+	pool, role, users, err := db.startOp(pick)
+	if err != nil {
+		return nil, err
 	}
-	db.mu.RUnlock()
+	defer db.endOp(users)
 
-	db.activeOps.Add(1)
-	defer db.activeOps.Done()
+	ctx, cancel := db.operationContext(ctx)
+
+	sql, args, err = db.hooks.executeRewrite(ctx, sql, args)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
 
+	ctx = withPoolRole(withHookState(ctx), role)
 	if err := db.hooks.executeBeforeOperation(ctx, sql, args, pgconn.CommandTag{}, nil); err != nil {
+		cancel()
 		return nil, fmt.Errorf("before operation hook failed: %w", err)
 	}
 
@@ -821,56 +1345,65 @@ func (db *DB) executeQuery(ctx context.Context, pool *pgxpool.Pool, sql string,
 			rows.Close()
 		}
 		if err == nil {
+			cancel()
 			return nil, fmt.Errorf("after operation hook failed: %w", hookErr)
 		}
 	}
 
-	return rows, err
+	if err != nil {
+		cancel()
+		return rows, err
+	}
+	return &cancelRows{Rows: rows, cancel: cancel}, nil
 }
 
-func (db *DB) executeQueryRow(ctx context.Context, pool *pgxpool.Pool, sql string, args ...interface{}) pgx.Row {
-	db.mu.RLock()
-	if db.shutdown {
-		db.mu.RUnlock()
-		return &shutdownRow{err: fmt.Errorf("database is shutting down")}
-	}
-	if pool == nil {
-		db.mu.RUnlock()
-		return &shutdownRow{err: fmt.Errorf("database is not connected")}
+func (db *DB) executeQueryRow(ctx context.Context, pick func() (*pgxpool.Pool, PoolSelector), sql string, args ...interface{}) pgx.Row {
+	pool, role, users, err := db.startOp(pick)
+	if err != nil {
+		return &shutdownRow{err: err}
 	}
-	db.mu.RUnlock()
+	defer db.endOp(users)
 
-	db.activeOps.Add(1)
-	defer db.activeOps.Done()
+	ctx, cancel := db.operationContext(ctx)
+
+	sql, args, err = db.hooks.executeRewrite(ctx, sql, args)
+	if err != nil {
+		cancel()
+		return &shutdownRow{err: err}
+	}
 
+	ctx = withPoolRole(withHookState(ctx), role)
 	if err := db.hooks.executeBeforeOperation(ctx, sql, args, pgconn.CommandTag{}, nil); err != nil {
+		cancel()
 		return &shutdownRow{err: fmt.Errorf("before operation hook failed: %w", err)}
 	}
 
 	row := pool.QueryRow(ctx, sql, args...)
 
 	if hookErr := db.hooks.executeAfterOperation(ctx, sql, args, pgconn.CommandTag{}, nil); hookErr != nil {
+		cancel()
 		return &shutdownRow{err: fmt.Errorf("after operation hook failed: %w", hookErr)}
 	}
 
-	return row
+	return &cancelRow{row: row, cancel: cancel}
 }
 
-func (db *DB) executeExec(ctx context.Context, pool *pgxpool.Pool, sql string, args ...interface{}) (pgconn.CommandTag, error) {
-	db.mu.RLock()
-	if db.shutdown {
-		db.mu.RUnlock()
-		return pgconn.CommandTag{}, fmt.Errorf("database is shutting down")
-	}
-	if pool == nil {
-		db.mu.RUnlock()
-		return pgconn.CommandTag{}, fmt.Errorf("database is not connected")
+func (db *DB) executeExec(ctx context.Context, pick func() (*pgxpool.Pool, PoolSelector), sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	pool, role, users, err := db.startOp(pick)
+	if err != nil {
+		return pgconn.CommandTag{}, err
 	}
-	db.mu.RUnlock()
+	defer db.endOp(users)
 
-	db.activeOps.Add(1)
-	defer db.activeOps.Done()
+	ctx, cancel := db.operationContext(ctx)
+	defer cancel()
+
+	sql, args, err = db.hooks.executeRewrite(ctx, sql, args)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
 
+	ctx = withPoolRole(withHookState(ctx), role)
 	if err := db.hooks.executeBeforeOperation(ctx, sql, args, pgconn.CommandTag{}, nil); err != nil {
 		return pgconn.CommandTag{}, fmt.Errorf("before operation hook failed: %w", err)
 	}
@@ -0,0 +1,68 @@
+package pgxkit
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DBConfig holds the pool size and connection lifetime settings, for loading
+// them from a config file once and using them both with Connect and with
+// pools built by hand. Zero values leave the pgxpool defaults in place.
+type DBConfig struct {
+	MaxConns        int32
+	MinConns        int32
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+}
+
+// Apply sets the non-zero fields of c on config, the same mapping Connect
+// uses. Use it when creating a *pgxpool.Pool yourself:
+//
+//	config, err := pgxpool.ParseConfig(dsn)
+//	if err != nil {
+//	    return err
+//	}
+//	dbConfig.Apply(config)
+//	pool, err := pgxpool.NewWithConfig(ctx, config)
+func (c *DBConfig) Apply(config *pgxpool.Config) {
+	if c == nil {
+		return
+	}
+	if c.MaxConns > 0 {
+		config.MaxConns = c.MaxConns
+	}
+	if c.MinConns > 0 {
+		config.MinConns = c.MinConns
+	}
+	if c.MaxConnLifetime > 0 {
+		config.MaxConnLifetime = c.MaxConnLifetime
+	}
+	if c.MaxConnIdleTime > 0 {
+		config.MaxConnIdleTime = c.MaxConnIdleTime
+	}
+}
+
+// WithConfig applies the non-zero fields of cfg as if passed to WithMaxConns,
+// WithMinConns, WithMaxConnLifetime and WithMaxConnIdleTime. A nil cfg is
+// ignored.
+//
+// Example:
+//
+//	err := db.Connect(ctx, dsn, pgxkit.WithConfig(&pgxkit.DBConfig{
+//	    MaxConns:        25,
+//	    MaxConnLifetime: time.Hour,
+//	}))
+func WithConfig(cfg *DBConfig) ConnectOption {
+	return func(c *connectConfig) {
+		if cfg == nil {
+			return
+		}
+		WithMaxConns(cfg.MaxConns)(c)
+		if cfg.MinConns > 0 {
+			c.minConns = cfg.MinConns
+		}
+		WithMaxConnLifetime(cfg.MaxConnLifetime)(c)
+		WithMaxConnIdleTime(cfg.MaxConnIdleTime)(c)
+	}
+}
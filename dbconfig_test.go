@@ -0,0 +1,66 @@
+package pgxkit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestDBConfigApply(t *testing.T) {
+	config, err := pgxpool.ParseConfig(lazyDSN)
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	(&DBConfig{
+		MaxConns:        12,
+		MinConns:        3,
+		MaxConnLifetime: time.Hour,
+		MaxConnIdleTime: time.Minute,
+	}).Apply(config)
+
+	if config.MaxConns != 12 {
+		t.Errorf("expected MaxConns 12, got %d", config.MaxConns)
+	}
+	if config.MinConns != 3 {
+		t.Errorf("expected MinConns 3, got %d", config.MinConns)
+	}
+	if config.MaxConnLifetime != time.Hour {
+		t.Errorf("expected MaxConnLifetime 1h, got %v", config.MaxConnLifetime)
+	}
+	if config.MaxConnIdleTime != time.Minute {
+		t.Errorf("expected MaxConnIdleTime 1m, got %v", config.MaxConnIdleTime)
+	}
+}
+
+func TestDBConfigApplySkipsZeroValues(t *testing.T) {
+	config, err := pgxpool.ParseConfig(lazyDSN)
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	want := *config
+
+	(&DBConfig{}).Apply(config)
+	var nilConfig *DBConfig
+	nilConfig.Apply(config)
+
+	if config.MaxConns != want.MaxConns || config.MinConns != want.MinConns ||
+		config.MaxConnLifetime != want.MaxConnLifetime || config.MaxConnIdleTime != want.MaxConnIdleTime {
+		t.Errorf("zero values should leave the defaults, got %d/%d/%v/%v",
+			config.MaxConns, config.MinConns, config.MaxConnLifetime, config.MaxConnIdleTime)
+	}
+}
+
+func TestWithConfig(t *testing.T) {
+	cfg := newConnectConfig()
+	WithMaxConnIdleTime(time.Minute)(cfg)
+	WithConfig(&DBConfig{MaxConns: 8, MinConns: 2, MaxConnLifetime: time.Hour})(cfg)
+	WithConfig(nil)(cfg)
+
+	if cfg.maxConns != 8 || cfg.minConns != 2 || cfg.maxConnLifetime != time.Hour {
+		t.Errorf("expected the DBConfig fields to be applied, got %d/%d/%v", cfg.maxConns, cfg.minConns, cfg.maxConnLifetime)
+	}
+	if cfg.maxConnIdleTime != time.Minute {
+		t.Errorf("a zero field should keep the earlier option, got %v", cfg.maxConnIdleTime)
+	}
+}
@@ -1,6 +1,12 @@
 package pgxkit
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
 
 // Database error types - these are generic errors that can be used by any repository.
 // These errors provide consistent error handling across database operations and can be
@@ -33,6 +39,9 @@ type NotFoundError struct {
 }
 
 func (e *NotFoundError) Error() string {
+	if e.Identifier == nil {
+		return fmt.Sprintf("%s not found", e.Entity)
+	}
 	return fmt.Sprintf("%s not found: %v", e.Entity, e.Identifier)
 }
 
@@ -103,3 +112,125 @@ func NewDatabaseError(entity, operation string, err error) *DatabaseError {
 		Err:       err,
 	}
 }
+
+// constraintViolations maps the PostgreSQL integrity-constraint SQLSTATEs that
+// MapError reports as validation failures to a short reason.
+var constraintViolations = map[string]string{
+	"23505": "unique violation",
+	"23503": "foreign key violation",
+	"23502": "not null violation",
+	"23514": "check violation",
+}
+
+// ErrServerConnectionsExhausted is matched by errors.Is on errors from
+// MapError when the server refused a connection because max_connections was
+// reached (53300). See IsTooManyConnections.
+var ErrServerConnectionsExhausted = errors.New("server connection limit reached")
+
+// MapError translates a raw pgx error into one of the typed errors above:
+//
+//   - pgx.ErrNoRows becomes a *NotFoundError (with no Identifier; build one
+//     with NewNotFoundError if you have the key at hand).
+//   - Unique, foreign-key, not-null and check violations (23505, 23503, 23502,
+//     23514) become a *ValidationError whose Field is the violated constraint
+//     (or the column, for not-null) and whose Err is the *pgconn.PgError.
+//   - too_many_connections (53300) becomes a *DatabaseError that also matches
+//     ErrServerConnectionsExhausted.
+//   - Anything else becomes a *DatabaseError wrapping err.
+//
+// A nil err returns nil.
+//
+// Example:
+//
+//	_, err := db.Exec(ctx, "INSERT INTO users (email) VALUES ($1)", email)
+//	if err != nil {
+//	    return pgxkit.MapError("User", "create", err)
+//	}
+func MapError(entity, operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return NewNotFoundError(entity, nil)
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if reason, ok := constraintViolations[pgErr.Code]; ok {
+			field := pgErr.ConstraintName
+			if field == "" {
+				field = pgErr.ColumnName
+			}
+			return NewValidationError(entity, operation, field, reason, pgErr)
+		}
+	}
+	if IsTooManyConnections(err) {
+		return NewDatabaseError(entity, operation, fmt.Errorf("%w: %w", ErrServerConnectionsExhausted, err))
+	}
+	return NewDatabaseError(entity, operation, err)
+}
+
+// pgErrorWithCode returns the *pgconn.PgError in err's chain if it has the
+// given SQLSTATE.
+func pgErrorWithCode(err error, code string) (*pgconn.PgError, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == code {
+		return pgErr, true
+	}
+	return nil, false
+}
+
+// IsUniqueViolation reports whether err is a unique violation (23505) and
+// returns the violated constraint, so callers can tell a duplicate email from
+// a duplicate username:
+//
+//	if constraint, ok := pgxkit.IsUniqueViolation(err); ok && constraint == "users_email_key" {
+//	    return ErrEmailTaken
+//	}
+func IsUniqueViolation(err error) (constraint string, ok bool) {
+	pgErr, ok := pgErrorWithCode(err, "23505")
+	if !ok {
+		return "", false
+	}
+	return pgErr.ConstraintName, true
+}
+
+// IsForeignKeyViolation reports whether err is a foreign key violation (23503)
+// and returns the violated constraint.
+func IsForeignKeyViolation(err error) (constraint string, ok bool) {
+	pgErr, ok := pgErrorWithCode(err, "23503")
+	if !ok {
+		return "", false
+	}
+	return pgErr.ConstraintName, true
+}
+
+// IsNotNullViolation reports whether err is a not-null violation (23502) and
+// returns the offending column.
+func IsNotNullViolation(err error) (column string, ok bool) {
+	pgErr, ok := pgErrorWithCode(err, "23502")
+	if !ok {
+		return "", false
+	}
+	return pgErr.ColumnName, true
+}
+
+// IsCheckViolation reports whether err is a check constraint violation (23514)
+// and returns the violated constraint.
+func IsCheckViolation(err error) (constraint string, ok bool) {
+	pgErr, ok := pgErrorWithCode(err, "23514")
+	if !ok {
+		return "", false
+	}
+	return pgErr.ConstraintName, true
+}
+
+// IsTooManyConnections reports whether err is too_many_connections (53300):
+// the server has reached max_connections. Unlike a dropped connection this is
+// a capacity problem, so it is worth alerting on and pool sizes across
+// instances may need lowering. IsRetryableError treats it as retryable, and
+// Retry waits the full max delay before the next attempt.
+func IsTooManyConnections(err error) bool {
+	_, ok := pgErrorWithCode(err, "53300")
+	return ok
+}
@@ -2,9 +2,12 @@ package pgxkit
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 func TestNewNotFoundError(t *testing.T) {
@@ -173,3 +176,139 @@ func TestErrorTypeDetection(t *testing.T) {
 		t.Error("Expected errors.As to NOT detect DatabaseError in NotFoundError")
 	}
 }
+
+func TestNotFoundErrorWithoutIdentifier(t *testing.T) {
+	if got := NewNotFoundError("User", nil).Error(); got != "User not found" {
+		t.Errorf("expected 'User not found', got %q", got)
+	}
+}
+
+func TestMapError(t *testing.T) {
+	if MapError("User", "create", nil) != nil {
+		t.Error("MapError(nil) should return nil")
+	}
+
+	var nfErr *NotFoundError
+	if err := MapError("User", "get", fmt.Errorf("lookup: %w", pgx.ErrNoRows)); !errors.As(err, &nfErr) || nfErr.Entity != "User" {
+		t.Errorf("expected NotFoundError for ErrNoRows, got %T %v", err, err)
+	}
+
+	cases := []struct {
+		pgErr      *pgconn.PgError
+		wantField  string
+		wantReason string
+	}{
+		{&pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"}, "users_email_key", "unique violation"},
+		{&pgconn.PgError{Code: "23503", ConstraintName: "orders_user_id_fkey"}, "orders_user_id_fkey", "foreign key violation"},
+		{&pgconn.PgError{Code: "23502", ColumnName: "email"}, "email", "not null violation"},
+		{&pgconn.PgError{Code: "23514", ConstraintName: "orders_total_check"}, "orders_total_check", "check violation"},
+	}
+	for _, tc := range cases {
+		err := MapError("User", "create", fmt.Errorf("insert: %w", tc.pgErr))
+		var valErr *ValidationError
+		if !errors.As(err, &valErr) {
+			t.Errorf("%s: expected ValidationError, got %T", tc.pgErr.Code, err)
+			continue
+		}
+		if valErr.Field != tc.wantField || valErr.Reason != tc.wantReason {
+			t.Errorf("%s: got field=%q reason=%q, want field=%q reason=%q", tc.pgErr.Code, valErr.Field, valErr.Reason, tc.wantField, tc.wantReason)
+		}
+		if valErr.Entity != "User" || valErr.Operation != "create" {
+			t.Errorf("%s: unexpected entity/operation %q/%q", tc.pgErr.Code, valErr.Entity, valErr.Operation)
+		}
+		var gotPgErr *pgconn.PgError
+		if !errors.As(err, &gotPgErr) || gotPgErr != tc.pgErr {
+			t.Errorf("%s: ValidationError should unwrap to the original PgError", tc.pgErr.Code)
+		}
+	}
+
+	other := &pgconn.PgError{Code: "40001"}
+	err := MapError("User", "update", other)
+	var dbErr *DatabaseError
+	if !errors.As(err, &dbErr) || dbErr.Operation != "update" {
+		t.Errorf("expected DatabaseError for %s, got %T", other.Code, err)
+	}
+	if !errors.Is(err, other) {
+		t.Error("DatabaseError should wrap the original error")
+	}
+
+	plain := errors.New("connection refused")
+	if err := MapError("User", "query", plain); !errors.As(err, &dbErr) || !errors.Is(err, plain) {
+		t.Errorf("expected DatabaseError wrapping plain error, got %T %v", err, err)
+	}
+}
+
+func TestConstraintViolationHelpers(t *testing.T) {
+	unique := &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"}
+	fk := &pgconn.PgError{Code: "23503", ConstraintName: "orders_user_id_fkey"}
+	notNull := &pgconn.PgError{Code: "23502", ColumnName: "email"}
+	check := &pgconn.PgError{Code: "23514", ConstraintName: "orders_total_check"}
+
+	helpers := map[string]func(error) (string, bool){
+		"IsUniqueViolation":     IsUniqueViolation,
+		"IsForeignKeyViolation": IsForeignKeyViolation,
+		"IsNotNullViolation":    IsNotNullViolation,
+		"IsCheckViolation":      IsCheckViolation,
+	}
+	matches := map[string]*pgconn.PgError{
+		"IsUniqueViolation":     unique,
+		"IsForeignKeyViolation": fk,
+		"IsNotNullViolation":    notNull,
+		"IsCheckViolation":      check,
+	}
+	want := map[string]string{
+		"IsUniqueViolation":     "users_email_key",
+		"IsForeignKeyViolation": "orders_user_id_fkey",
+		"IsNotNullViolation":    "email",
+		"IsCheckViolation":      "orders_total_check",
+	}
+
+	for name, helper := range helpers {
+		// Direct and wrapped errors both match.
+		for _, err := range []error{matches[name], fmt.Errorf("insert user: %w", matches[name])} {
+			got, ok := helper(err)
+			if !ok || got != want[name] {
+				t.Errorf("%s(%v) = %q, %v; want %q, true", name, err, got, ok, want[name])
+			}
+		}
+		// Every other code, a plain error and nil don't.
+		for other, pgErr := range matches {
+			if other == name {
+				continue
+			}
+			if got, ok := helper(pgErr); ok || got != "" {
+				t.Errorf("%s should not match %s, got %q, %v", name, pgErr.Code, got, ok)
+			}
+		}
+		if _, ok := helper(errors.New("boom")); ok {
+			t.Errorf("%s should not match a non-PgError", name)
+		}
+		if _, ok := helper(nil); ok {
+			t.Errorf("%s should not match nil", name)
+		}
+	}
+}
+
+func TestIsTooManyConnections(t *testing.T) {
+	tooMany := &pgconn.PgError{Code: "53300", Message: "sorry, too many clients already"}
+	if !IsTooManyConnections(tooMany) || !IsTooManyConnections(fmt.Errorf("connect: %w", tooMany)) {
+		t.Error("expected 53300 to be reported, directly and wrapped")
+	}
+	for _, err := range []error{&pgconn.PgError{Code: "57P03"}, errors.New("boom"), nil} {
+		if IsTooManyConnections(err) {
+			t.Errorf("IsTooManyConnections(%v) should be false", err)
+		}
+	}
+
+	mapped := MapError("User", "query", tooMany)
+	var dbErr *DatabaseError
+	if !errors.As(mapped, &dbErr) {
+		t.Fatalf("expected a *DatabaseError, got %T", mapped)
+	}
+	if !errors.Is(mapped, ErrServerConnectionsExhausted) || !IsTooManyConnections(mapped) {
+		t.Errorf("expected the mapped error to match ErrServerConnectionsExhausted and keep the PgError, got %v", mapped)
+	}
+	if errors.Is(MapError("User", "query", &pgconn.PgError{Code: "08006"}), ErrServerConnectionsExhausted) {
+		t.Error("other connection errors should not match ErrServerConnectionsExhausted")
+	}
+}
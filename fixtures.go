@@ -0,0 +1,108 @@
+package pgxkit
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// fixture is one SQL file queued for loading.
+type fixture struct {
+	name string
+	sql  string
+}
+
+// LoadFixtures executes SQL fixture files against the write pool. Each path
+// may be a .sql file or a directory, in which case its .sql files (not
+// recursing into subdirectories) run in lexical order. Paths run in the order
+// given.
+//
+// All fixtures run in a single transaction: if any statement fails, nothing is
+// applied. A file may contain multiple statements.
+//
+// Example:
+//
+//	testDB := pgxkit.RequireDB(t)
+//	if err := testDB.LoadFixtures(ctx, "testdata/fixtures"); err != nil {
+//	    t.Fatal(err)
+//	}
+func (tdb *TestDB) LoadFixtures(ctx context.Context, paths ...string) error {
+	var fixtures []fixture
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to stat fixture path %s: %w", path, err)
+		}
+		files := []string{path}
+		if info.IsDir() {
+			files, err = filepath.Glob(filepath.Join(path, "*.sql"))
+			if err != nil {
+				return fmt.Errorf("failed to list fixtures in %s: %w", path, err)
+			}
+			sort.Strings(files)
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read fixture %s: %w", file, err)
+			}
+			fixtures = append(fixtures, fixture{name: file, sql: string(data)})
+		}
+	}
+	return tdb.execFixtures(ctx, fixtures)
+}
+
+// LoadFixturesFS is LoadFixtures for fixtures in an fs.FS, typically an
+// embed.FS. Files matching pattern (fs.Glob syntax) run in lexical order,
+// in a single transaction.
+//
+// Example:
+//
+//	//go:embed testdata/fixtures/*.sql
+//	var fixtures embed.FS
+//
+//	err := testDB.LoadFixturesFS(ctx, fixtures, "testdata/fixtures/*.sql")
+func (tdb *TestDB) LoadFixturesFS(ctx context.Context, fsys fs.FS, pattern string) error {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return fmt.Errorf("failed to match fixtures %q: %w", pattern, err)
+	}
+	sort.Strings(files)
+
+	fixtures := make([]fixture, 0, len(files))
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return fmt.Errorf("failed to read fixture %s: %w", file, err)
+		}
+		fixtures = append(fixtures, fixture{name: file, sql: string(data)})
+	}
+	return tdb.execFixtures(ctx, fixtures)
+}
+
+func (tdb *TestDB) execFixtures(ctx context.Context, fixtures []fixture) error {
+	if len(fixtures) == 0 {
+		return fmt.Errorf("no fixtures found")
+	}
+	tx, err := tdb.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to begin fixture transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, f := range fixtures {
+		if strings.TrimSpace(f.sql) == "" {
+			continue
+		}
+		if _, err := tx.Exec(ctx, f.sql); err != nil {
+			return fmt.Errorf("failed to load fixture %s: %w", f.name, err)
+		}
+	}
+	return tx.Commit(ctx)
+}
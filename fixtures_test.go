@@ -0,0 +1,126 @@
+package pgxkit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func writeFixture(t *testing.T, dir, name, sql string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(sql), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	return path
+}
+
+func withFixtureTable(t *testing.T, testDB *TestDB) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := testDB.Exec(ctx, "CREATE TABLE IF NOT EXISTS fixtures_test (id INT PRIMARY KEY, name TEXT NOT NULL)"); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	if _, err := testDB.Exec(ctx, "TRUNCATE fixtures_test"); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = testDB.Exec(context.Background(), "DROP TABLE IF EXISTS fixtures_test")
+	})
+}
+
+func fixtureNames(t *testing.T, testDB *TestDB) []string {
+	t.Helper()
+	rows, err := testDB.Query(context.Background(), "SELECT name FROM fixtures_test ORDER BY id")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var n string
+		if err := rows.Scan(&n); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		names = append(names, n)
+	}
+	return names
+}
+
+func TestLoadFixturesMissingPath(t *testing.T) {
+	err := NewTestDB().LoadFixtures(context.Background(), filepath.Join(t.TempDir(), "missing.sql"))
+	if err == nil || !strings.Contains(err.Error(), "missing.sql") {
+		t.Errorf("expected error naming the missing path, got %v", err)
+	}
+}
+
+func TestLoadFixturesEmptyDir(t *testing.T) {
+	err := NewTestDB().LoadFixtures(context.Background(), t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "no fixtures") {
+		t.Errorf("expected no fixtures error, got %v", err)
+	}
+}
+
+func TestLoadFixturesDirectory(t *testing.T) {
+	testDB := RequireDB(t)
+	if testDB == nil {
+		return
+	}
+	withFixtureTable(t, testDB)
+
+	dir := t.TempDir()
+	writeFixture(t, dir, "02_second.sql", "INSERT INTO fixtures_test (id, name) VALUES (2, 'bob');")
+	writeFixture(t, dir, "01_first.sql", "INSERT INTO fixtures_test (id, name) VALUES (1, 'alice');\nINSERT INTO fixtures_test (id, name) VALUES (3, 'carol');")
+	writeFixture(t, dir, "notes.txt", "not sql")
+
+	if err := testDB.LoadFixtures(context.Background(), dir); err != nil {
+		t.Fatalf("LoadFixtures failed: %v", err)
+	}
+	names := fixtureNames(t, testDB)
+	if strings.Join(names, ",") != "alice,bob,carol" {
+		t.Errorf("expected alice,bob,carol, got %v", names)
+	}
+}
+
+func TestLoadFixturesRollsBackOnError(t *testing.T) {
+	testDB := RequireDB(t)
+	if testDB == nil {
+		return
+	}
+	withFixtureTable(t, testDB)
+
+	dir := t.TempDir()
+	good := writeFixture(t, dir, "good.sql", "INSERT INTO fixtures_test (id, name) VALUES (1, 'alice');")
+	bad := writeFixture(t, dir, "bad.sql", "INSERT INTO no_such_table VALUES (1);")
+
+	err := testDB.LoadFixtures(context.Background(), good, bad)
+	if err == nil || !strings.Contains(err.Error(), "bad.sql") {
+		t.Fatalf("expected error naming bad.sql, got %v", err)
+	}
+	if names := fixtureNames(t, testDB); len(names) != 0 {
+		t.Errorf("a failing fixture should leave no partial state, got %v", names)
+	}
+}
+
+func TestLoadFixturesFS(t *testing.T) {
+	testDB := RequireDB(t)
+	if testDB == nil {
+		return
+	}
+	withFixtureTable(t, testDB)
+
+	fsys := fstest.MapFS{
+		"fixtures/b.sql": {Data: []byte("INSERT INTO fixtures_test (id, name) VALUES (2, 'bob');")},
+		"fixtures/a.sql": {Data: []byte("INSERT INTO fixtures_test (id, name) VALUES (1, 'alice');")},
+	}
+	if err := testDB.LoadFixturesFS(context.Background(), fsys, "fixtures/*.sql"); err != nil {
+		t.Fatalf("LoadFixturesFS failed: %v", err)
+	}
+	names := fixtureNames(t, testDB)
+	if strings.Join(names, ",") != "alice,bob" {
+		t.Errorf("expected alice,bob, got %v", names)
+	}
+}
@@ -2,12 +2,15 @@ package pgxkit
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,9 +28,36 @@ type goldenT interface {
 
 var overwriteGolden = flag.Bool("overwrite-golden", false, "regenerate testdata/golden baselines instead of asserting")
 
+// updateGoldenEnvVar re-blesses every golden and plan baseline when set to a
+// true value, for environments where passing test flags is awkward.
+const updateGoldenEnvVar = "PGXKIT_UPDATE_GOLDEN"
+
+// shouldUpdateBaseline reports whether baselines should be overwritten rather
+// than diffed. Besides pgxkit's own -overwrite-* flag it honors
+// PGXKIT_UPDATE_GOLDEN and the conventional -update flag. pgxkit does not
+// register -update itself (that would collide with test packages that do);
+// it is picked up when the test binary defines it as a bool flag.
+func shouldUpdateBaseline(overwrite *bool) bool {
+	if overwrite != nil && *overwrite {
+		return true
+	}
+	if update, err := strconv.ParseBool(os.Getenv(updateGoldenEnvVar)); err == nil && update {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			if update, ok := getter.Get().(bool); ok && update {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 type transcriptEvent struct {
 	Step         int    `json:"step"`
 	Event        string `json:"event"`
+	Name         string `json:"name,omitempty"`
 	SQL          string `json:"sql,omitempty"`
 	Args         []any  `json:"args,omitempty"`
 	RowsAffected *int64 `json:"rows_affected,omitempty"`
@@ -40,6 +70,75 @@ const (
 	transcriptEventQuery    = "QUERY"
 )
 
+type queryNameKey struct{}
+
+// WithQueryName labels the queries run with ctx for golden and plan
+// baselines. The name is stored alongside the captured transcript event or
+// QueryPlan, and AssertGolden and AssertPlan failures refer to the query by
+// name instead of by position:
+//
+//	ctx := pgxkit.WithQueryName(ctx, "GetUserByID")
+//	err := db.QueryRow(ctx, "SELECT * FROM users WHERE id = $1", id).Scan(&u)
+//
+// Names have no effect outside EnableGolden and EnableAssertPlan. Without
+// one, the WithOp operation name is used.
+func WithQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, queryNameKey{}, name)
+}
+
+// queryNameFrom returns the WithQueryName label, falling back to the WithOp
+// operation name.
+func queryNameFrom(ctx context.Context) string {
+	if name, _ := ctx.Value(queryNameKey{}).(string); name != "" {
+		return name
+	}
+	name, _ := OpFromContext(ctx)
+	return name
+}
+
+// summarizeTranscriptDiff names the transcript events that differ between
+// baseline and current, matching them by position. Queries labelled with
+// WithQueryName are reported by name. It returns "" when either side can't be
+// decoded or the events line up, leaving the unified diff to speak for
+// itself.
+func summarizeTranscriptDiff(baseline, current []byte) string {
+	var before, after []transcriptEvent
+	if err := json.Unmarshal(baseline, &before); err != nil {
+		return ""
+	}
+	if err := json.Unmarshal(current, &after); err != nil {
+		return ""
+	}
+
+	var changes []string
+	for i := 0; i < len(before) || i < len(after); i++ {
+		switch {
+		case i >= len(before):
+			changes = append(changes, transcriptLabel(after[i])+" added")
+		case i >= len(after):
+			changes = append(changes, transcriptLabel(before[i])+" removed")
+		default:
+			b, _ := json.Marshal(before[i])
+			a, _ := json.Marshal(after[i])
+			if !bytes.Equal(b, a) {
+				changes = append(changes, transcriptLabel(before[i])+" changed")
+			}
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+	return "transcript changes:\n  " + strings.Join(changes, "\n  ")
+}
+
+// transcriptLabel names an event for summarizeTranscriptDiff.
+func transcriptLabel(ev transcriptEvent) string {
+	if ev.Name != "" {
+		return fmt.Sprintf("step %d %s %s", ev.Step, ev.Event, ev.Name)
+	}
+	return fmt.Sprintf("step %d %s", ev.Step, ev.Event)
+}
+
 // normalizer replaces volatile arg values (timestamps, UUIDs) with stable
 // placeholders so transcripts compare cleanly across runs. UUIDs use first-seen
 // ordering so the same value gets the same placeholder.
@@ -104,8 +203,46 @@ func (n *normalizer) normalizeArgs(args []any) []any {
 	return out
 }
 
+// defaultBaselineDir is where golden transcripts and plans live unless
+// TestDB.GoldenDir says otherwise.
+const defaultBaselineDir = "testdata"
+
+// baselinePath returns dir/kind/namespace/name.json, with dir defaulting to
+// testdata and namespace optional.
+func baselinePath(dir, kind, namespace, name string) string {
+	if dir == "" {
+		dir = defaultBaselineDir
+	}
+	return filepath.Join(dir, kind, namespace, name+".json")
+}
+
 func goldenPath(name string) string {
-	return filepath.Join("testdata", "golden", name+".json")
+	return baselinePath("", "golden", "", name)
+}
+
+// callerPackage returns the import path of the package whose function is
+// skip frames above its caller, or "" if it can't be determined.
+func callerPackage(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return ""
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+	return packageOfFunc(fn.Name())
+}
+
+// packageOfFunc extracts the import path from a fully qualified function
+// name such as github.com/acme/app/store.TestCreate.func1.
+func packageOfFunc(name string) string {
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return ""
+	}
+	return name[:slash+1+dot]
 }
 
 func marshalEvents(events []transcriptEvent) ([]byte, error) {
@@ -151,6 +288,14 @@ func unifiedDiff(path string, baseline, current []byte) (string, bool) {
 // otherwise diffs against the existing baseline. kind labels the artifact in
 // log/error messages (e.g. "golden transcript", "plan").
 func assertBaseline(t goldenT, path string, current []byte, kind string, overwrite bool) {
+	t.Helper()
+	assertBaselineSummarized(t, path, current, kind, overwrite, nil)
+}
+
+// assertBaselineSummarized is assertBaseline with an optional summarize func
+// that renders a human-readable description of what changed. Its output is
+// placed above the unified diff in the failure message.
+func assertBaselineSummarized(t goldenT, path string, current []byte, kind string, overwrite bool, summarize func(baseline, current []byte) string) {
 	t.Helper()
 	_, statErr := os.Stat(path)
 	missing := os.IsNotExist(statErr)
@@ -175,5 +320,11 @@ func assertBaseline(t goldenT, path string, current []byte, kind string, overwri
 	if ok {
 		return
 	}
+	if summarize != nil {
+		if summary := summarize(baseline, current); summary != "" {
+			t.Errorf("%s mismatch for %s\n%s\n%s", kind, path, summary, diff)
+			return
+		}
+	}
 	t.Errorf("%s mismatch for %s\n%s", kind, path, diff)
 }
@@ -3,14 +3,17 @@ package pgxkit
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 // withGoldenSchema creates a regular (non-temp) table for golden testing and
@@ -488,6 +491,129 @@ func TestGolden_QueryRowReturnsErrNoRowsOnEmpty(t *testing.T) {
 	g.AssertGolden(t, name)
 }
 
+func TestGolden_GateOffIsNoOp(t *testing.T) {
+	t.Setenv(goldenEnvVar, "")
+
+	tdb := NewTestDB()
+	g := tdb.EnableGolden("TestGolden_GateOff")
+	if g.goldenHook != nil {
+		t.Fatalf("EnableGolden should not install a golden hook when %s is unset", goldenEnvVar)
+	}
+	if len(g.hooks.afterOperation) != 0 || len(g.hooks.beforeTransaction) != 0 || len(g.hooks.afterTransaction) != 0 {
+		t.Errorf("EnableGolden should not register capture hooks when %s is unset", goldenEnvVar)
+	}
+
+	// Without the gate this would fail with "without an active golden hook".
+	g.AssertGolden(t, "TestGolden_GateOff")
+	if goldenFileExists("TestGolden_GateOff") {
+		t.Errorf("AssertGolden should not write a baseline when %s is unset", goldenEnvVar)
+	}
+}
+
+func TestAssertPlan_GateOffIsNoOp(t *testing.T) {
+	t.Setenv(goldenEnvVar, "0")
+
+	tdb := NewTestDB()
+	p := tdb.EnableAssertPlan("TestAssertPlan_GateOff")
+	if p.planHook != nil {
+		t.Fatalf("EnableAssertPlan should not install a plan hook when %s is off", goldenEnvVar)
+	}
+	if len(p.hooks.beforeOperation) != 0 {
+		t.Errorf("EnableAssertPlan should not register capture hooks when %s is off", goldenEnvVar)
+	}
+	p.AssertPlan(t, "TestAssertPlan_GateOff")
+}
+
+func TestGoldenEnabled(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"", false},
+		{"0", false},
+		{"false", false},
+		{"garbage", false},
+		{"1", true},
+		{"true", true},
+	}
+	for _, tt := range tests {
+		t.Setenv(goldenEnvVar, tt.value)
+		if got := goldenEnabled(); got != tt.want {
+			t.Errorf("goldenEnabled() with %s=%q = %v, want %v", goldenEnvVar, tt.value, got, tt.want)
+		}
+	}
+}
+
+// updateFlag mirrors the conventional -update flag a consuming test package
+// would define, so shouldUpdateBaseline's flag lookup can be exercised.
+var updateFlag = flag.Bool("update", false, "update golden baselines")
+
+func TestAssertBaseline_UpdateAndCompareModes(t *testing.T) {
+	t.Setenv(updateGoldenEnvVar, "")
+	path := filepath.Join(t.TempDir(), "golden", "scenario.json")
+
+	first := &capturingT{}
+	assertBaseline(first, path, []byte("v1\n"), "golden transcript", shouldUpdateBaseline(nil))
+	if first.failed {
+		t.Fatalf("first run should create the baseline, got error: %s", first.errorMsg)
+	}
+
+	compare := &capturingT{}
+	assertBaseline(compare, path, []byte("v2\n"), "golden transcript", shouldUpdateBaseline(nil))
+	if !compare.failed || !strings.Contains(compare.errorMsg, "mismatch") {
+		t.Fatalf("compare mode should fail on diff, got failed=%v msg=%q", compare.failed, compare.errorMsg)
+	}
+
+	t.Setenv(updateGoldenEnvVar, "1")
+	update := &capturingT{}
+	assertBaseline(update, path, []byte("v2\n"), "golden transcript", shouldUpdateBaseline(nil))
+	if update.failed {
+		t.Fatalf("update mode should not fail, got: %s", update.errorMsg)
+	}
+	if len(update.logs) != 1 || !strings.Contains(update.logs[0], "regenerated") {
+		t.Errorf("update mode should log that it regenerated the baseline, got %v", update.logs)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read baseline: %v", err)
+	}
+	if string(data) != "v2\n" {
+		t.Errorf("baseline should have been overwritten, got %q", data)
+	}
+
+	t.Setenv(updateGoldenEnvVar, "")
+	after := &capturingT{}
+	assertBaseline(after, path, []byte("v2\n"), "golden transcript", shouldUpdateBaseline(nil))
+	if after.failed {
+		t.Errorf("compare mode should pass against the re-blessed baseline, got: %s", after.errorMsg)
+	}
+}
+
+func TestShouldUpdateBaseline(t *testing.T) {
+	t.Setenv(updateGoldenEnvVar, "")
+	if shouldUpdateBaseline(nil) {
+		t.Error("expected no update with nothing set")
+	}
+
+	overwrite := true
+	if !shouldUpdateBaseline(&overwrite) {
+		t.Error("expected update when the specific overwrite flag is set")
+	}
+
+	t.Setenv(updateGoldenEnvVar, "true")
+	if !shouldUpdateBaseline(nil) {
+		t.Errorf("expected update when %s is set", updateGoldenEnvVar)
+	}
+	t.Setenv(updateGoldenEnvVar, "")
+
+	old := *updateFlag
+	*updateFlag = true
+	defer func() { *updateFlag = old }()
+	if !shouldUpdateBaseline(nil) {
+		t.Error("expected update when the -update flag is set")
+	}
+}
+
 // capturingT mimics enough of *testing.T for assertGolden to drive into
 // without polluting the real test result.
 type capturingT struct {
@@ -506,3 +632,139 @@ func (c *capturingT) Logf(format string, args ...any) {
 }
 
 func (c *capturingT) Helper() {}
+
+func TestGolden_QueryNameRecorded(t *testing.T) {
+	hook := &assertGoldenHook{testName: "named", normalizer: newNormalizer()}
+	ctx := WithQueryName(context.Background(), "GetUserByID")
+
+	_ = hook.afterOp(ctx, "SELECT * FROM users WHERE id = $1", []any{1}, pgconn.CommandTag{}, nil)
+	_ = hook.afterOp(context.Background(), "SELECT 1", nil, pgconn.CommandTag{}, nil)
+
+	if hook.events[0].Name != "GetUserByID" {
+		t.Errorf("expected query name on the event, got %q", hook.events[0].Name)
+	}
+	if hook.events[1].Name != "" {
+		t.Errorf("unnamed query should have no name, got %q", hook.events[1].Name)
+	}
+	data, err := marshalEvents(hook.events[1:])
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if strings.Contains(string(data), `"name"`) {
+		t.Errorf("unnamed events should not change the baseline format:\n%s", data)
+	}
+}
+
+func TestGolden_QueryNameFallsBackToOp(t *testing.T) {
+	hook := &assertGoldenHook{testName: "op", normalizer: newNormalizer()}
+	opCtx := WithOp(context.Background(), "ListOrders")
+
+	_ = hook.afterOp(opCtx, "SELECT * FROM orders", nil, pgconn.CommandTag{}, nil)
+	_ = hook.afterOp(WithQueryName(opCtx, "ListOrdersPage"), "SELECT * FROM orders LIMIT 10", nil, pgconn.CommandTag{}, nil)
+
+	if hook.events[0].Name != "ListOrders" {
+		t.Errorf("expected the op name on the event, got %q", hook.events[0].Name)
+	}
+	if hook.events[1].Name != "ListOrdersPage" {
+		t.Errorf("WithQueryName should take precedence over WithOp, got %q", hook.events[1].Name)
+	}
+}
+
+func TestGolden_DiffReportsQueryName(t *testing.T) {
+	events := func(sql string) []byte {
+		data, err := marshalEvents([]transcriptEvent{
+			{Step: 1, Event: transcriptEventBegin},
+			{Step: 2, Event: transcriptEventQuery, Name: "GetUserByID", SQL: sql},
+			{Step: 3, Event: transcriptEventCommit},
+		})
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		return data
+	}
+	path := filepath.Join(t.TempDir(), "golden", "named.json")
+	if err := writeBaseline(path, events("SELECT * FROM users WHERE id = $1")); err != nil {
+		t.Fatalf("write baseline: %v", err)
+	}
+
+	mt := &capturingT{}
+	assertBaselineSummarized(mt, path, events("SELECT * FROM users WHERE email = $1"), "golden transcript", false, summarizeTranscriptDiff)
+	if !mt.failed {
+		t.Fatal("expected transcript mismatch to fail")
+	}
+	if !strings.Contains(mt.errorMsg, "step 2 QUERY GetUserByID changed") {
+		t.Errorf("error should name the changed query, got:\n%s", mt.errorMsg)
+	}
+	if strings.Contains(mt.errorMsg, "step 1") || strings.Contains(mt.errorMsg, "step 3") {
+		t.Errorf("unchanged events should not be reported:\n%s", mt.errorMsg)
+	}
+}
+
+func TestGoldenDir_WritesAndReadsCustomDirectory(t *testing.T) {
+	t.Setenv(goldenEnvVar, "1")
+	dir := t.TempDir()
+	tdb := NewTestDB()
+	tdb.GoldenDir(dir)
+	const name = "TestGoldenDir"
+
+	golden := tdb.EnableGolden(name)
+	golden.AssertGolden(t, name)
+	path := filepath.Join(dir, "golden", name+".json")
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected baseline at %s: %v", path, err)
+	}
+	if goldenFileExists(name) {
+		t.Errorf("nothing should be written under the default testdata directory")
+	}
+
+	// A second run reads the baseline back from the custom directory.
+	tdb.EnableGolden(name).AssertGolden(t, name)
+	if err := os.WriteFile(path, []byte("[{\"step\":1,\"event\":\"BEGIN\"}]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mt := &capturingT{}
+	tdb.EnableGolden(name).assertGolden(mt, name)
+	if !mt.failed || !strings.Contains(mt.errorMsg, path) {
+		t.Errorf("expected a mismatch against %s, got %q", path, mt.errorMsg)
+	}
+
+	plans := tdb.EnableAssertPlan(name)
+	plans.AssertPlan(t, name)
+	if _, err := os.Stat(filepath.Join(dir, "plans", name+".json")); err != nil {
+		t.Errorf("expected plan baseline under the custom directory: %v", err)
+	}
+}
+
+func TestGoldenPackageNamespace(t *testing.T) {
+	t.Setenv(goldenEnvVar, "1")
+	dir := t.TempDir()
+	tdb := NewTestDB()
+	tdb.GoldenDir(dir)
+	tdb.GoldenPackageNamespace(true)
+	const name = "TestGoldenPackageNamespace"
+
+	tdb.EnableGolden(name).AssertGolden(t, name)
+	tdb.EnableAssertPlan(name).AssertPlan(t, name)
+
+	for _, kind := range []string{"golden", "plans"} {
+		path := filepath.Join(dir, kind, "github.com", "nhalm", "pgxkit", "v2", name+".json")
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s baseline namespaced by package at %s: %v", kind, path, err)
+		}
+	}
+}
+
+func TestPackageOfFunc(t *testing.T) {
+	tests := map[string]string{
+		"github.com/acme/app/store.TestCreate":             "github.com/acme/app/store",
+		"github.com/acme/app/store_test.TestCreate.func1":  "github.com/acme/app/store_test",
+		"github.com/acme/app.v2/store.(*suite).TestCreate": "github.com/acme/app.v2/store",
+		"main.main": "main",
+		"nodot":     "",
+	}
+	for fn, want := range tests {
+		if got := packageOfFunc(fn); got != want {
+			t.Errorf("packageOfFunc(%q) = %q, want %q", fn, got, want)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package pgxkit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WithReadReadiness makes IsReady require the read pool to answer a ping as
+// well as the write pool. Without it, a dead replica does not fail readiness,
+// which is what you want when reads fall back to the primary
+// (WithReadFallbackToWrite). Has no effect in single pool mode.
+func WithReadReadiness() ConnectOption {
+	return func(c *connectConfig) {
+		c.readReadiness = true
+	}
+}
+
+// HealthCheckWithLatency is HealthCheck that also reports how long the ping
+// took, so readiness probes can fail on a slow database and not just a dead
+// one. The latency is zero if the database could not be pinged at all.
+//
+// Example:
+//
+//	latency, err := db.HealthCheckWithLatency(ctx)
+//	if err != nil || latency > 200*time.Millisecond {
+//	    http.Error(w, "database degraded", http.StatusServiceUnavailable)
+//	    return
+//	}
+func (db *DB) HealthCheckWithLatency(ctx context.Context) (time.Duration, error) {
+	return db.timedPing(ctx, false)
+}
+
+// PoolSelector names one of the DB's pools, for PingPool and
+// PoolFromContext.
+type PoolSelector int
+
+const (
+	// PoolWrite selects the write pool.
+	PoolWrite PoolSelector = iota
+	// PoolRead selects the read pool. In single pool mode that is the write
+	// pool.
+	PoolRead
+)
+
+// PingPool pings the selected pool once. PingPool(ctx, PoolWrite) is
+// HealthCheck and PingPool(ctx, PoolRead) is ReadHealthCheck.
+//
+// Example:
+//
+//	if err := db.PingPool(ctx, pgxkit.PoolRead); err != nil {
+//	    log.Printf("read replica unreachable: %v", err)
+//	}
+func (db *DB) PingPool(ctx context.Context, which PoolSelector) error {
+	switch which {
+	case PoolWrite:
+		return db.pingPool(ctx, false)
+	case PoolRead:
+		return db.pingPool(ctx, true)
+	}
+	return fmt.Errorf("unknown pool selector %d", which)
+}
+
+// PingWithRetry pings the write pool, retrying transient failures with
+// RetryOperation's backoff so a brief blip doesn't fail a readiness probe,
+// and a database that is still starting can be waited for at startup. opts
+// are the usual RetryOption values. Permanent errors, such as a DB that is
+// not connected or is shutting down, are returned without retrying.
+//
+// Example:
+//
+//	err := db.PingWithRetry(ctx, pgxkit.WithMaxRetries(10), pgxkit.WithMaxDelay(2*time.Second))
+func (db *DB) PingWithRetry(ctx context.Context, opts ...RetryOption) error {
+	return RetryOperation(ctx, db.HealthCheck, opts...)
+}
+
+// PoolHealth is the result of pinging one pool.
+type PoolHealth struct {
+	// Healthy is true when the ping succeeded.
+	Healthy bool
+	// Latency is how long the ping took. Zero if the pool was never pinged.
+	Latency time.Duration
+	// Err is the ping error, or why the pool could not be pinged.
+	Err error
+	// Stats is a snapshot of the pool's statistics, nil if not connected.
+	Stats *pgxpool.Stat
+}
+
+// HealthReport describes the health of every pool behind a DB.
+type HealthReport struct {
+	Write PoolHealth
+	// Read is nil in single pool mode, where reads use the write pool.
+	Read *PoolHealth
+}
+
+// Healthy reports whether every pool in the report is healthy.
+func (r HealthReport) Healthy() bool {
+	return r.Write.Healthy && (r.Read == nil || r.Read.Healthy)
+}
+
+// HealthReport pings each pool and returns per-pool status, ping latency and
+// current pool statistics. Unlike HealthCheck, a failure is recorded in the
+// report rather than returned, so one dead replica doesn't hide the primary's
+// status.
+//
+// Example:
+//
+//	report := db.HealthReport(ctx)
+//	if report.Read != nil && !report.Read.Healthy {
+//	    log.Printf("read replica down: %v", report.Read.Err)
+//	}
+func (db *DB) HealthReport(ctx context.Context) HealthReport {
+	db.mu.RLock()
+	writePool, readPool := db.writePool, db.readPool
+	db.mu.RUnlock()
+
+	report := HealthReport{Write: db.poolHealth(ctx, writePool, false)}
+	if readPool != nil && readPool != writePool {
+		read := db.poolHealth(ctx, readPool, true)
+		report.Read = &read
+	}
+	return report
+}
+
+func (db *DB) poolHealth(ctx context.Context, pool *pgxpool.Pool, read bool) PoolHealth {
+	if pool == nil {
+		return PoolHealth{Err: fmt.Errorf("database is not connected")}
+	}
+	latency, err := db.timedPing(ctx, read)
+	return PoolHealth{
+		Healthy: err == nil,
+		Latency: latency,
+		Err:     err,
+		Stats:   pool.Stat(),
+	}
+}
+
+// healthCheckAllTimeout bounds HealthCheckAll when ctx has no deadline.
+const healthCheckAllTimeout = 5 * time.Second
+
+// WithLabel names the DB for multi-database tooling such as HealthCheckAll.
+// Empty labels are ignored.
+func WithLabel(label string) ConnectOption {
+	return func(c *connectConfig) {
+		if label != "" {
+			c.label = label
+		}
+	}
+}
+
+// Label returns the name given with WithLabel. Without one it describes the
+// write pool's target as user@host:port/database, or returns "" before
+// Connect.
+func (db *DB) Label() string {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.label != "" {
+		return db.label
+	}
+	if db.writePool == nil {
+		return ""
+	}
+	return describeTarget(db.writePool.Config())
+}
+
+// HealthCheckAll runs HealthCheck on every DB in parallel and returns each
+// result keyed by Label, with a nil error for healthy databases. All checks
+// share ctx's deadline, or a 5 second timeout if ctx has none, so one hung
+// database can't stall a readiness endpoint covering several.
+//
+// DBs without a label are keyed "db<i>" by argument position, and a label
+// seen twice gets "#<i>" appended, so every DB has its own entry.
+//
+// Example:
+//
+//	results := pgxkit.HealthCheckAll(ctx, ordersDB, usersDB)
+//	for name, err := range results {
+//	    if err != nil {
+//	        http.Error(w, name+": "+err.Error(), http.StatusServiceUnavailable)
+//	        return
+//	    }
+//	}
+func HealthCheckAll(ctx context.Context, dbs ...*DB) map[string]error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, healthCheckAllTimeout)
+		defer cancel()
+	}
+
+	keys := make([]string, len(dbs))
+	seen := make(map[string]bool, len(dbs))
+	for i, db := range dbs {
+		key := ""
+		if db != nil {
+			key = db.Label()
+		}
+		if key == "" {
+			key = fmt.Sprintf("db%d", i)
+		}
+		if seen[key] {
+			key = fmt.Sprintf("%s#%d", key, i)
+		}
+		seen[key] = true
+		keys[i] = key
+	}
+
+	errs := make([]error, len(dbs))
+	var wg sync.WaitGroup
+	for i, db := range dbs {
+		if db == nil {
+			errs[i] = fmt.Errorf("database is nil")
+			continue
+		}
+		wg.Add(1)
+		go func(i int, db *DB) {
+			defer wg.Done()
+			errs[i] = db.HealthCheck(ctx)
+		}(i, db)
+	}
+	wg.Wait()
+
+	results := make(map[string]error, len(dbs))
+	for i, key := range keys {
+		results[key] = errs[i]
+	}
+	return results
+}
+
+// VerifyRequirements lists what Verify expects of the database.
+type VerifyRequirements struct {
+	// Schemas must all appear in the session's effective search path
+	// (current_schemas(false)), which only includes schemas that exist.
+	Schemas []string
+	// Extensions must all be installed (present in pg_extension).
+	Extensions []string
+}
+
+// VerifyError is returned by Verify when a requirement is not met.
+type VerifyError struct {
+	// Pool is "write" or "read".
+	Pool              string
+	MissingSchemas    []string
+	MissingExtensions []string
+}
+
+func (e *VerifyError) Error() string {
+	var problems []string
+	if len(e.MissingSchemas) > 0 {
+		problems = append(problems, "search_path is missing schemas "+strings.Join(e.MissingSchemas, ", "))
+	}
+	if len(e.MissingExtensions) > 0 {
+		problems = append(problems, "extensions not installed: "+strings.Join(e.MissingExtensions, ", "))
+	}
+	return fmt.Sprintf("%s pool verification failed: %s", e.Pool, strings.Join(problems, "; "))
+}
+
+// Verify is a deeper readiness check than HealthCheck: besides reaching the
+// database it confirms the connection is configured the way the application
+// expects, catching a pool that is connected to the wrong database or with
+// the wrong search_path before the first query fails. In read/write split
+// mode both pools are checked, since replicas are often configured
+// separately. A requirement that isn't met is reported as a *VerifyError
+// listing everything missing.
+//
+// Example:
+//
+//	err := db.Verify(ctx, pgxkit.VerifyRequirements{
+//	    Schemas:    []string{"app"},
+//	    Extensions: []string{"pgcrypto", "pg_trgm"},
+//	})
+func (db *DB) Verify(ctx context.Context, requirements VerifyRequirements) error {
+	db.mu.RLock()
+	if db.shutdown {
+		db.mu.RUnlock()
+		return fmt.Errorf("database is shutting down")
+	}
+	writePool, readPool := db.writePool, db.readPool
+	if writePool == nil {
+		db.mu.RUnlock()
+		return fmt.Errorf("database is not connected")
+	}
+	writeUsers, readUsers := db.holdPool(writePool), db.holdPool(readPool)
+	db.mu.RUnlock()
+	defer db.releasePool(writeUsers)
+	defer db.releasePool(readUsers)
+
+	if err := verifyPool(ctx, writePool, "write", requirements); err != nil {
+		return err
+	}
+	if readPool != nil && readPool != writePool {
+		return verifyPool(ctx, readPool, "read", requirements)
+	}
+	return nil
+}
+
+func verifyPool(ctx context.Context, pool *pgxpool.Pool, name string, requirements VerifyRequirements) error {
+	var schemas, extensions []string
+	if err := pool.QueryRow(ctx, "SELECT current_schemas(false)::text[]").Scan(&schemas); err != nil {
+		return fmt.Errorf("%s pool: failed to read search_path: %w", name, err)
+	}
+	if len(requirements.Extensions) > 0 {
+		rows, err := pool.Query(ctx, "SELECT extname::text FROM pg_extension WHERE extname = ANY($1)", requirements.Extensions)
+		if err != nil {
+			return fmt.Errorf("%s pool: failed to list extensions: %w", name, err)
+		}
+		extensions, err = pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return fmt.Errorf("%s pool: failed to list extensions: %w", name, err)
+		}
+	}
+
+	verr := &VerifyError{
+		Pool:              name,
+		MissingSchemas:    missingFrom(requirements.Schemas, schemas),
+		MissingExtensions: missingFrom(requirements.Extensions, extensions),
+	}
+	if len(verr.MissingSchemas) > 0 || len(verr.MissingExtensions) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// missingFrom returns the entries of want that are not in have.
+func missingFrom(want, have []string) []string {
+	var missing []string
+	for _, w := range want {
+		if !slices.Contains(have, w) {
+			missing = append(missing, w)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,104 @@
+package pgxkit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// healthHandlerTimeout bounds the pings behind HealthHandler and
+// ReadinessHandler when the request has no earlier deadline.
+const healthHandlerTimeout = 5 * time.Second
+
+// healthResponse is the JSON body written by HealthHandler and
+// ReadinessHandler.
+type healthResponse struct {
+	Status   string              `json:"status"`
+	Pool     poolHealthResponse  `json:"pool"`
+	ReadPool *poolHealthResponse `json:"read_pool,omitempty"`
+}
+
+type poolHealthResponse struct {
+	Healthy       bool    `json:"healthy"`
+	LatencyMS     float64 `json:"latency_ms"`
+	Error         string  `json:"error,omitempty"`
+	TotalConns    int32   `json:"total_conns"`
+	IdleConns     int32   `json:"idle_conns"`
+	AcquiredConns int32   `json:"acquired_conns"`
+	MaxConns      int32   `json:"max_conns"`
+}
+
+func newPoolHealthResponse(h PoolHealth) poolHealthResponse {
+	resp := poolHealthResponse{
+		Healthy:   h.Healthy,
+		LatencyMS: float64(h.Latency) / float64(time.Millisecond),
+	}
+	if h.Err != nil {
+		resp.Error = h.Err.Error()
+	}
+	if h.Stats != nil {
+		resp.TotalConns = h.Stats.TotalConns()
+		resp.IdleConns = h.Stats.IdleConns()
+		resp.AcquiredConns = h.Stats.AcquiredConns()
+		resp.MaxConns = h.Stats.MaxConns()
+	}
+	return resp
+}
+
+// HealthHandler returns an http.Handler that runs HealthReport and responds
+// 200 when every pool is healthy and 503 otherwise, including while the DB
+// is shutting down. The JSON body carries the status and each pool's ping
+// latency, error and connection counts:
+//
+//	{"status":"ok","pool":{"healthy":true,"latency_ms":0.8,"total_conns":4,...}}
+//
+// In read/write split mode the replica is reported under "read_pool" and
+// must be healthy too.
+//
+// Example:
+//
+//	mux.Handle("/healthz", db.HealthHandler())
+func (db *DB) HealthHandler() http.Handler {
+	return db.healthHandler(HealthReport.Healthy)
+}
+
+// ReadinessHandler is HealthHandler with IsReady's rules: a dead read replica
+// only fails the check with WithReadReadiness, so a service whose reads fall
+// back to the primary keeps receiving traffic.
+//
+// Example:
+//
+//	mux.Handle("/readyz", db.ReadinessHandler())
+func (db *DB) ReadinessHandler() http.Handler {
+	return db.healthHandler(func(r HealthReport) bool {
+		if !r.Write.Healthy {
+			return false
+		}
+		return !db.readReadiness || r.Read == nil || r.Read.Healthy
+	})
+}
+
+func (db *DB) healthHandler(ok func(HealthReport) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), healthHandlerTimeout)
+		defer cancel()
+
+		report := db.HealthReport(ctx)
+		resp := healthResponse{Status: "ok", Pool: newPoolHealthResponse(report.Write)}
+		if report.Read != nil {
+			read := newPoolHealthResponse(*report.Read)
+			resp.ReadPool = &read
+		}
+		status := http.StatusOK
+		if !ok(report) {
+			resp.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
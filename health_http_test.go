@@ -0,0 +1,92 @@
+package pgxkit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serveHealth calls h and decodes the JSON response.
+func serveHealth(t *testing.T, h http.Handler) (int, healthResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected JSON content type, got %q", ct)
+	}
+	var resp healthResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON body %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
+
+func TestHealthHandlerNotConnected(t *testing.T) {
+	db := NewDB()
+	for name, h := range map[string]http.Handler{"health": db.HealthHandler(), "readiness": db.ReadinessHandler()} {
+		code, resp := serveHealth(t, h)
+		if code != http.StatusServiceUnavailable || resp.Status != "unavailable" {
+			t.Errorf("%s: expected 503 unavailable, got %d %q", name, code, resp.Status)
+		}
+		if !strings.Contains(resp.Pool.Error, "not connected") {
+			t.Errorf("%s: expected not connected error, got %q", name, resp.Pool.Error)
+		}
+	}
+}
+
+func TestHealthHandlerShutdown(t *testing.T) {
+	db := NewDB()
+	db.writePool = newLazyPool(t)
+	db.readPool = db.writePool
+	if err := db.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	code, resp := serveHealth(t, db.HealthHandler())
+	if code != http.StatusServiceUnavailable || resp.Status != "unavailable" {
+		t.Errorf("expected 503 unavailable, got %d %q", code, resp.Status)
+	}
+	if !strings.Contains(resp.Pool.Error, "shutting down") {
+		t.Errorf("expected shutting down error, got %q", resp.Pool.Error)
+	}
+}
+
+func TestHealthHandlersIntegration(t *testing.T) {
+	db := NewDB()
+	db.writePool = newIsolatedTestPool(t)
+	db.readPool = db.writePool
+
+	code, resp := serveHealth(t, db.HealthHandler())
+	if code != http.StatusOK || resp.Status != "ok" {
+		t.Fatalf("expected 200 ok, got %d %+v", code, resp)
+	}
+	if !resp.Pool.Healthy || resp.Pool.MaxConns == 0 || resp.ReadPool != nil {
+		t.Errorf("unexpected pool body: %+v", resp)
+	}
+	if code, _ := serveHealth(t, db.ReadinessHandler()); code != http.StatusOK {
+		t.Errorf("expected readiness 200, got %d", code)
+	}
+
+	// A dead replica fails health, but only fails readiness with read readiness.
+	db.readPool = newClosedPool(t)
+	if code, resp := serveHealth(t, db.HealthHandler()); code != http.StatusServiceUnavailable || resp.ReadPool == nil || resp.ReadPool.Healthy {
+		t.Errorf("expected 503 with an unhealthy read pool, got %d %+v", code, resp)
+	}
+	if code, _ := serveHealth(t, db.ReadinessHandler()); code != http.StatusOK {
+		t.Errorf("readiness should ignore the replica by default, got %d", code)
+	}
+	db.readReadiness = true
+	if code, _ := serveHealth(t, db.ReadinessHandler()); code != http.StatusServiceUnavailable {
+		t.Errorf("readiness should fail on a dead replica with read readiness, got %d", code)
+	}
+
+	db.readPool = db.writePool
+	if err := db.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if code, _ := serveHealth(t, db.ReadinessHandler()); code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 after shutdown, got %d", code)
+	}
+}
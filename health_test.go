@@ -0,0 +1,329 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestHealthReportNotConnected(t *testing.T) {
+	db := NewDB()
+	report := db.HealthReport(context.Background())
+
+	if report.Write.Healthy || report.Write.Err == nil || !strings.Contains(report.Write.Err.Error(), "not connected") {
+		t.Errorf("expected not connected write health, got %+v", report.Write)
+	}
+	if report.Write.Stats != nil {
+		t.Error("unconnected DB should report nil stats")
+	}
+	if report.Read != nil {
+		t.Error("unconnected DB should not report a read pool")
+	}
+	if report.Healthy() {
+		t.Error("unconnected DB should not be healthy")
+	}
+}
+
+func TestReadHealthCheckNotConnected(t *testing.T) {
+	db := NewDB()
+	if err := db.ReadHealthCheck(context.Background()); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected not connected error, got %v", err)
+	}
+}
+
+func TestHealthReportSplitUnreachableReplica(t *testing.T) {
+	db := NewDB()
+	db.writePool = newLazyPool(t)
+	db.readPool = newClosedPool(t)
+
+	report := db.HealthReport(context.Background())
+	if report.Read == nil {
+		t.Fatal("split mode should report the read pool")
+	}
+	if report.Read.Healthy || report.Read.Err == nil {
+		t.Errorf("closed replica should be unhealthy, got %+v", report.Read)
+	}
+	if report.Read.Stats == nil {
+		t.Error("read pool stats should be populated")
+	}
+	if report.Healthy() {
+		t.Error("report with an unhealthy pool should not be healthy")
+	}
+}
+
+func TestWithReadReadiness(t *testing.T) {
+	cfg := newConnectConfig()
+	if cfg.readReadiness {
+		t.Error("read readiness should be off by default")
+	}
+	WithReadReadiness()(cfg)
+	if !cfg.readReadiness {
+		t.Error("WithReadReadiness should enable read readiness")
+	}
+}
+
+func TestHealthReportIntegration(t *testing.T) {
+	ctx := context.Background()
+	db := NewDB()
+	db.writePool = requireTestPool(t)
+	db.readPool = newIsolatedTestPool(t)
+
+	report := db.HealthReport(ctx)
+	if !report.Write.Healthy || report.Write.Latency <= 0 || report.Write.Stats == nil {
+		t.Errorf("unexpected write health: %+v", report.Write)
+	}
+	if report.Read == nil || !report.Read.Healthy || report.Read.Stats == nil {
+		t.Fatalf("unexpected read health: %+v", report.Read)
+	}
+	if !report.Healthy() {
+		t.Error("expected healthy report")
+	}
+	if err := db.ReadHealthCheck(ctx); err != nil {
+		t.Errorf("ReadHealthCheck failed: %v", err)
+	}
+
+	// With read readiness, a dead replica fails IsReady; without it, it doesn't.
+	db.readPool = newClosedPool(t)
+	if !db.IsReady(ctx) {
+		t.Error("IsReady should ignore the replica by default")
+	}
+	db.readReadiness = true
+	if db.IsReady(ctx) {
+		t.Error("IsReady should fail on a dead replica with read readiness")
+	}
+}
+
+func TestHealthCheckWithLatencyNotConnected(t *testing.T) {
+	latency, err := NewDB().HealthCheckWithLatency(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected not connected error, got %v", err)
+	}
+	if latency != 0 {
+		t.Errorf("expected zero latency when nothing was pinged, got %v", latency)
+	}
+}
+
+func TestHealthCheckWithLatencyIntegration(t *testing.T) {
+	db := NewDB()
+	db.writePool = requireTestPool(t)
+	db.readPool = db.writePool
+
+	latency, err := db.HealthCheckWithLatency(context.Background())
+	if err != nil {
+		t.Fatalf("HealthCheckWithLatency failed: %v", err)
+	}
+	if latency <= 0 {
+		t.Errorf("expected positive latency, got %v", latency)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	cfg := newConnectConfig()
+	WithLabel("orders")(cfg)
+	WithLabel("")(cfg)
+	if cfg.label != "orders" {
+		t.Errorf("empty label should be ignored, got %q", cfg.label)
+	}
+
+	db := NewDB()
+	if got := db.Label(); got != "" {
+		t.Errorf("unconnected DB without a label should return empty, got %q", got)
+	}
+	db.writePool = newLazyPool(t)
+	if got := db.Label(); got != "user@127.0.0.1:1/db" {
+		t.Errorf("expected target-derived label, got %q", got)
+	}
+	db.label = "orders"
+	if got := db.Label(); got != "orders" {
+		t.Errorf("expected configured label, got %q", got)
+	}
+}
+
+func TestHealthCheckAllMixed(t *testing.T) {
+	shut := NewDB()
+	shut.label = "archive"
+	if err := shut.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	unreachable := NewDB()
+	unreachable.label = "orders"
+	unreachable.writePool = newLazyPool(t)
+	unreachable.readPool = unreachable.writePool
+
+	dup := NewDB()
+	dup.label = "orders"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	results := HealthCheckAll(ctx, shut, unreachable, NewDB(), dup, nil)
+
+	if len(results) != 5 {
+		t.Fatalf("expected 5 results, got %v", results)
+	}
+	if err := results["archive"]; err == nil || !strings.Contains(err.Error(), "shutting down") {
+		t.Errorf("archive: expected shutting down error, got %v", err)
+	}
+	if err := results["orders"]; err == nil {
+		t.Error("orders: expected ping error for unreachable database")
+	}
+	if err := results["db2"]; err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("db2: expected not connected error, got %v", err)
+	}
+	if err := results["orders#3"]; err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("orders#3: expected not connected error, got %v", err)
+	}
+	if err := results["db4"]; err == nil {
+		t.Error("db4: expected error for nil DB")
+	}
+}
+
+func TestHealthCheckAllIntegration(t *testing.T) {
+	healthy := NewDB()
+	healthy.label = "primary"
+	healthy.writePool = requireTestPool(t)
+	healthy.readPool = healthy.writePool
+
+	shut := NewDB()
+	shut.label = "legacy"
+	_ = shut.Shutdown(context.Background())
+
+	results := HealthCheckAll(context.Background(), healthy, shut)
+	if err, ok := results["primary"]; !ok || err != nil {
+		t.Errorf("primary: expected healthy, got %v (present=%v)", err, ok)
+	}
+	if results["legacy"] == nil {
+		t.Error("legacy: expected error for shut down DB")
+	}
+}
+
+func TestVerifyNotConnected(t *testing.T) {
+	db := NewDB()
+	if err := db.Verify(context.Background(), VerifyRequirements{}); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected not connected error, got %v", err)
+	}
+
+	db.writePool = newLazyPool(t)
+	_ = db.Shutdown(context.Background())
+	if err := db.Verify(context.Background(), VerifyRequirements{}); err == nil || !strings.Contains(err.Error(), "shutting down") {
+		t.Errorf("expected shutting down error, got %v", err)
+	}
+}
+
+func TestVerifyErrorMessage(t *testing.T) {
+	err := &VerifyError{Pool: "read", MissingSchemas: []string{"app"}, MissingExtensions: []string{"pgcrypto", "pg_trgm"}}
+	want := "read pool verification failed: search_path is missing schemas app; extensions not installed: pgcrypto, pg_trgm"
+	if err.Error() != want {
+		t.Errorf("unexpected message:\n got %q\nwant %q", err.Error(), want)
+	}
+}
+
+func TestVerifyIntegration(t *testing.T) {
+	ctx := context.Background()
+	db := NewDB()
+	db.writePool = requireTestPool(t)
+	db.readPool = db.writePool
+
+	if err := db.Verify(ctx, VerifyRequirements{Schemas: []string{"public"}, Extensions: []string{"plpgsql"}}); err != nil {
+		t.Errorf("expected default schema and plpgsql to verify, got %v", err)
+	}
+
+	err := db.Verify(ctx, VerifyRequirements{
+		Schemas:    []string{"public", "pgxkit_no_such_schema"},
+		Extensions: []string{"plpgsql", "pgxkit_no_such_extension"},
+	})
+	var verr *VerifyError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *VerifyError, got %v", err)
+	}
+	if verr.Pool != "write" ||
+		len(verr.MissingSchemas) != 1 || verr.MissingSchemas[0] != "pgxkit_no_such_schema" ||
+		len(verr.MissingExtensions) != 1 || verr.MissingExtensions[0] != "pgxkit_no_such_extension" {
+		t.Errorf("unexpected verification result: %+v", verr)
+	}
+}
+
+// pingCounter returns a fake pool ping that fails with errs in turn and then
+// succeeds, and the number of pings made.
+func pingCounter(errs ...error) (func(context.Context, *pgxpool.Pool) error, *int) {
+	calls := 0
+	return func(ctx context.Context, pool *pgxpool.Pool) error {
+		calls++
+		if calls <= len(errs) {
+			return errs[calls-1]
+		}
+		return nil
+	}, &calls
+}
+
+func TestPingWithRetryRecoversFromTransientFailures(t *testing.T) {
+	db := NewDB()
+	db.writePool = newLazyPool(t)
+	db.readPool = db.writePool
+	transient := &pgconn.PgError{Code: "57P03"} // cannot_connect_now
+	var calls *int
+	db.ping, calls = pingCounter(transient, transient)
+
+	if err := db.PingWithRetry(context.Background(), WithMaxRetries(3), WithBaseDelay(time.Millisecond)); err != nil {
+		t.Fatalf("expected the ping to recover, got %v", err)
+	}
+	if *calls != 3 {
+		t.Errorf("expected 3 pings, got %d", *calls)
+	}
+}
+
+func TestPingWithRetryGivesUp(t *testing.T) {
+	db := NewDB()
+	db.writePool = newLazyPool(t)
+	db.readPool = db.writePool
+	transient := &pgconn.PgError{Code: "57P03"}
+	var calls *int
+	db.ping, calls = pingCounter(transient, transient, transient, transient)
+
+	err := db.PingWithRetry(context.Background(), WithMaxRetries(2), WithBaseDelay(time.Millisecond))
+	if !errors.Is(err, transient) || *calls != 3 {
+		t.Errorf("expected failure after 3 pings, got %d pings and %v", *calls, err)
+	}
+
+	db.ping, calls = pingCounter(errors.New("password authentication failed"))
+	if err := db.PingWithRetry(context.Background(), WithMaxRetries(2), WithBaseDelay(time.Millisecond)); err == nil || *calls != 1 {
+		t.Errorf("permanent errors should not be retried, got %d pings and %v", *calls, err)
+	}
+}
+
+func TestPingWithRetryNotConnected(t *testing.T) {
+	err := NewDB().PingWithRetry(context.Background(), WithBaseDelay(time.Millisecond))
+	if err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected not connected error, got %v", err)
+	}
+}
+
+func TestPingPool(t *testing.T) {
+	db := NewDB()
+	db.writePool = newLazyPool(t)
+	db.readPool = newClosedPool(t)
+	var pinged []*pgxpool.Pool
+	db.ping = func(ctx context.Context, pool *pgxpool.Pool) error {
+		pinged = append(pinged, pool)
+		return nil
+	}
+	ctx := context.Background()
+
+	if err := db.PingPool(ctx, PoolWrite); err != nil {
+		t.Fatalf("PingPool(PoolWrite) failed: %v", err)
+	}
+	if err := db.PingPool(ctx, PoolRead); err != nil {
+		t.Fatalf("PingPool(PoolRead) failed: %v", err)
+	}
+	if len(pinged) != 2 || pinged[0] != db.writePool || pinged[1] != db.readPool {
+		t.Errorf("expected the write then the read pool to be pinged, got %v", pinged)
+	}
+	if err := db.PingPool(ctx, PoolSelector(9)); err == nil {
+		t.Error("expected error for an unknown selector")
+	}
+}
@@ -2,6 +2,7 @@ package pgxkit
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/jackc/pgx/v5"
@@ -34,6 +35,13 @@ const (
 	// OnShutdown is called during graceful shutdown.
 	// The sql and args parameters will be empty, operationErr will be nil.
 	OnShutdown
+
+	// AfterCommitSuccess is called only after a commit succeeds, following
+	// AfterTransaction. It never fires on rollback or on a failed commit, which
+	// makes it the place for side effects that must only happen once data is
+	// durable (publishing an event, invalidating a cache). The sql parameter is
+	// TxCommit and operationErr is always nil.
+	AfterCommitSuccess
 )
 
 // HookFunc is the universal hook function signature for operation-level hooks.
@@ -46,16 +54,105 @@ const (
 // original result but is reported.
 type HookFunc func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error
 
+// RewriteHook returns the SQL and arguments to run in place of sql and args,
+// for concerns such as tenant filters or query tagging comments. Rewrite
+// hooks run in registration order, each seeing the previous one's output,
+// before any BeforeOperation hook; Before and AfterOperation hooks therefore
+// see the rewritten statement. Returning an error aborts the operation.
+type RewriteHook func(ctx context.Context, sql string, args []interface{}) (string, []interface{}, error)
+
+type hookStateKey struct{}
+
+// hookState is the per-operation store behind HookStore.
+type hookState struct {
+	m sync.Map
+}
+
+// withHookState gives an operation a fresh store, replacing any inherited
+// from an enclosing operation so nested calls don't share state.
+func withHookState(ctx context.Context) context.Context {
+	return context.WithValue(ctx, hookStateKey{}, &hookState{})
+}
+
+// HookStore returns a store scoped to the current operation, shared by its
+// BeforeOperation and AfterOperation hooks. A before-hook can record a start
+// time, span or request metadata that the matching after-hook reads back,
+// without each hook allocating its own context key:
+//
+//	type startKey struct{}
+//
+//	err := db.Connect(ctx, dsn,
+//	    pgxkit.WithBeforeOperation(func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+//	        pgxkit.HookStore(ctx).Store(startKey{}, time.Now())
+//	        return nil
+//	    }),
+//	    pgxkit.WithAfterOperation(func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+//	        if start, ok := pgxkit.HookStore(ctx).Load(startKey{}); ok {
+//	            queryDuration.Observe(time.Since(start.(time.Time)).Seconds())
+//	        }
+//	        return nil
+//	    }),
+//	)
+//
+// Each query in a batch is its own operation with its own store. Outside an
+// operation (a hook called directly from a test, say) HookStore returns an
+// empty store that nothing else shares.
+func HookStore(ctx context.Context) *sync.Map {
+	if state, ok := ctx.Value(hookStateKey{}).(*hookState); ok {
+		return &state.m
+	}
+	return &sync.Map{}
+}
+
+type opKey struct{}
+
+// WithOp names the operations run with ctx, e.g. "GetUserByID". It is the
+// single source of operation names for hooks: a tracing hook and a metrics
+// hook that both read OpFromContext label the same query the same way,
+// instead of each deriving a name from the SQL. Golden and plan baselines
+// use it too when no WithQueryName is set.
+//
+// Example:
+//
+//	ctx = pgxkit.WithOp(ctx, "GetUserByID")
+//	err := db.QueryRow(ctx, "SELECT * FROM users WHERE id = $1", id).Scan(&u)
+func WithOp(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, opKey{}, name)
+}
+
+// OpFromContext returns the operation name set with WithOp. Hooks should
+// prefer it over the raw SQL for span names and metric labels, falling back
+// to the SQL (or a fixed label) when ok is false:
+//
+//	pgxkit.WithAfterOperation(func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+//	    op, ok := pgxkit.OpFromContext(ctx)
+//	    if !ok {
+//	        op = "unnamed"
+//	    }
+//	    queryTotal.WithLabelValues(op).Inc()
+//	    return nil
+//	})
+func OpFromContext(ctx context.Context) (name string, ok bool) {
+	name, ok = ctx.Value(opKey{}).(string)
+	return name, ok && name != ""
+}
+
 // hooks manages both operation-level and connection-level hooks
 type hooks struct {
 	mu sync.RWMutex
 
 	// Operation-level hooks
-	beforeOperation   []HookFunc
-	afterOperation    []HookFunc
-	beforeTransaction []HookFunc
-	afterTransaction  []HookFunc
-	onShutdown        []HookFunc
+	beforeOperation    []HookFunc
+	afterOperation     []HookFunc
+	beforeTransaction  []HookFunc
+	afterTransaction   []HookFunc
+	afterCommitSuccess []HookFunc
+	onShutdown         []HookFunc
+	rewrite            []RewriteHook
+
+	// onOperationError, if set, sees every error passed to the after-operation
+	// and after-transaction hooks. WithRefreshOnAdminShutdown installs it.
+	onOperationError func(error)
 
 	// Connection-level hooks (pgx native signatures)
 	connectionHooks *connectionHooks
@@ -64,12 +161,13 @@ type hooks struct {
 // newHooks creates a new hooks manager
 func newHooks() *hooks {
 	return &hooks{
-		beforeOperation:   make([]HookFunc, 0),
-		afterOperation:    make([]HookFunc, 0),
-		beforeTransaction: make([]HookFunc, 0),
-		afterTransaction:  make([]HookFunc, 0),
-		onShutdown:        make([]HookFunc, 0),
-		connectionHooks:   newConnectionHooks(),
+		beforeOperation:    make([]HookFunc, 0),
+		afterOperation:     make([]HookFunc, 0),
+		beforeTransaction:  make([]HookFunc, 0),
+		afterTransaction:   make([]HookFunc, 0),
+		afterCommitSuccess: make([]HookFunc, 0),
+		onShutdown:         make([]HookFunc, 0),
+		connectionHooks:    newConnectionHooks(),
 	}
 }
 
@@ -87,28 +185,71 @@ func (h *hooks) addHook(hookType HookType, hookFunc HookFunc) {
 		h.beforeTransaction = append(h.beforeTransaction, hookFunc)
 	case AfterTransaction:
 		h.afterTransaction = append(h.afterTransaction, hookFunc)
+	case AfterCommitSuccess:
+		h.afterCommitSuccess = append(h.afterCommitSuccess, hookFunc)
 	case OnShutdown:
 		h.onShutdown = append(h.onShutdown, hookFunc)
 	}
 }
 
-func (h *hooks) executeBeforeOperation(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error {
+// count returns the number of operation-level hooks registered for hookType.
+func (h *hooks) count(hookType HookType) int {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	for _, hook := range h.beforeOperation {
-		if err := hook(ctx, sql, args, tag, operationErr); err != nil {
-			return err
-		}
+	switch hookType {
+	case BeforeOperation:
+		return len(h.beforeOperation)
+	case AfterOperation:
+		return len(h.afterOperation)
+	case BeforeTransaction:
+		return len(h.beforeTransaction)
+	case AfterTransaction:
+		return len(h.afterTransaction)
+	case AfterCommitSuccess:
+		return len(h.afterCommitSuccess)
+	case OnShutdown:
+		return len(h.onShutdown)
 	}
-	return nil
+	return 0
 }
 
-func (h *hooks) executeAfterOperation(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error {
+// addRewriteHook adds a hook that may replace an operation's SQL and args.
+func (h *hooks) addRewriteHook(fn RewriteHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.rewrite = append(h.rewrite, fn)
+}
+
+// inheritRewriteHooks puts the rewrite hooks registered on prev (with
+// DB.AddRewriteHook before Connect) ahead of h's own, so they survive Connect
+// replacing the DB's hooks.
+func (h *hooks) inheritRewriteHooks(prev *hooks) {
+	if prev == nil || prev == h {
+		return
+	}
+	prev.mu.RLock()
+	inherited := append([]RewriteHook(nil), prev.rewrite...)
+	prev.mu.RUnlock()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.rewrite = append(inherited, h.rewrite...)
+}
+
+// snapshot returns the hooks in list as they are now. The execute methods
+// run hooks on this copy after releasing h.mu, so a hook may run statements
+// (which take h.mu again) while another goroutine registers hooks, without
+// deadlocking on the waiting writer.
+func (h *hooks) snapshot(list *[]HookFunc) []HookFunc {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
+	return append([]HookFunc(nil), *list...)
+}
 
-	for _, hook := range h.afterOperation {
+// runHooks calls each hook in order and stops at the first error.
+func runHooks(ctx context.Context, hooks []HookFunc, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error {
+	for _, hook := range hooks {
 		if err := hook(ctx, sql, args, tag, operationErr); err != nil {
 			return err
 		}
@@ -116,40 +257,57 @@ func (h *hooks) executeAfterOperation(ctx context.Context, sql string, args []in
 	return nil
 }
 
-func (h *hooks) executeBeforeTransaction(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error {
+// executeRewrite passes sql and args through every rewrite hook in order.
+func (h *hooks) executeRewrite(ctx context.Context, sql string, args []interface{}) (string, []interface{}, error) {
 	h.mu.RLock()
-	defer h.mu.RUnlock()
+	rewrite := append([]RewriteHook(nil), h.rewrite...)
+	h.mu.RUnlock()
 
-	for _, hook := range h.beforeTransaction {
-		if err := hook(ctx, sql, args, tag, operationErr); err != nil {
-			return err
+	for _, hook := range rewrite {
+		var err error
+		if sql, args, err = hook(ctx, sql, args); err != nil {
+			return "", nil, fmt.Errorf("rewrite hook failed: %w", err)
 		}
 	}
-	return nil
+	return sql, args, nil
 }
 
-func (h *hooks) executeAfterTransaction(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error {
+func (h *hooks) executeBeforeOperation(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error {
+	return runHooks(ctx, h.snapshot(&h.beforeOperation), sql, args, tag, operationErr)
+}
+
+func (h *hooks) executeAfterOperation(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error {
 	h.mu.RLock()
-	defer h.mu.RUnlock()
+	onError := h.onOperationError
+	h.mu.RUnlock()
 
-	for _, hook := range h.afterTransaction {
-		if err := hook(ctx, sql, args, tag, operationErr); err != nil {
-			return err
-		}
+	if operationErr != nil && onError != nil {
+		onError(operationErr)
 	}
-	return nil
+	return runHooks(ctx, h.snapshot(&h.afterOperation), sql, args, tag, operationErr)
 }
 
-func (h *hooks) executeOnShutdown(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error {
+func (h *hooks) executeBeforeTransaction(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error {
+	return runHooks(ctx, h.snapshot(&h.beforeTransaction), sql, args, tag, operationErr)
+}
+
+func (h *hooks) executeAfterTransaction(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error {
 	h.mu.RLock()
-	defer h.mu.RUnlock()
+	onError := h.onOperationError
+	h.mu.RUnlock()
 
-	for _, hook := range h.onShutdown {
-		if err := hook(ctx, sql, args, tag, operationErr); err != nil {
-			return err
-		}
+	if operationErr != nil && onError != nil {
+		onError(operationErr)
 	}
-	return nil
+	return runHooks(ctx, h.snapshot(&h.afterTransaction), sql, args, tag, operationErr)
+}
+
+func (h *hooks) executeAfterCommitSuccess(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error {
+	return runHooks(ctx, h.snapshot(&h.afterCommitSuccess), sql, args, tag, operationErr)
+}
+
+func (h *hooks) executeOnShutdown(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error {
+	return runHooks(ctx, h.snapshot(&h.onShutdown), sql, args, tag, operationErr)
 }
 
 // connectionHooks manages connection lifecycle hooks.
@@ -201,6 +359,25 @@ func (h *connectionHooks) addOnRelease(fn func(*pgx.Conn)) {
 	h.onRelease = append(h.onRelease, fn)
 }
 
+// count returns the number of connection hooks of the given kind: "OnConnect",
+// "OnDisconnect", "OnAcquire" or "OnRelease". Unknown kinds count as 0.
+func (h *connectionHooks) count(kind string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	switch kind {
+	case "OnConnect":
+		return len(h.onConnect)
+	case "OnDisconnect":
+		return len(h.onDisconnect)
+	case "OnAcquire":
+		return len(h.onAcquire)
+	case "OnRelease":
+		return len(h.onRelease)
+	}
+	return 0
+}
+
 // executeOnConnect executes all OnConnect callbacks
 func (h *connectionHooks) executeOnConnect(conn *pgx.Conn) error {
 	h.mu.RLock()
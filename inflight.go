@@ -0,0 +1,104 @@
+package pgxkit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// opTracker is a sync.WaitGroup that also knows how many operations are
+// outstanding, so Shutdown can report what it gave up waiting for. When
+// disabled, Add and Done are no-ops.
+type opTracker struct {
+	wg       sync.WaitGroup
+	n        atomic.Int64
+	disabled bool
+}
+
+func (o *opTracker) Add(delta int) {
+	if o.disabled {
+		return
+	}
+	o.n.Add(int64(delta))
+	o.wg.Add(delta)
+}
+
+func (o *opTracker) Done() {
+	if o.disabled {
+		return
+	}
+	o.n.Add(-1)
+	o.wg.Done()
+}
+
+func (o *opTracker) Wait() {
+	o.wg.Wait()
+}
+
+func (o *opTracker) count() int {
+	return int(o.n.Load())
+}
+
+// InFlight returns the number of operations currently running: queries and
+// execs in progress plus open transactions. Useful for tuning the Shutdown
+// drain timeout and for exposing as a metric. It is always 0 under
+// WithoutGracefulShutdownTracking.
+func (db *DB) InFlight() int {
+	return db.activeOps.count()
+}
+
+// WithoutGracefulShutdownTracking skips the in-flight operation bookkeeping
+// that every query, exec, batch and transaction otherwise does. It is only
+// worth it on extreme hot paths, for services that never drain on shutdown.
+//
+// Warning: with tracking disabled, Shutdown does not wait for in-flight
+// operations. It closes the pools immediately, so queries and transactions
+// still running fail, and InFlight always reports 0.
+func WithoutGracefulShutdownTracking() ConnectOption {
+	return func(c *connectConfig) {
+		c.disableOpTracking = true
+	}
+}
+
+// ShutdownTimeoutError is returned by Shutdown when its context ended before
+// every in-flight operation finished. Shutdown still runs the OnShutdown
+// hooks and closes the pools; InFlight is how many operations were
+// outstanding at the deadline.
+type ShutdownTimeoutError struct {
+	InFlight int
+}
+
+func (e *ShutdownTimeoutError) Error() string {
+	return fmt.Sprintf("shutdown deadline reached with %d operations still in flight", e.InFlight)
+}
+
+// ShutdownSummary describes how Shutdown's drain went. It is passed to the
+// OnShutdown hooks through their context; read it with
+// ShutdownSummaryFromContext.
+type ShutdownSummary struct {
+	// TimedOut reports whether Shutdown's context ended with operations
+	// still in flight.
+	TimedOut bool
+	// InFlight is how many operations were outstanding at the deadline, or
+	// 0 when the drain completed.
+	InFlight int
+}
+
+type shutdownSummaryKey struct{}
+
+// ShutdownSummaryFromContext returns the drain summary Shutdown attaches to
+// the context of OnShutdown hooks, so they can log or emit a final metric:
+//
+//	pgxkit.WithOnShutdown(func(ctx context.Context, _ string, _ []interface{}, _ pgconn.CommandTag, _ error) error {
+//	    if s, ok := pgxkit.ShutdownSummaryFromContext(ctx); ok && s.TimedOut {
+//	        log.Printf("shutdown timed out with %d operations in flight", s.InFlight)
+//	    }
+//	    return nil
+//	})
+//
+// ok is false for any other context.
+func ShutdownSummaryFromContext(ctx context.Context) (summary ShutdownSummary, ok bool) {
+	summary, ok = ctx.Value(shutdownSummaryKey{}).(ShutdownSummary)
+	return summary, ok
+}
@@ -0,0 +1,191 @@
+package pgxkit
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// InsertOption configures InsertStruct.
+type InsertOption func(*insertConfig)
+
+type insertConfig struct {
+	omitZero  bool
+	returning []string
+	dest      []any
+}
+
+// WithOmitZero leaves zero-valued fields out of the INSERT, so their columns
+// get the table's defaults (a serial id, created_at DEFAULT now(), ...).
+func WithOmitZero() InsertOption {
+	return func(c *insertConfig) {
+		c.omitZero = true
+	}
+}
+
+// WithReturning adds RETURNING columns to the INSERT and scans the returned
+// row into dest, one destination per column:
+//
+//	_, err := pgxkit.InsertStruct(ctx, db, "users", &u, pgxkit.WithOmitZero(),
+//	    pgxkit.WithReturning([]string{"id", "created_at"}, &u.ID, &u.CreatedAt))
+func WithReturning(columns []string, dest ...any) InsertOption {
+	return func(c *insertConfig) {
+		c.returning = columns
+		c.dest = dest
+	}
+}
+
+// InsertStruct inserts v, a struct or pointer to a struct, into table. Each
+// field tagged `db:"column"` becomes a column, bound as a positional
+// parameter:
+//
+//	type User struct {
+//	    ID    int64  `db:"id"`
+//	    Email string `db:"email"`
+//	    Name  string `db:"name"`
+//	    cache string // untagged fields are ignored
+//	}
+//	tag, err := pgxkit.InsertStruct(ctx, db, "users", u)
+//	// INSERT INTO "users" ("id", "email", "name") VALUES ($1, $2, $3)
+//
+// Fields tagged `db:"-"` and untagged fields are skipped, and the fields of
+// untagged embedded structs are included. The table may be
+// schema-qualified; table and column names (including RETURNING columns)
+// must be plain identifiers. Like unquoted names in SQL, they are folded to
+// lower case before being quoted, so `db:"userId"` names the column userid,
+// just as the table "Users" names users. If no columns remain, as when
+// WithOmitZero drops every field, the row is inserted with DEFAULT VALUES.
+// It runs through Exec, or Query with WithReturning, so hooks and timeouts
+// apply as usual.
+func InsertStruct(ctx context.Context, db *DB, table string, v any, opts ...InsertOption) (pgconn.CommandTag, error) {
+	cfg := &insertConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	sql, args, err := insertSQL(table, v, cfg)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	if len(cfg.returning) == 0 {
+		return db.Exec(ctx, sql, args...)
+	}
+
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return pgconn.CommandTag{}, err
+		}
+		return pgconn.CommandTag{}, pgx.ErrNoRows
+	}
+	if err := rows.Scan(cfg.dest...); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return rows.CommandTag(), nil
+}
+
+// insertSQL builds the INSERT statement and its arguments for InsertStruct.
+func insertSQL(table string, v any, cfg *insertConfig) (string, []any, error) {
+	ident, err := parseTableName(table)
+	if err != nil {
+		return "", nil, fmt.Errorf("insert: %w", err)
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return "", nil, fmt.Errorf("insert: nil %T", v)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return "", nil, fmt.Errorf("insert: expected a struct, got %T", v)
+	}
+	if len(cfg.returning) != len(cfg.dest) {
+		return "", nil, fmt.Errorf("insert: %d RETURNING columns but %d destinations", len(cfg.returning), len(cfg.dest))
+	}
+
+	var columns []string
+	var args []any
+	if err := collectInsertColumns(rv, cfg.omitZero, &columns, &args); err != nil {
+		return "", nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("INSERT INTO ")
+	b.WriteString(ident.Sanitize())
+	if len(columns) == 0 {
+		b.WriteString(" DEFAULT VALUES")
+	} else {
+		b.WriteString(" (")
+		b.WriteString(strings.Join(columns, ", "))
+		b.WriteString(") VALUES (")
+		for i := range columns {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString("$" + strconv.Itoa(i+1))
+		}
+		b.WriteString(")")
+	}
+	if len(cfg.returning) > 0 {
+		quoted := make([]string, len(cfg.returning))
+		for i, col := range cfg.returning {
+			if !identifierPattern.MatchString(col) {
+				return "", nil, fmt.Errorf("insert: invalid returning column %q", col)
+			}
+			quoted[i] = quoteColumn(col)
+		}
+		b.WriteString(" RETURNING ")
+		b.WriteString(strings.Join(quoted, ", "))
+	}
+	return b.String(), args, nil
+}
+
+// collectInsertColumns appends the quoted column and value of each db-tagged
+// field of rv, descending into untagged embedded structs.
+func collectInsertColumns(rv reflect.Value, omitZero bool, columns *[]string, args *[]any) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag, tagged := field.Tag.Lookup("db")
+		if !tagged {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if err := collectInsertColumns(rv.Field(i), omitZero, columns, args); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+		value := rv.Field(i)
+		if omitZero && value.IsZero() {
+			continue
+		}
+		if !identifierPattern.MatchString(tag) {
+			return fmt.Errorf("insert: invalid column %q on field %s", tag, field.Name)
+		}
+		*columns = append(*columns, quoteColumn(tag))
+		*args = append(*args, value.Interface())
+	}
+	return nil
+}
+
+// quoteColumn folds a plain column name to lower case, matching
+// parseTableName, and quotes it.
+func quoteColumn(name string) string {
+	return pgx.Identifier{strings.ToLower(name)}.Sanitize()
+}
@@ -0,0 +1,146 @@
+package pgxkit
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type insertAudit struct {
+	CreatedBy string `db:"created_by"`
+}
+
+type insertUser struct {
+	ID    int64  `db:"id"`
+	Email string `db:"email"`
+	Name  string `db:"name"`
+	Notes string `db:"-"`
+	cache string
+	insertAudit
+}
+
+func TestInsertSQL(t *testing.T) {
+	u := insertUser{Email: "ada@example.com", Name: "Ada", Notes: "skip", cache: "skip", insertAudit: insertAudit{CreatedBy: "admin"}}
+
+	sql, args, err := insertSQL("users", u, &insertConfig{})
+	if err != nil {
+		t.Fatalf("insertSQL failed: %v", err)
+	}
+	want := `INSERT INTO "users" ("id", "email", "name", "created_by") VALUES ($1, $2, $3, $4)`
+	if sql != want {
+		t.Errorf("expected %s, got %s", want, sql)
+	}
+	if wantArgs := []any{int64(0), "ada@example.com", "Ada", "admin"}; !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("expected args %v, got %v", wantArgs, args)
+	}
+
+	var id int64
+	cfg := &insertConfig{}
+	WithOmitZero()(cfg)
+	WithReturning([]string{"id"}, &id)(cfg)
+	sql, args, err = insertSQL("app.users", &u, cfg)
+	if err != nil {
+		t.Fatalf("insertSQL failed: %v", err)
+	}
+	want = `INSERT INTO "app"."users" ("email", "name", "created_by") VALUES ($1, $2, $3) RETURNING "id"`
+	if sql != want {
+		t.Errorf("expected %s, got %s", want, sql)
+	}
+	if len(args) != 3 {
+		t.Errorf("expected 3 args with the zero id omitted, got %v", args)
+	}
+
+	sql, args, err = insertSQL("users", insertUser{}, cfg)
+	if err != nil {
+		t.Fatalf("insertSQL failed: %v", err)
+	}
+	if want := `INSERT INTO "users" DEFAULT VALUES RETURNING "id"`; sql != want || len(args) != 0 {
+		t.Errorf("expected %s with no args, got %s %v", want, sql, args)
+	}
+}
+
+func TestInsertSQLFoldsCase(t *testing.T) {
+	type mixedCase struct {
+		UserID int64  `db:"userId"`
+		Email  string `db:"Email"`
+	}
+	var id int64
+	cfg := &insertConfig{}
+	WithReturning([]string{"UserID"}, &id)(cfg)
+	sql, _, err := insertSQL("App.Users", mixedCase{UserID: 1, Email: "ada@example.com"}, cfg)
+	if err != nil {
+		t.Fatalf("insertSQL failed: %v", err)
+	}
+	want := `INSERT INTO "app"."users" ("userid", "email") VALUES ($1, $2) RETURNING "userid"`
+	if sql != want {
+		t.Errorf("expected %s, got %s", want, sql)
+	}
+}
+
+func TestInsertSQLValidation(t *testing.T) {
+	type badColumn struct {
+		Name string `db:"name; DROP TABLE users"`
+	}
+	var id int64
+	tests := []struct {
+		name  string
+		table string
+		v     any
+		cfg   *insertConfig
+	}{
+		{"not a struct", "users", 42, &insertConfig{}},
+		{"nil pointer", "users", (*insertUser)(nil), &insertConfig{}},
+		{"bad table", "users; --", insertUser{}, &insertConfig{}},
+		{"bad column", "users", badColumn{Name: "x"}, &insertConfig{}},
+		{"bad returning", "users", insertUser{}, &insertConfig{returning: []string{"id)"}, dest: []any{&id}}},
+		{"returning mismatch", "users", insertUser{}, &insertConfig{returning: []string{"id", "name"}, dest: []any{&id}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := insertSQL(tt.table, tt.v, tt.cfg); err == nil || !strings.HasPrefix(err.Error(), "insert:") {
+				t.Errorf("expected an insert error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestInsertStructIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+	db := NewDB()
+	db.writePool = pool
+	db.readPool = pool
+	ctx := context.Background()
+
+	if _, err := db.Exec(ctx, `CREATE TABLE IF NOT EXISTS insert_struct_users (
+		id bigserial PRIMARY KEY, email text NOT NULL, name text NOT NULL,
+		created_by text NOT NULL DEFAULT 'system', created_at timestamptz NOT NULL DEFAULT now())`); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	t.Cleanup(func() { _, _ = db.Exec(context.Background(), "DROP TABLE IF EXISTS insert_struct_users") })
+
+	u := insertUser{Email: "ada@example.com", Name: "Ada"}
+	var createdAt time.Time
+	tag, err := InsertStruct(ctx, db, "insert_struct_users", &u, WithOmitZero(),
+		WithReturning([]string{"id", "created_at"}, &u.ID, &createdAt))
+	if err != nil {
+		t.Fatalf("InsertStruct failed: %v", err)
+	}
+	if !tag.Insert() || tag.RowsAffected() != 1 {
+		t.Errorf("expected INSERT 0 1, got %s", tag)
+	}
+	if u.ID == 0 || createdAt.IsZero() {
+		t.Errorf("expected RETURNING to fill id and created_at, got %d, %v", u.ID, createdAt)
+	}
+
+	var email, name, createdBy string
+	err = db.QueryRow(ctx, "SELECT email, name, created_by FROM insert_struct_users WHERE id = $1", u.ID).
+		Scan(&email, &name, &createdBy)
+	if err != nil {
+		t.Fatalf("select failed: %v", err)
+	}
+	if email != u.Email || name != u.Name || createdBy != "system" {
+		t.Errorf("round trip mismatch: got %q, %q, %q", email, name, createdBy)
+	}
+}
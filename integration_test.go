@@ -3,6 +3,7 @@ package pgxkit
 import (
 	"context"
 	"errors"
+	"os"
 	"testing"
 	"time"
 
@@ -111,6 +112,59 @@ func TestTransactionCommitFlow(t *testing.T) {
 	}
 }
 
+func TestRetryOperationRerunsTransactionOnSerializationFailure(t *testing.T) {
+	pool := requireTestPool(t)
+	ctx := context.Background()
+
+	db := NewDB()
+	db.readPool = pool
+	db.writePool = pool
+
+	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS tx_test_retry (id SERIAL PRIMARY KEY, attempt INT)`)
+	if err != nil {
+		t.Fatalf("Failed to create test table: %v", err)
+	}
+	defer CleanupTestData("DROP TABLE IF EXISTS tx_test_retry")
+
+	attempts := 0
+	err = RetryOperation(ctx, func(ctx context.Context) error {
+		attempts++
+		tx, err := db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if _, err := tx.Exec(ctx, `INSERT INTO tx_test_retry (attempt) VALUES ($1)`, attempts); err != nil {
+			return err
+		}
+		if attempts == 1 {
+			// Simulate the server aborting the transaction under contention.
+			_, err := tx.Exec(ctx, `DO $$ BEGIN RAISE EXCEPTION 'forced' USING ERRCODE = 'serialization_failure'; END $$`)
+			return err
+		}
+		return tx.Commit(ctx)
+	}, WithMaxRetries(3), WithBaseDelay(time.Millisecond))
+	if err != nil {
+		t.Fatalf("RetryOperation failed: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected the transaction to run twice, got %d", attempts)
+	}
+
+	rows, err := pool.Query(ctx, `SELECT attempt FROM tx_test_retry`)
+	if err != nil {
+		t.Fatalf("Failed to read committed rows: %v", err)
+	}
+	committed, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		t.Fatalf("Failed to collect rows: %v", err)
+	}
+	if len(committed) != 1 || committed[0] != 2 {
+		t.Errorf("expected only the retried attempt to commit, got %v", committed)
+	}
+}
+
 func TestTransactionRollbackOnError(t *testing.T) {
 	pool := requireTestPool(t)
 	ctx := context.Background()
@@ -362,6 +416,112 @@ func TestTxEscapeHatch(t *testing.T) {
 	}
 }
 
+func TestIdleInTransactionTimeoutAbortsTransaction(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+	ctx := context.Background()
+
+	db := NewDB()
+	if err := db.Connect(ctx, dsn, WithMaxConns(2), WithIdleInTransactionTimeout(100*time.Millisecond)); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer db.Shutdown(ctx)
+
+	var setting string
+	if err := db.QueryRow(ctx, "SHOW idle_in_transaction_session_timeout").Scan(&setting); err != nil {
+		t.Fatalf("SHOW failed: %v", err)
+	}
+	if setting != "100ms" {
+		t.Errorf("expected idle_in_transaction_session_timeout=100ms, got %q", setting)
+	}
+
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("first Exec failed: %v", err)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+
+	if _, err := tx.Exec(ctx, "SELECT 1"); err == nil {
+		t.Error("expected the server to abort the idle transaction")
+	}
+}
+
+func TestSessionRuntimeParamsApplied(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+	ctx := context.Background()
+
+	db := NewDB()
+	err := db.Connect(ctx, dsn,
+		WithMaxConns(2),
+		WithApplicationName("pgxkit-test"),
+		WithStatementTimeout(1500*time.Millisecond),
+		WithSearchPath("pg_catalog, public"),
+	)
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer db.Shutdown(ctx)
+
+	for param, want := range map[string]string{
+		"application_name":  "pgxkit-test",
+		"statement_timeout": "1500ms",
+		"search_path":       "pg_catalog, public",
+	} {
+		var got string
+		if err := db.QueryRow(ctx, "SHOW "+param).Scan(&got); err != nil {
+			t.Fatalf("SHOW %s failed: %v", param, err)
+		}
+		if got != want {
+			t.Errorf("%s = %q, want %q", param, got, want)
+		}
+	}
+}
+
+func TestSimpleProtocolParameterizedQuery(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+	ctx := context.Background()
+
+	db := NewDB()
+	if err := db.Connect(ctx, dsn, WithMaxConns(2), WithSimpleProtocol()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer db.Shutdown(ctx)
+
+	// Arguments are interpolated client-side, so quoting must survive.
+	var text string
+	var n int
+	err := db.QueryRow(ctx, "SELECT $1::text, $2::int + 1", "it's; DROP TABLE x --", 41).Scan(&text, &n)
+	if err != nil {
+		t.Fatalf("QueryRow failed: %v", err)
+	}
+	if text != "it's; DROP TABLE x --" || n != 42 {
+		t.Errorf("got (%q, %d), want (%q, 42)", text, n, "it's; DROP TABLE x --")
+	}
+
+	// No named prepared statements are created under simple protocol.
+	var prepared int
+	if err := db.QueryRow(ctx, "SELECT count(*) FROM pg_prepared_statements").Scan(&prepared); err != nil {
+		t.Fatalf("count prepared statements: %v", err)
+	}
+	if prepared != 0 {
+		t.Errorf("expected no prepared statements, got %d", prepared)
+	}
+}
+
 func TestBeforeTransactionHookFailure(t *testing.T) {
 	pool := requireTestPool(t)
 	ctx := context.Background()
@@ -0,0 +1,129 @@
+package pgxkit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// LoggingConfig selects what WithLogging logs. The zero value logs nothing.
+type LoggingConfig struct {
+	// Logger receives the records; nil means slog.Default().
+	Logger *slog.Logger
+	// SlowQueryThreshold logs, at Warn level, every Query, QueryRow, Exec
+	// and batch query that takes longer than this. Zero disables it.
+	SlowQueryThreshold time.Duration
+	// LogConnections logs pooled connections being opened and closed, at
+	// Info level.
+	LogConnections bool
+	// LogTransactions logs transactions beginning and ending at Debug level,
+	// and failed begins, commits and rollbacks at Warn level.
+	LogTransactions bool
+}
+
+type slowQueryStartKey struct{}
+
+// WithLogging installs hooks that log slow queries, transactions and
+// connections to cfg.Logger, as selected by cfg. Like the rollback log, it
+// never logs argument values, only how many there were.
+//
+// Example:
+//
+//	err := db.Connect(ctx, dsn, pgxkit.WithLogging(pgxkit.LoggingConfig{
+//	    Logger:             logger,
+//	    SlowQueryThreshold: 200 * time.Millisecond,
+//	    LogTransactions:    true,
+//	}))
+func WithLogging(cfg LoggingConfig) ConnectOption {
+	return func(c *connectConfig) {
+		logger := cfg.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		if cfg.SlowQueryThreshold > 0 {
+			c.hooks.addHook(BeforeOperation, func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+				HookStore(ctx).Store(slowQueryStartKey{}, time.Now())
+				return nil
+			})
+			c.hooks.addHook(AfterOperation, slowQueryLogHook(logger, cfg.SlowQueryThreshold))
+		}
+		if cfg.LogTransactions {
+			c.hooks.addHook(BeforeTransaction, func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+				logger.DebugContext(ctx, "transaction begin")
+				return nil
+			})
+			c.hooks.addHook(AfterTransaction, transactionLogHook(logger))
+		}
+		if cfg.LogConnections {
+			c.hooks.connectionHooks.addOnConnect(func(conn *pgx.Conn) error {
+				logger.Info("database connection opened", connAttrs(conn)...)
+				return nil
+			})
+			c.hooks.connectionHooks.addOnDisconnect(func(conn *pgx.Conn) {
+				logger.Info("database connection closed", connAttrs(conn)...)
+			})
+		}
+	}
+}
+
+// slowQueryLogHook logs operations that took longer than threshold since the
+// start time recorded in their HookStore.
+func slowQueryLogHook(logger *slog.Logger, threshold time.Duration) HookFunc {
+	return func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+		start, ok := HookStore(ctx).Load(slowQueryStartKey{})
+		if !ok {
+			return nil
+		}
+		duration := time.Since(start.(time.Time))
+		if duration <= threshold {
+			return nil
+		}
+		attrs := []any{"sql", sql, "args", len(args), "duration", duration}
+		if op, ok := OpFromContext(ctx); ok {
+			attrs = append(attrs, "op", op)
+		}
+		if err != nil {
+			attrs = append(attrs, "error", err)
+		}
+		logger.WarnContext(ctx, "slow query", attrs...)
+		return nil
+	}
+}
+
+// transactionLogHook logs how a transaction ended. A failed BeginTx has no
+// Tx in ctx and is logged as such.
+func transactionLogHook(logger *slog.Logger) HookFunc {
+	return func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+		tx, ok := txFromContext(ctx)
+		if !ok {
+			if err != nil {
+				logger.WarnContext(ctx, "transaction begin failed", "error", err)
+			}
+			return nil
+		}
+		attrs := []any{"duration", time.Since(tx.startedAt), "statements", tx.Info().Statements}
+		switch {
+		case err != nil && sql == TxCommit:
+			logger.WarnContext(ctx, "transaction commit failed", append(attrs, "error", err)...)
+		case err != nil:
+			logger.WarnContext(ctx, "transaction rollback failed", append(attrs, "error", err)...)
+		case sql == TxCommit:
+			logger.DebugContext(ctx, "transaction committed", attrs...)
+		default:
+			logger.DebugContext(ctx, "transaction rolled back", attrs...)
+		}
+		return nil
+	}
+}
+
+// connAttrs describes conn for the connection log. conn may be nil when a
+// hook is called directly.
+func connAttrs(conn *pgx.Conn) []any {
+	if conn == nil || conn.PgConn() == nil {
+		return nil
+	}
+	return []any{"pid", conn.PgConn().PID(), "host", conn.Config().Host}
+}
@@ -0,0 +1,109 @@
+package pgxkit
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// loggingDB returns a DB with WithLogging(cfg) installed, logging at Debug
+// level into the returned buffer.
+func loggingDB(cfg LoggingConfig) (*DB, *bytes.Buffer) {
+	var buf bytes.Buffer
+	cfg.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := newConnectConfig()
+	WithLogging(cfg)(c)
+	db := NewDB()
+	db.hooks = c.hooks
+	return db, &buf
+}
+
+func TestWithLoggingSlowQuery(t *testing.T) {
+	db, buf := loggingDB(LoggingConfig{SlowQueryThreshold: 5 * time.Millisecond})
+	delay := 20 * time.Millisecond
+	tx := &Tx{tx: &mockTx{execFunc: func(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+		time.Sleep(delay)
+		return pgconn.CommandTag{}, nil
+	}}, db: db}
+
+	if _, err := tx.Exec(WithOp(context.Background(), "ArchiveJobs"), "UPDATE jobs SET archived = true WHERE token = $1", "s3cr3t"); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"slow query", "UPDATE jobs", "args=1", "op=ArchiveJobs"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the log, got %q", want, out)
+		}
+	}
+	if strings.Contains(out, "s3cr3t") {
+		t.Errorf("argument values should not be logged, got %q", out)
+	}
+
+	buf.Reset()
+	delay = 0
+	if _, err := tx.Exec(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("fast queries should not be logged, got %q", buf.String())
+	}
+}
+
+func TestWithLoggingTransactions(t *testing.T) {
+	db, buf := loggingDB(LoggingConfig{LogTransactions: true})
+	ctx, release := withConnAffinity(context.Background(), db, &fakeAffinityPool{})
+	defer release()
+
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	tx, err = db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"transaction begin", "transaction committed", "transaction rolled back"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the log, got %q", want, out)
+		}
+	}
+}
+
+func TestWithLoggingConnections(t *testing.T) {
+	db, buf := loggingDB(LoggingConfig{LogConnections: true})
+	if err := db.hooks.connectionHooks.executeOnConnect(nil); err != nil {
+		t.Fatalf("OnConnect failed: %v", err)
+	}
+	db.hooks.connectionHooks.executeOnDisconnect(nil)
+
+	out := buf.String()
+	for _, want := range []string{"database connection opened", "database connection closed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the log, got %q", want, out)
+		}
+	}
+}
+
+func TestWithLoggingZeroConfig(t *testing.T) {
+	c := newConnectConfig()
+	WithLogging(LoggingConfig{})(c)
+	for _, hookType := range []HookType{BeforeOperation, AfterOperation, BeforeTransaction, AfterTransaction} {
+		if n := c.hooks.count(hookType); n != 0 {
+			t.Errorf("zero LoggingConfig should install no hooks, got %d for %v", n, hookType)
+		}
+	}
+}
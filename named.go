@@ -0,0 +1,71 @@
+package pgxkit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// rewriteNamed expands @name placeholders into positional $n parameters so the
+// statement can go through the normal hooked execute path. Hooks therefore see
+// the rewritten SQL and a flattened args slice rather than the named map.
+func rewriteNamed(ctx context.Context, sql string, args pgx.NamedArgs) (string, []interface{}, error) {
+	newSQL, newArgs, err := args.RewriteQuery(ctx, nil, sql, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to rewrite named args: %w", err)
+	}
+	return newSQL, newArgs, nil
+}
+
+// QueryNamed executes a query with @name style arguments. Like Query, it uses
+// the write pool unless WithAutoReadRouting sends it to the read pool. The SQL
+// is rewritten to positional parameters before hooks run, so
+// BeforeOperation/AfterOperation receive the rewritten SQL and flattened args.
+//
+// Example:
+//
+//	rows, err := db.QueryNamed(ctx, "SELECT * FROM users WHERE email = @email AND active = @active",
+//	    pgx.NamedArgs{"email": email, "active": true})
+func (db *DB) QueryNamed(ctx context.Context, sql string, args pgx.NamedArgs) (pgx.Rows, error) {
+	newSQL, newArgs, err := rewriteNamed(ctx, sql, args)
+	if err != nil {
+		return nil, err
+	}
+	return db.executeQuery(ctx, func() (*pgxpool.Pool, PoolSelector) { return db.queryTarget(ctx, sql) }, newSQL, newArgs...)
+}
+
+// QueryRowNamed executes a query with @name style arguments that returns a
+// single row. It picks its pool the way QueryRow does.
+//
+// Example:
+//
+//	var id int
+//	err := db.QueryRowNamed(ctx, "SELECT id FROM users WHERE email = @email",
+//	    pgx.NamedArgs{"email": email}).Scan(&id)
+func (db *DB) QueryRowNamed(ctx context.Context, sql string, args pgx.NamedArgs) pgx.Row {
+	newSQL, newArgs, err := rewriteNamed(ctx, sql, args)
+	if err != nil {
+		return &shutdownRow{err: err}
+	}
+	return db.executeQueryRow(ctx, func() (*pgxpool.Pool, PoolSelector) { return db.queryTarget(ctx, sql) }, newSQL, newArgs...)
+}
+
+// ExecNamed executes a statement with @name style arguments using the write pool.
+// This is most useful for wide INSERT/UPDATE statements where positional
+// arguments become hard to follow.
+//
+// Example:
+//
+//	_, err := db.ExecNamed(ctx,
+//	    "INSERT INTO users (name, email, role) VALUES (@name, @email, @role)",
+//	    pgx.NamedArgs{"name": name, "email": email, "role": "admin"})
+func (db *DB) ExecNamed(ctx context.Context, sql string, args pgx.NamedArgs) (pgconn.CommandTag, error) {
+	newSQL, newArgs, err := rewriteNamed(ctx, sql, args)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return db.executeExec(ctx, db.writeTarget, newSQL, newArgs...)
+}
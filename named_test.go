@@ -0,0 +1,99 @@
+package pgxkit
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestRewriteNamed(t *testing.T) {
+	sql, args, err := rewriteNamed(context.Background(),
+		"INSERT INTO users (name, email) VALUES (@name, @email) RETURNING @name",
+		pgx.NamedArgs{"name": "alice", "email": "alice@example.com"})
+	if err != nil {
+		t.Fatalf("rewriteNamed returned unexpected error: %v", err)
+	}
+	if sql != "INSERT INTO users (name, email) VALUES ($1, $2) RETURNING $1" {
+		t.Errorf("unexpected rewritten SQL: %q", sql)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"alice", "alice@example.com"}) {
+		t.Errorf("unexpected flattened args: %v", args)
+	}
+}
+
+func TestNamedMethodsNotConnected(t *testing.T) {
+	db := NewDB()
+	ctx := context.Background()
+	named := pgx.NamedArgs{"id": 1}
+
+	if _, err := db.QueryNamed(ctx, "SELECT @id", named); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("QueryNamed: expected not connected error, got %v", err)
+	}
+	if err := db.QueryRowNamed(ctx, "SELECT @id", named).Scan(new(int)); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("QueryRowNamed: expected not connected error, got %v", err)
+	}
+	if _, err := db.ExecNamed(ctx, "SELECT @id", named); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("ExecNamed: expected not connected error, got %v", err)
+	}
+}
+
+func TestNamedQueriesIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+	ctx := context.Background()
+
+	db := NewDB()
+	db.readPool = pool
+	db.writePool = pool
+
+	var capturedSQL []string
+	var capturedArgs [][]interface{}
+	db.hooks.addHook(BeforeOperation, func(ctx context.Context, sql string, args []interface{}, _ pgconn.CommandTag, _ error) error {
+		capturedSQL = append(capturedSQL, sql)
+		capturedArgs = append(capturedArgs, args)
+		return nil
+	})
+
+	var sum int
+	err := db.QueryRowNamed(ctx, "SELECT @a::int + @b::int", pgx.NamedArgs{"a": 2, "b": 3}).Scan(&sum)
+	if err != nil {
+		t.Fatalf("QueryRowNamed failed: %v", err)
+	}
+	if sum != 5 {
+		t.Errorf("expected 5, got %d", sum)
+	}
+
+	rows, err := db.QueryNamed(ctx, "SELECT generate_series(1, @n::int)", pgx.NamedArgs{"n": 3})
+	if err != nil {
+		t.Fatalf("QueryNamed failed: %v", err)
+	}
+	count := 0
+	for rows.Next() {
+		count++
+	}
+	rows.Close()
+	if count != 3 {
+		t.Errorf("expected 3 rows, got %d", count)
+	}
+
+	tag, err := db.ExecNamed(ctx, "SELECT @v::text", pgx.NamedArgs{"v": "x"})
+	if err != nil {
+		t.Fatalf("ExecNamed failed: %v", err)
+	}
+	if tag.RowsAffected() != 1 {
+		t.Errorf("expected 1 row affected, got %d", tag.RowsAffected())
+	}
+
+	if len(capturedSQL) != 3 {
+		t.Fatalf("expected hook to capture 3 statements, got %d", len(capturedSQL))
+	}
+	if capturedSQL[0] != "SELECT $1::int + $2::int" {
+		t.Errorf("hook should receive rewritten SQL, got %q", capturedSQL[0])
+	}
+	if !reflect.DeepEqual(capturedArgs[0], []interface{}{2, 3}) {
+		t.Errorf("hook should receive flattened args, got %v", capturedArgs[0])
+	}
+}
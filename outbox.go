@@ -0,0 +1,182 @@
+package pgxkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// OutboxSchema creates the table used by Outbox. Run it from your migrations.
+// Rows with a NULL published_at were committed but not (yet) handed to the
+// publisher, e.g. because the process died or Publish returned an error.
+const OutboxSchema = `CREATE TABLE IF NOT EXISTS pgxkit_outbox (
+	id           BIGSERIAL PRIMARY KEY,
+	topic        TEXT NOT NULL,
+	payload      JSONB NOT NULL,
+	created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+	published_at TIMESTAMPTZ
+)`
+
+// OutboxEvent is a message recorded in the outbox.
+type OutboxEvent struct {
+	ID      int64
+	Topic   string
+	Payload json.RawMessage
+}
+
+// Publisher delivers committed outbox events to a message broker.
+type Publisher interface {
+	Publish(ctx context.Context, events []OutboxEvent) error
+}
+
+// PublisherFunc adapts a function to the Publisher interface.
+type PublisherFunc func(ctx context.Context, events []OutboxEvent) error
+
+func (f PublisherFunc) Publish(ctx context.Context, events []OutboxEvent) error {
+	return f(ctx, events)
+}
+
+// Outbox implements the transactional outbox pattern on top of the
+// AfterCommitSuccess hook. Events enqueued in a transaction are written to
+// pgxkit_outbox as part of that transaction and handed to the publisher only
+// once it commits; a rollback or failed commit discards them.
+type Outbox struct {
+	db        *DB
+	publisher Publisher
+	onError   func(ctx context.Context, events []OutboxEvent, err error)
+
+	mu      sync.Mutex
+	pending map[*Tx][]OutboxEvent
+}
+
+// OutboxOption configures NewOutbox.
+type OutboxOption func(*Outbox)
+
+// WithOutboxErrorHandler sets the function told about events that were
+// committed but could not be published or marked published. By then the
+// transaction has committed, so the error is not returned from Commit; the
+// default handler logs it with slog.
+func WithOutboxErrorHandler(fn func(ctx context.Context, events []OutboxEvent, err error)) OutboxOption {
+	return func(o *Outbox) {
+		if fn != nil {
+			o.onError = fn
+		}
+	}
+}
+
+// NewOutbox registers the outbox hooks on db and returns the Outbox.
+// Transactions must be started with db.BeginTx.
+//
+// Like AddConnectionHookLive, it returns an error when db is not connected
+// yet, since Connect would discard the hooks, or is shutting down.
+//
+// Example:
+//
+//	outbox, err := pgxkit.NewOutbox(db, pgxkit.PublisherFunc(func(ctx context.Context, events []pgxkit.OutboxEvent) error {
+//	    return broker.Send(ctx, events)
+//	}))
+//
+//	tx, _ := db.BeginTx(ctx, pgx.TxOptions{})
+//	defer tx.Rollback(ctx)
+//	// ... write business rows via tx ...
+//	_ = outbox.Enqueue(ctx, tx, "order.created", order)
+//	err = tx.Commit(ctx) // publishes only if the commit succeeded
+func NewOutbox(db *DB, publisher Publisher, opts ...OutboxOption) (*Outbox, error) {
+	o := &Outbox{
+		db:        db,
+		publisher: publisher,
+		onError:   logOutboxError,
+		pending:   make(map[*Tx][]OutboxEvent),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.shutdown {
+		return nil, fmt.Errorf("database is shutting down")
+	}
+	if db.writePool == nil {
+		return nil, fmt.Errorf("database is not connected: create the outbox after Connect")
+	}
+	db.hooks.addHook(AfterTransaction, o.afterTransaction)
+	db.hooks.addHook(AfterCommitSuccess, o.afterCommitSuccess)
+	return o, nil
+}
+
+func logOutboxError(ctx context.Context, events []OutboxEvent, err error) {
+	slog.ErrorContext(ctx, "outbox events not published", "events", len(events), "error", err)
+}
+
+// Enqueue marshals payload to JSON and inserts it into the outbox within tx.
+// The event is published after tx commits.
+func (o *Outbox) Enqueue(ctx context.Context, tx *Tx, topic string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox payload: %w", err)
+	}
+
+	event := OutboxEvent{Topic: topic, Payload: data}
+	err = tx.QueryRow(ctx,
+		"INSERT INTO pgxkit_outbox (topic, payload) VALUES ($1, $2) RETURNING id",
+		topic, string(data),
+	).Scan(&event.ID)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue outbox event: %w", err)
+	}
+
+	o.mu.Lock()
+	o.pending[tx] = append(o.pending[tx], event)
+	o.mu.Unlock()
+	return nil
+}
+
+// take removes and returns the events pending for tx.
+func (o *Outbox) take(tx *Tx) []OutboxEvent {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	events := o.pending[tx]
+	delete(o.pending, tx)
+	return events
+}
+
+func (o *Outbox) afterTransaction(ctx context.Context, sql string, _ []interface{}, _ pgconn.CommandTag, operationErr error) error {
+	if sql == TxCommit && operationErr == nil {
+		return nil
+	}
+	if tx, ok := txFromContext(ctx); ok {
+		o.take(tx)
+	}
+	return nil
+}
+
+func (o *Outbox) afterCommitSuccess(ctx context.Context, _ string, _ []interface{}, _ pgconn.CommandTag, _ error) error {
+	tx, ok := txFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	events := o.take(tx)
+	if len(events) == 0 {
+		return nil
+	}
+
+	if err := o.publisher.Publish(ctx, events); err != nil {
+		o.onError(ctx, events, fmt.Errorf("failed to publish outbox events: %w", err))
+		return nil
+	}
+
+	ids := make([]int64, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	// The transaction has committed; don't attribute the UPDATE to it.
+	if _, err := o.db.Exec(withoutTx(ctx), "UPDATE pgxkit_outbox SET published_at = now() WHERE id = ANY($1)", ids); err != nil {
+		o.onError(ctx, events, fmt.Errorf("failed to mark outbox events published: %w", err))
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// outboxTx returns a Tx whose inserts hand back sequential IDs, so Enqueue
+// works without a database.
+func outboxTx(db *DB, commitErr error) *Tx {
+	nextID := int64(0)
+	mock := &mockTx{
+		queryRowFunc: func(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+			return &mockRow{scanFunc: func(dest ...interface{}) error {
+				nextID++
+				*dest[0].(*int64) = nextID
+				return nil
+			}}
+		},
+		commitFunc: func(ctx context.Context) error { return commitErr },
+	}
+	db.activeOps.Add(1)
+	return &Tx{tx: mock, db: db}
+}
+
+// outboxDB returns a DB connected to a pool that never dials, since
+// NewOutbox requires Connect.
+func outboxDB(t *testing.T) *DB {
+	t.Helper()
+	db := NewDB()
+	if err := db.Connect(context.Background(), lazyDSN); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Shutdown(context.Background()) })
+	return db
+}
+
+// newTestOutbox is NewOutbox that fails the test on error.
+func newTestOutbox(t *testing.T, db *DB, publisher Publisher, opts ...OutboxOption) *Outbox {
+	t.Helper()
+	outbox, err := NewOutbox(db, publisher, opts...)
+	if err != nil {
+		t.Fatalf("NewOutbox failed: %v", err)
+	}
+	return outbox
+}
+
+type recordingPublisher struct {
+	published []OutboxEvent
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, events []OutboxEvent) error {
+	p.published = append(p.published, events...)
+	return nil
+}
+
+func TestOutboxRequiresConnection(t *testing.T) {
+	if _, err := NewOutbox(NewDB(), &recordingPublisher{}); err == nil {
+		t.Error("expected an error creating an outbox before Connect")
+	}
+
+	db := outboxDB(t)
+	if err := db.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if _, err := NewOutbox(db, &recordingPublisher{}); err == nil {
+		t.Error("expected an error creating an outbox after Shutdown")
+	}
+}
+
+func TestOutboxPublishesOnCommit(t *testing.T) {
+	db := outboxDB(t)
+	pub := &recordingPublisher{}
+	var handled error
+	outbox := newTestOutbox(t, db, pub, WithOutboxErrorHandler(func(ctx context.Context, events []OutboxEvent, err error) {
+		handled = err
+	}))
+	ctx := context.Background()
+
+	tx := outboxTx(db, nil)
+	if err := outbox.Enqueue(ctx, tx, "order.created", map[string]int{"id": 7}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if len(pub.published) != 0 {
+		t.Fatal("events must not be published before commit")
+	}
+
+	// The pool never dials, so marking rows published fails after delivery.
+	// The commit itself succeeded, so that is reported to the handler.
+	if err := tx.Commit(ctx); err != nil {
+		t.Errorf("Commit failed: %v", err)
+	}
+	if handled == nil || !strings.Contains(handled.Error(), "mark outbox events published") {
+		t.Errorf("expected the handler to get the mark-published error, got %v", handled)
+	}
+	if len(pub.published) != 1 {
+		t.Fatalf("expected 1 published event, got %d", len(pub.published))
+	}
+	if ev := pub.published[0]; ev.ID != 1 || ev.Topic != "order.created" || string(ev.Payload) != `{"id":7}` {
+		t.Errorf("unexpected published event: %+v", ev)
+	}
+	if len(outbox.pending) != 0 {
+		t.Error("pending events should be cleared after commit")
+	}
+}
+
+func TestOutboxDiscardsOnRollback(t *testing.T) {
+	db := outboxDB(t)
+	pub := &recordingPublisher{}
+	outbox := newTestOutbox(t, db, pub)
+	ctx := context.Background()
+
+	tx := outboxTx(db, nil)
+	if err := outbox.Enqueue(ctx, tx, "order.created", 1); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if len(pub.published) != 0 {
+		t.Errorf("rolled back events must not be published, got %v", pub.published)
+	}
+	if len(outbox.pending) != 0 {
+		t.Error("pending events should be discarded on rollback")
+	}
+}
+
+func TestOutboxDiscardsOnCommitError(t *testing.T) {
+	db := outboxDB(t)
+	pub := &recordingPublisher{}
+	outbox := newTestOutbox(t, db, pub)
+	ctx := context.Background()
+	commitErr := errors.New("commit failed")
+
+	tx := outboxTx(db, commitErr)
+	if err := outbox.Enqueue(ctx, tx, "order.created", 1); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if err := tx.Commit(ctx); !errors.Is(err, commitErr) {
+		t.Errorf("expected commit error, got %v", err)
+	}
+	if len(pub.published) != 0 {
+		t.Errorf("events from a failed commit must not be published, got %v", pub.published)
+	}
+	if len(outbox.pending) != 0 {
+		t.Error("pending events should be discarded on commit error")
+	}
+}
+
+func TestOutboxPublishError(t *testing.T) {
+	db := outboxDB(t)
+	publishErr := errors.New("broker down")
+	var handled error
+	var unpublished []OutboxEvent
+	outbox := newTestOutbox(t, db, PublisherFunc(func(ctx context.Context, events []OutboxEvent) error {
+		return publishErr
+	}), WithOutboxErrorHandler(func(ctx context.Context, events []OutboxEvent, err error) {
+		handled, unpublished = err, events
+	}))
+	ctx := context.Background()
+
+	tx := outboxTx(db, nil)
+	if err := outbox.Enqueue(ctx, tx, "order.created", 1); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Errorf("Commit must not fail once the transaction committed, got %v", err)
+	}
+	if !errors.Is(handled, publishErr) || len(unpublished) != 1 {
+		t.Errorf("expected the handler to get the publish error and event, got %v, %v", handled, unpublished)
+	}
+}
+
+// Registering a hook while a commit is publishing must not deadlock the
+// commit, and the mark-published UPDATE must not be attributed to the
+// committed transaction.
+func TestOutboxCommitWithConcurrentRewriteHook(t *testing.T) {
+	db := NewDB()
+	if err := db.Connect(context.Background(), lazyDSN); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	var sawTx atomic.Bool
+	added := make(chan struct{})
+	outbox := newTestOutbox(t, db, PublisherFunc(func(ctx context.Context, events []OutboxEvent) error {
+		go func() {
+			_ = db.AddRewriteHook(func(ctx context.Context, sql string, args []interface{}) (string, []interface{}, error) {
+				if _, ok := txFromContext(ctx); ok {
+					sawTx.Store(true)
+				}
+				return sql, args, nil
+			})
+			close(added)
+		}()
+		select {
+		case <-added:
+		case <-time.After(100 * time.Millisecond):
+		}
+		return nil
+	}))
+	ctx := context.Background()
+
+	tx := outboxTx(db, nil)
+	if err := outbox.Enqueue(ctx, tx, "order.created", 1); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- tx.Commit(ctx) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Commit failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		// Shutdown would block on the same deadlock, so leave the DB open.
+		t.Fatal("Commit deadlocked with a concurrent AddRewriteHook")
+	}
+	t.Cleanup(func() { _ = db.Shutdown(context.Background()) })
+	<-added
+	if sawTx.Load() {
+		t.Error("the mark-published UPDATE should not carry the committed transaction")
+	}
+}
+
+func TestOutboxEnqueueMarshalError(t *testing.T) {
+	db := outboxDB(t)
+	outbox := newTestOutbox(t, db, &recordingPublisher{})
+	tx := outboxTx(db, nil)
+	defer tx.Rollback(context.Background())
+
+	if err := outbox.Enqueue(context.Background(), tx, "bad", make(chan int)); err == nil {
+		t.Error("expected marshal error for unsupported payload")
+	}
+}
+
+func TestOutboxIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+	ctx := context.Background()
+
+	if _, err := pool.Exec(ctx, OutboxSchema); err != nil {
+		t.Fatalf("create outbox table: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), "DROP TABLE IF EXISTS pgxkit_outbox")
+	})
+
+	db := NewDB()
+	db.readPool = pool
+	db.writePool = pool
+	pub := &recordingPublisher{}
+	outbox := newTestOutbox(t, db, pub)
+
+	rolledBack, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if err := outbox.Enqueue(ctx, rolledBack, "order.cancelled", map[string]int{"id": 1}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if err := rolledBack.Rollback(ctx); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+
+	committed, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if err := outbox.Enqueue(ctx, committed, "order.created", map[string]int{"id": 2}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if err := committed.Commit(ctx); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	if len(pub.published) != 1 || pub.published[0].Topic != "order.created" {
+		t.Fatalf("expected only the committed event to be published, got %+v", pub.published)
+	}
+
+	var topic string
+	var published bool
+	err = pool.QueryRow(ctx, "SELECT topic, published_at IS NOT NULL FROM pgxkit_outbox").Scan(&topic, &published)
+	if err != nil {
+		t.Fatalf("query outbox: %v", err)
+	}
+	if topic != "order.created" || !published {
+		t.Errorf("expected one published order.created row, got topic=%q published=%v", topic, published)
+	}
+}
@@ -0,0 +1,221 @@
+package pgxkit
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// defaultPageSize is used when PaginateConfig.PageSize is not positive.
+const defaultPageSize = 50
+
+// PaginateConfig describes one page of a keyset-paginated query.
+type PaginateConfig struct {
+	// SQL is the base query, without ORDER BY or LIMIT. It is wrapped as a
+	// subquery, so it may contain its own WHERE, joins and placeholders.
+	SQL string
+	// Args are the arguments for SQL's placeholders.
+	Args []interface{}
+	// OrderBy names the output columns that order the results. Together they
+	// must be unique per row (end with the primary key) or rows sharing a
+	// sort key may be skipped between pages. The columns must not be NULL.
+	OrderBy []string
+	// Desc orders every OrderBy column descending instead of ascending.
+	Desc bool
+	// PageSize is the maximum number of items per page. Default 50.
+	PageSize int
+	// Cursor is the nextCursor returned by the previous page, or "" for the
+	// first page.
+	Cursor string
+}
+
+// Paginate returns one page of cfg.SQL using keyset (cursor) pagination.
+// Instead of OFFSET, which makes the database read and discard every
+// earlier row, each page continues from the sort key of the previous page's
+// last row:
+//
+//	SELECT * FROM (<SQL>) AS pgxkit_page WHERE (<OrderBy>) > (<cursor>) ORDER BY <OrderBy> LIMIT <PageSize+1>
+//
+// so every page costs the same with a suitable index, and rows inserted or
+// deleted between calls don't shift later pages the way they do with OFFSET.
+// nextCursor is an opaque token encoding the last item's sort key, or ""
+// when there are no more rows.
+//
+// Example:
+//
+//	cfg := pgxkit.PaginateConfig{
+//	    SQL:      "SELECT id, name, created_at FROM users WHERE active",
+//	    OrderBy:  []string{"created_at", "id"},
+//	    PageSize: 100,
+//	    Cursor:   r.URL.Query().Get("cursor"),
+//	}
+//	users, next, err := pgxkit.Paginate(ctx, db, cfg, pgx.RowToStructByName[User])
+func Paginate[T any](ctx context.Context, db *DB, cfg PaginateConfig, scan pgx.RowToFunc[T]) (items []T, nextCursor string, err error) {
+	if len(cfg.OrderBy) == 0 {
+		return nil, "", fmt.Errorf("paginate: OrderBy is required")
+	}
+	for _, col := range cfg.OrderBy {
+		if !identifierPattern.MatchString(col) {
+			return nil, "", fmt.Errorf("paginate: invalid order column %q", col)
+		}
+	}
+	pageSize := cfg.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	var after []string
+	if cfg.Cursor != "" {
+		if after, err = decodeCursor(cfg.Cursor, len(cfg.OrderBy)); err != nil {
+			return nil, "", err
+		}
+	}
+
+	sql, args := paginateSQL(cfg, after, pageSize)
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	keyIndexes, err := orderColumnIndexes(rows.FieldDescriptions(), cfg.OrderBy)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var lastKey []string
+	for rows.Next() {
+		if len(items) == pageSize {
+			// The extra row only tells us another page exists.
+			if nextCursor, err = encodeCursor(lastKey); err != nil {
+				return nil, "", err
+			}
+			break
+		}
+		item, err := scan(rows)
+		if err != nil {
+			return nil, "", err
+		}
+		items = append(items, item)
+		if len(items) == pageSize {
+			if lastKey, err = cursorKey(rows, keyIndexes, cfg.OrderBy); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	return items, nextCursor, nil
+}
+
+// paginateSQL wraps cfg.SQL with the keyset condition, ordering and limit.
+// Cursor values are bound after cfg.Args as text parameters, which the
+// server parses as the type of the column they are compared with.
+func paginateSQL(cfg PaginateConfig, after []string, pageSize int) (string, []interface{}) {
+	cols := make([]string, len(cfg.OrderBy))
+	order := make([]string, len(cfg.OrderBy))
+	direction, op := "", ">"
+	if cfg.Desc {
+		direction, op = " DESC", "<"
+	}
+	for i, col := range cfg.OrderBy {
+		cols[i] = pgx.Identifier{col}.Sanitize()
+		order[i] = cols[i] + direction
+	}
+
+	args := append([]interface{}(nil), cfg.Args...)
+	var b strings.Builder
+	fmt.Fprintf(&b, "SELECT * FROM (%s) AS pgxkit_page", cfg.SQL)
+	if after != nil {
+		params := make([]string, len(after))
+		for i, v := range after {
+			args = append(args, v)
+			params[i] = fmt.Sprintf("$%d", len(args))
+		}
+		fmt.Fprintf(&b, " WHERE (%s) %s (%s)", strings.Join(cols, ", "), op, strings.Join(params, ", "))
+	}
+	fmt.Fprintf(&b, " ORDER BY %s LIMIT %d", strings.Join(order, ", "), pageSize+1)
+	return b.String(), args
+}
+
+// orderColumnIndexes finds each OrderBy column among the result columns.
+func orderColumnIndexes(fields []pgconn.FieldDescription, orderBy []string) ([]int, error) {
+	indexes := make([]int, len(orderBy))
+	for i, col := range orderBy {
+		indexes[i] = -1
+		for j, fd := range fields {
+			if fd.Name == col {
+				indexes[i] = j
+				break
+			}
+		}
+		if indexes[i] < 0 {
+			return nil, fmt.Errorf("paginate: order column %q is not in the query's output", col)
+		}
+	}
+	return indexes, nil
+}
+
+// cursorKey renders the current row's sort key in PostgreSQL text format.
+func cursorKey(rows pgx.Rows, keyIndexes []int, orderBy []string) ([]string, error) {
+	values, err := rows.Values()
+	if err != nil {
+		return nil, err
+	}
+	fields := rows.FieldDescriptions()
+	typeMap := rows.Conn().TypeMap()
+	key := make([]string, len(keyIndexes))
+	for i, idx := range keyIndexes {
+		if values[idx] == nil {
+			return nil, fmt.Errorf("paginate: order column %q is NULL", orderBy[i])
+		}
+		text, err := typeMap.Encode(fields[idx].DataTypeOID, pgtype.TextFormatCode, values[idx], nil)
+		if err != nil {
+			return nil, fmt.Errorf("paginate: failed to encode cursor column %q: %w", orderBy[i], err)
+		}
+		key[i] = string(text)
+	}
+	return key, nil
+}
+
+func encodeCursor(key []string) (string, error) {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(cursor string, columns int) ([]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("paginate: invalid cursor: %w", err)
+	}
+	var key []string
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("paginate: invalid cursor: %w", err)
+	}
+	if len(key) != columns {
+		return nil, fmt.Errorf("paginate: cursor has %d values, expected %d", len(key), columns)
+	}
+	return key, nil
+}
+
+// SafeLimitOffset clamps user-supplied pagination parameters before they are
+// bound as LIMIT and OFFSET arguments: limit to [1, maxLimit] and offset to
+// at least 0, so a request can't ask for a million rows or a negative offset.
+// A maxLimit below 1 is treated as 1.
+//
+// Example:
+//
+//	limit, offset := pgxkit.SafeLimitOffset(req.Limit, req.Offset, 100)
+//	rows, err := db.Query(ctx, "SELECT * FROM users ORDER BY id LIMIT $1 OFFSET $2", limit, offset)
+func SafeLimitOffset(limit, offset, maxLimit int) (int, int) {
+	return min(max(limit, 1), max(maxLimit, 1)), max(offset, 0)
+}
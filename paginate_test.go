@@ -0,0 +1,162 @@
+package pgxkit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestPaginateSQL(t *testing.T) {
+	cfg := PaginateConfig{
+		SQL:     "SELECT id, created_at FROM events WHERE kind = $1",
+		Args:    []interface{}{"click"},
+		OrderBy: []string{"created_at", "id"},
+	}
+
+	sql, args := paginateSQL(cfg, nil, 10)
+	want := `SELECT * FROM (SELECT id, created_at FROM events WHERE kind = $1) AS pgxkit_page ORDER BY "created_at", "id" LIMIT 11`
+	if sql != want || len(args) != 1 {
+		t.Errorf("first page:\n got %s %v\nwant %s", sql, args, want)
+	}
+
+	cfg.Desc = true
+	sql, args = paginateSQL(cfg, []string{"2024-01-01 00:00:00Z", "42"}, 10)
+	want = `SELECT * FROM (SELECT id, created_at FROM events WHERE kind = $1) AS pgxkit_page WHERE ("created_at", "id") < ($2, $3) ORDER BY "created_at" DESC, "id" DESC LIMIT 11`
+	if sql != want {
+		t.Errorf("later page:\n got %s\nwant %s", sql, want)
+	}
+	if len(args) != 3 || args[0] != "click" || args[1] != "2024-01-01 00:00:00Z" || args[2] != "42" {
+		t.Errorf("expected base args followed by cursor values, got %v", args)
+	}
+	if len(cfg.Args) != 1 {
+		t.Error("paginateSQL must not modify cfg.Args")
+	}
+}
+
+func TestPaginateCursorRoundTrip(t *testing.T) {
+	cursor, err := encodeCursor([]string{"2024-01-01 00:00:00Z", "42"})
+	if err != nil {
+		t.Fatalf("encodeCursor: %v", err)
+	}
+	key, err := decodeCursor(cursor, 2)
+	if err != nil || len(key) != 2 || key[1] != "42" {
+		t.Errorf("round trip failed: %v, %v", key, err)
+	}
+	if _, err := decodeCursor(cursor, 1); err == nil {
+		t.Error("expected error when the cursor doesn't match OrderBy")
+	}
+	if _, err := decodeCursor("not a cursor!", 2); err == nil {
+		t.Error("expected error for a malformed cursor")
+	}
+}
+
+func TestPaginateValidation(t *testing.T) {
+	db := NewDB()
+	ctx := context.Background()
+	scan := pgx.RowTo[int]
+
+	if _, _, err := Paginate(ctx, db, PaginateConfig{SQL: "SELECT 1"}, scan); err == nil || !strings.Contains(err.Error(), "OrderBy") {
+		t.Errorf("expected missing OrderBy error, got %v", err)
+	}
+	if _, _, err := Paginate(ctx, db, PaginateConfig{SQL: "SELECT 1", OrderBy: []string{"id; DROP TABLE x"}}, scan); err == nil || !strings.Contains(err.Error(), "invalid order column") {
+		t.Errorf("expected invalid column error, got %v", err)
+	}
+	if _, _, err := Paginate(ctx, db, PaginateConfig{SQL: "SELECT 1", OrderBy: []string{"id"}, Cursor: "bogus!"}, scan); err == nil || !strings.Contains(err.Error(), "invalid cursor") {
+		t.Errorf("expected invalid cursor error, got %v", err)
+	}
+}
+
+func TestSafeLimitOffset(t *testing.T) {
+	tests := []struct {
+		name                  string
+		limit, offset, max    int
+		wantLimit, wantOffset int
+	}{
+		{"in range", 20, 40, 100, 20, 40},
+		{"zero", 0, 0, 100, 1, 0},
+		{"negative", -5, -10, 100, 1, 0},
+		{"over max", 1_000_000, 5, 100, 100, 5},
+		{"at max", 100, 0, 100, 100, 0},
+		{"non-positive max", 20, 0, 0, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, offset := SafeLimitOffset(tt.limit, tt.offset, tt.max)
+			if limit != tt.wantLimit || offset != tt.wantOffset {
+				t.Errorf("SafeLimitOffset(%d, %d, %d) = %d, %d; want %d, %d",
+					tt.limit, tt.offset, tt.max, limit, offset, tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestPaginateIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+	db := NewDB()
+	db.writePool = pool
+	db.readPool = pool
+	ctx := context.Background()
+
+	if _, err := db.Exec(ctx, `CREATE TABLE IF NOT EXISTS paginate_items (
+		id int PRIMARY KEY, created_at timestamptz NOT NULL, name text NOT NULL)`); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	t.Cleanup(func() { _, _ = db.Exec(context.Background(), "DROP TABLE IF EXISTS paginate_items") })
+	// Timestamps repeat every 7 rows so pages must split ties on id.
+	if _, err := db.Exec(ctx, `TRUNCATE paginate_items;
+		INSERT INTO paginate_items
+		SELECT i, timestamptz '2024-01-01 00:00:00.123456Z' + (i % 7) * interval '1 minute', 'item ' || i
+		FROM generate_series(1, 53) AS i`); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	type item struct {
+		ID   int
+		Name string
+	}
+	scan := func(row pgx.CollectableRow) (item, error) {
+		var it item
+		err := row.Scan(&it.ID, new(interface{}), &it.Name)
+		return it, err
+	}
+
+	for _, desc := range []bool{false, true} {
+		cfg := PaginateConfig{
+			SQL:      "SELECT id, created_at, name FROM paginate_items WHERE id <> $1",
+			Args:     []interface{}{13},
+			OrderBy:  []string{"created_at", "id"},
+			Desc:     desc,
+			PageSize: 10,
+		}
+		seen := map[int]bool{}
+		pages := 0
+		for {
+			items, next, err := Paginate(ctx, db, cfg, scan)
+			if err != nil {
+				t.Fatalf("desc=%v page %d: %v", desc, pages, err)
+			}
+			pages++
+			for _, it := range items {
+				if seen[it.ID] {
+					t.Errorf("desc=%v: item %d returned twice", desc, it.ID)
+				}
+				seen[it.ID] = true
+			}
+			if next == "" {
+				break
+			}
+			if len(items) != cfg.PageSize {
+				t.Errorf("desc=%v: non-final page has %d items", desc, len(items))
+			}
+			cfg.Cursor = next
+		}
+		if len(seen) != 52 || seen[13] {
+			t.Errorf("desc=%v: expected all 52 matching items exactly once, got %d", desc, len(seen))
+		}
+		if pages != 6 {
+			t.Errorf("desc=%v: expected 6 pages, got %d", desc, pages)
+		}
+	}
+}
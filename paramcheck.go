@@ -0,0 +1,68 @@
+package pgxkit
+
+import (
+	"regexp"
+	"testing"
+)
+
+const (
+	// literalComparison matches the comparison operators that inlined user
+	// input usually sits behind, including IN (...) and = ANY(...).
+	literalComparison = `\s*(?:=|<>|!=|<=|>=|<|>|(?:NOT\s+)?(?:I?LIKE|IN)|IS\s+(?:NOT\s+)?DISTINCT\s+FROM)\s*(?:(?:ANY\s*)?\(\s*)?`
+	stringLiteral     = `'(?:[^']|'')*'`
+	numberLiteral     = `-?\d+(?:\.\d+)?\b`
+	// columnPrefix stops a column from matching the tail of a longer
+	// identifier, and opens the reported group with an optional table
+	// qualifier.
+	columnPrefix = `(?:^|[^\w$".])((?:"?\w+"?\.)?"?`
+)
+
+// anyColumnLiteral matches any column compared to a string literal.
+var anyColumnLiteral = regexp.MustCompile(`(?i)` + columnPrefix + `[A-Za-z_][\w$]*"?` + literalComparison + stringLiteral + `)`)
+
+// inlinedLiterals returns the comparisons in sql that test a column against
+// a literal instead of a parameter. With no columns, any column compared to
+// a string literal is reported. Given columns are reported when compared to
+// a string or numeric literal, and other columns are ignored, so constant
+// filters such as status = 'active' can stay inline.
+func inlinedLiterals(sql string, columns []string) []string {
+	var patterns []*regexp.Regexp
+	for _, col := range columns {
+		patterns = append(patterns, regexp.MustCompile(`(?i)`+columnPrefix+regexp.QuoteMeta(col)+`"?`+
+			literalComparison+`(?:`+stringLiteral+`|`+numberLiteral+`))`))
+	}
+	if len(patterns) == 0 {
+		patterns = []*regexp.Regexp{anyColumnLiteral}
+	}
+	var found []string
+	for _, re := range patterns {
+		for _, m := range re.FindAllStringSubmatch(sql, -1) {
+			found = append(found, m[1])
+		}
+	}
+	return found
+}
+
+// AssertParameterized fails the test if sql compares a column to an inline
+// literal where a $n parameter was expected, the usual sign of user input
+// concatenated into a query:
+//
+//	sql := buildUserSearch(input)
+//	testDB.AssertParameterized(t, sql, "email", "users.id")
+//
+// Pass the columns that take user input; they are flagged when compared to
+// any string or numeric literal, while constant filters on other columns are
+// left alone. With no columns, every comparison against a string literal is
+// flagged. It is a heuristic lint, not a parser: it does not see literals
+// built with functions or casts, and it can be fooled by comments.
+func (tdb *TestDB) AssertParameterized(t *testing.T, sql string, columns ...string) {
+	t.Helper()
+	assertParameterized(t, sql, columns)
+}
+
+func assertParameterized(t goldenT, sql string, columns []string) {
+	t.Helper()
+	for _, lit := range inlinedLiterals(sql, columns) {
+		t.Errorf("query compares to an inline literal where a parameter was expected: %s\nquery: %s", lit, sql)
+	}
+}
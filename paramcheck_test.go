@@ -0,0 +1,51 @@
+package pgxkit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInlinedLiterals(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		columns []string
+		want    []string
+	}{
+		{"parameterized", "SELECT * FROM users WHERE email = $1 AND id = $2", []string{"email", "id"}, nil},
+		{"concatenated string", "SELECT * FROM users WHERE email = 'bob@example.com'", []string{"email"}, []string{"email = 'bob@example.com'"}},
+		{"concatenated number", "DELETE FROM users WHERE id = 42", []string{"id"}, []string{"id = 42"}},
+		{"qualified and quoted", `SELECT * FROM users u WHERE u."email" LIKE 'bob%'`, []string{"email"}, []string{`u."email" LIKE 'bob%'`}},
+		{"IN list", "SELECT * FROM users WHERE id IN (1, 2)", []string{"id"}, []string{"id IN (1"}},
+		{"escaped quote", "SELECT * FROM users WHERE name = 'O''Brien' OR 1=1", []string{"name"}, []string{"name = 'O''Brien'"}},
+		{"constant filter on another column", "SELECT * FROM users WHERE status = 'active' AND email = $1", []string{"email"}, nil},
+		{"longer identifier ignored", "SELECT * FROM users WHERE user_id = 7", []string{"id"}, nil},
+		{"no columns flags string literals", "SELECT * FROM users WHERE status = 'active' AND age > 18", nil, []string{"status = 'active'"}},
+		{"no columns parameterized", "SELECT * FROM users WHERE status = $1", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := inlinedLiterals(tt.sql, tt.columns)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAssertParameterized(t *testing.T) {
+	concatenated := "SELECT * FROM users WHERE email = '" + "x' OR '1'='1" + "'"
+	mt := &capturingT{}
+	assertParameterized(mt, concatenated, []string{"email"})
+	if !mt.failed || !strings.Contains(mt.errorMsg, "inline literal") {
+		t.Errorf("expected a concatenated query to fail, got %q", mt.errorMsg)
+	}
+
+	mt = &capturingT{}
+	assertParameterized(mt, "SELECT * FROM users WHERE email = $1", []string{"email"})
+	if mt.failed {
+		t.Errorf("expected a parameterized query to pass, got %q", mt.errorMsg)
+	}
+
+	NewTestDB().AssertParameterized(t, "UPDATE users SET name = $1 WHERE id = $2", "name", "id")
+}
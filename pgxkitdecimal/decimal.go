@@ -0,0 +1,72 @@
+// Package pgxkitdecimal converts between pgtype.Numeric and
+// shopspring/decimal without going through float64.
+//
+// pgxkit.FromPgxNumeric returns a float64, which can't represent most decimal
+// fractions or integers beyond 2^53 exactly. These helpers copy the numeric's
+// integer coefficient and exponent instead, so no digits are lost.
+//
+// It lives in its own module so pgxkit's core go.mod doesn't force the
+// decimal dependency on everyone.
+package pgxkitdecimal
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrNullNumeric is returned by FromPgxNumericToDecimal for a NULL numeric.
+	ErrNullNumeric = errors.New("numeric is NULL")
+
+	// ErrNotFinite is returned by FromPgxNumericToDecimal for NaN and
+	// ±Infinity, which decimal.Decimal can't represent.
+	ErrNotFinite = errors.New("numeric is NaN or infinite")
+)
+
+// FromPgxNumericToDecimal converts a pgtype.Numeric to a decimal.Decimal
+// exactly. It returns ErrNullNumeric if the numeric is invalid (NULL) and
+// ErrNotFinite for NaN or infinity. Use FromPgxNumericToNullDecimal for
+// nullable columns.
+func FromPgxNumericToDecimal(n pgtype.Numeric) (decimal.Decimal, error) {
+	if !n.Valid {
+		return decimal.Decimal{}, ErrNullNumeric
+	}
+	if n.NaN || n.InfinityModifier != pgtype.Finite {
+		return decimal.Decimal{}, ErrNotFinite
+	}
+	if n.Int == nil {
+		return decimal.New(0, n.Exp), nil
+	}
+	return decimal.NewFromBigInt(n.Int, n.Exp), nil
+}
+
+// FromPgxNumericToNullDecimal is FromPgxNumericToDecimal for nullable
+// columns: NULL becomes an invalid decimal.NullDecimal instead of an error.
+func FromPgxNumericToNullDecimal(n pgtype.Numeric) (decimal.NullDecimal, error) {
+	if !n.Valid {
+		return decimal.NullDecimal{}, nil
+	}
+	d, err := FromPgxNumericToDecimal(n)
+	if err != nil {
+		return decimal.NullDecimal{}, err
+	}
+	return decimal.NullDecimal{Decimal: d, Valid: true}, nil
+}
+
+// ToPgxNumericFromDecimal converts a decimal.Decimal to a valid
+// pgtype.Numeric with the same coefficient and exponent.
+func ToPgxNumericFromDecimal(d decimal.Decimal) pgtype.Numeric {
+	return pgtype.Numeric{Int: d.Coefficient(), Exp: d.Exponent(), Valid: true}
+}
+
+// ToPgxNumericFromNullDecimal converts a decimal.NullDecimal to
+// pgtype.Numeric. An invalid NullDecimal becomes an invalid pgtype.Numeric
+// (NULL in database).
+func ToPgxNumericFromNullDecimal(d decimal.NullDecimal) pgtype.Numeric {
+	if !d.Valid {
+		return pgtype.Numeric{Valid: false}
+	}
+	return ToPgxNumericFromDecimal(d.Decimal)
+}
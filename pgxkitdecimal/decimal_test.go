@@ -0,0 +1,124 @@
+package pgxkitdecimal
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/nhalm/pgxkit/v2"
+	"github.com/shopspring/decimal"
+)
+
+func scanNumeric(t *testing.T, s string) pgtype.Numeric {
+	t.Helper()
+	var n pgtype.Numeric
+	if err := n.Scan(s); err != nil {
+		t.Fatalf("scan %q: %v", s, err)
+	}
+	return n
+}
+
+func TestFromPgxNumericToDecimal_ExactWhereFloatIsNot(t *testing.T) {
+	tests := []struct {
+		value string
+		// floatLossy marks values float64 can't hold, where the float path
+		// must disagree for the comparison to mean anything.
+		floatLossy bool
+	}{
+		{"0.1", false},
+		{"-0.000000000000000000123456789", false},
+		{"12345678901234567.89", true},
+		{"9007199254740993", true},
+		{"123456789012345678901234567890.123456789", true},
+	}
+	for _, tt := range tests {
+		n := scanNumeric(t, tt.value)
+		want := decimal.RequireFromString(tt.value)
+
+		d, err := FromPgxNumericToDecimal(n)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.value, err)
+		}
+		if !d.Equal(want) {
+			t.Errorf("%s: decimal path got %s", tt.value, d)
+		}
+
+		f := pgxkit.FromPgxNumeric(n)
+		if f == nil {
+			t.Fatalf("%s: float path returned nil", tt.value)
+		}
+		if got := decimal.NewFromFloat(*f).Equal(want); got == tt.floatLossy {
+			t.Errorf("%s: float path exact=%v, expected exact=%v (got %v)", tt.value, got, !tt.floatLossy, *f)
+		}
+	}
+}
+
+func TestFromPgxNumericToDecimal_NullAndNonFinite(t *testing.T) {
+	if _, err := FromPgxNumericToDecimal(pgtype.Numeric{Valid: false}); !errors.Is(err, ErrNullNumeric) {
+		t.Errorf("expected ErrNullNumeric, got %v", err)
+	}
+	if _, err := FromPgxNumericToDecimal(pgtype.Numeric{NaN: true, Valid: true}); !errors.Is(err, ErrNotFinite) {
+		t.Errorf("expected ErrNotFinite for NaN, got %v", err)
+	}
+	if _, err := FromPgxNumericToDecimal(pgtype.Numeric{InfinityModifier: pgtype.Infinity, Valid: true}); !errors.Is(err, ErrNotFinite) {
+		t.Errorf("expected ErrNotFinite for Infinity, got %v", err)
+	}
+
+	nd, err := FromPgxNumericToNullDecimal(pgtype.Numeric{Valid: false})
+	if err != nil || nd.Valid {
+		t.Errorf("expected invalid NullDecimal for NULL, got %+v, %v", nd, err)
+	}
+	nd, err = FromPgxNumericToNullDecimal(scanNumeric(t, "1.50"))
+	if err != nil || !nd.Valid || !nd.Decimal.Equal(decimal.RequireFromString("1.5")) {
+		t.Errorf("expected valid 1.50, got %+v, %v", nd, err)
+	}
+}
+
+func TestFromPgxNumericToDecimal_NilInt(t *testing.T) {
+	d, err := FromPgxNumericToDecimal(pgtype.Numeric{Exp: -2, Valid: true})
+	if err != nil || !d.IsZero() {
+		t.Errorf("expected zero for nil Int, got %s, %v", d, err)
+	}
+}
+
+func TestToPgxNumericFromDecimal(t *testing.T) {
+	d := decimal.RequireFromString("-98765432109876543210.0123456789")
+	n := ToPgxNumericFromDecimal(d)
+	if !n.Valid {
+		t.Fatal("expected valid numeric")
+	}
+	want := scanNumeric(t, "-98765432109876543210.0123456789")
+	// Compare value, not representation: scale the one with the larger
+	// exponent down to the other's.
+	if !sameNumeric(n, want) {
+		t.Errorf("got Int=%s Exp=%d, want %s", n.Int, n.Exp, d)
+	}
+
+	back, err := FromPgxNumericToDecimal(n)
+	if err != nil || !back.Equal(d) {
+		t.Errorf("round trip got %s, %v; want %s", back, err, d)
+	}
+
+	if n := ToPgxNumericFromNullDecimal(decimal.NullDecimal{}); n.Valid {
+		t.Error("expected invalid numeric for invalid NullDecimal")
+	}
+	if n := ToPgxNumericFromNullDecimal(decimal.NewNullDecimal(d)); !sameNumeric(n, want) {
+		t.Errorf("NullDecimal conversion got Int=%s Exp=%d", n.Int, n.Exp)
+	}
+}
+
+func sameNumeric(a, b pgtype.Numeric) bool {
+	if a.Valid != b.Valid {
+		return false
+	}
+	ai, bi := new(big.Int).Set(a.Int), new(big.Int).Set(b.Int)
+	ten := big.NewInt(10)
+	for e := a.Exp; e > b.Exp; e-- {
+		ai.Mul(ai, ten)
+	}
+	for e := b.Exp; e > a.Exp; e-- {
+		bi.Mul(bi, ten)
+	}
+	return ai.Cmp(bi) == 0
+}
@@ -0,0 +1,89 @@
+// Package pgxkittest starts throwaway PostgreSQL containers for tests that
+// use pgxkit, so CI doesn't need an external database.
+//
+// It lives in its own module to keep testcontainers-go and its Docker
+// dependencies out of pgxkit's core go.mod.
+package pgxkittest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/nhalm/pgxkit/v2"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+// DefaultImage is the PostgreSQL image StartPostgres runs.
+const DefaultImage = "postgres:16-alpine"
+
+// StartPostgres starts a PostgreSQL container and returns its DSN and a
+// function that terminates it. Docker must be available.
+//
+// To share one container across a package, start it in TestMain and export
+// TEST_DATABASE_URL so pgxkit.RequireDB picks it up:
+//
+//	func TestMain(m *testing.M) {
+//	    dsn, terminate, err := pgxkittest.StartPostgres(context.Background())
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    os.Setenv("TEST_DATABASE_URL", dsn)
+//	    code := m.Run()
+//	    terminate()
+//	    os.Exit(code)
+//	}
+func StartPostgres(ctx context.Context) (dsn string, terminate func(), err error) {
+	ctr, err := postgres.Run(ctx, DefaultImage,
+		postgres.WithDatabase("testdb"),
+		postgres.WithUsername("postgres"),
+		postgres.WithPassword("postgres"),
+		postgres.BasicWaitStrategies(),
+	)
+	if err != nil {
+		if ctr != nil {
+			_ = testcontainers.TerminateContainer(ctr)
+		}
+		return "", nil, fmt.Errorf("failed to start postgres container: %w", err)
+	}
+
+	dsn, err = ctr.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		_ = testcontainers.TerminateContainer(ctr)
+		return "", nil, fmt.Errorf("failed to get container DSN: %w", err)
+	}
+
+	terminate = func() {
+		_ = testcontainers.TerminateContainer(ctr)
+	}
+	return dsn, terminate, nil
+}
+
+// RequireDB behaves like pgxkit.RequireDB when TEST_DATABASE_URL is set.
+// Otherwise it starts a container for this test, terminated at cleanup, and
+// skips the test if Docker is unavailable.
+func RequireDB(t *testing.T) *pgxkit.TestDB {
+	t.Helper()
+	if os.Getenv("TEST_DATABASE_URL") != "" {
+		return pgxkit.RequireDB(t)
+	}
+
+	ctx := context.Background()
+	dsn, terminate, err := StartPostgres(ctx)
+	if err != nil {
+		t.Skipf("no TEST_DATABASE_URL and no container available: %v", err)
+		return nil
+	}
+	t.Cleanup(terminate)
+
+	testDB := pgxkit.NewTestDB()
+	if err := testDB.Connect(ctx, dsn); err != nil {
+		t.Fatalf("failed to connect to container database: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = testDB.Shutdown(context.Background())
+	})
+	return testDB
+}
@@ -0,0 +1,48 @@
+package pgxkittest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestStartPostgres(t *testing.T) {
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+	ctx := context.Background()
+
+	dsn, terminate, err := StartPostgres(ctx)
+	if err != nil {
+		t.Fatalf("StartPostgres failed: %v", err)
+	}
+	defer terminate()
+
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	var n int
+	if err := conn.QueryRow(ctx, "SELECT 1").Scan(&n); err != nil {
+		t.Fatalf("SELECT 1 failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1, got %d", n)
+	}
+}
+
+func TestRequireDBStartsContainer(t *testing.T) {
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+	t.Setenv("TEST_DATABASE_URL", "")
+
+	testDB := RequireDB(t)
+	var n int
+	if err := testDB.QueryRow(context.Background(), "SELECT 1").Scan(&n); err != nil {
+		t.Fatalf("SELECT 1 failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1, got %d", n)
+	}
+}
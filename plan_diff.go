@@ -0,0 +1,161 @@
+package pgxkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// summarizePlanDiff renders a structural, node-level description of how the
+// current plans differ from the baseline: changed node types and the
+// relations/indexes they touch, changed conditions and row estimates on
+// otherwise matching nodes, plus added or removed nodes and queries. It
+// returns "" when either side can't be decoded or no structural change is
+// found, leaving the unified diff to speak for itself.
+func summarizePlanDiff(baseline, current []byte) string {
+	var before, after []QueryPlan
+	if err := json.Unmarshal(baseline, &before); err != nil {
+		return ""
+	}
+	if err := json.Unmarshal(current, &after); err != nil {
+		return ""
+	}
+
+	var changes []string
+	for i := 0; i < len(before) || i < len(after); i++ {
+		switch {
+		case i >= len(before):
+			changes = append(changes, fmt.Sprintf("%s added: %s", planLabel(after[i]), after[i].SQL))
+		case i >= len(after):
+			changes = append(changes, fmt.Sprintf("%s removed: %s", planLabel(before[i]), before[i].SQL))
+		default:
+			label := planLabel(after[i])
+			if before[i].SQL != after[i].SQL {
+				changes = append(changes, fmt.Sprintf("%s: SQL changed from %q to %q", label, before[i].SQL, after[i].SQL))
+			}
+			diffPlanNodes(label, rootPlanNode(before[i].Plan), rootPlanNode(after[i].Plan), &changes)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+	return "plan changes:\n  " + strings.Join(changes, "\n  ")
+}
+
+// planLabel names a query in the summary: "query 3", or "query 3
+// GetUserByID" when it ran under WithQueryName.
+func planLabel(p QueryPlan) string {
+	if p.Name != "" {
+		return fmt.Sprintf("query %d %s", p.Query, p.Name)
+	}
+	return fmt.Sprintf("query %d", p.Query)
+}
+
+// rootPlanNode returns the top "Plan" node of an EXPLAIN (FORMAT JSON) result.
+func rootPlanNode(plan []map[string]interface{}) map[string]interface{} {
+	if len(plan) == 0 {
+		return nil
+	}
+	node, _ := plan[0]["Plan"].(map[string]interface{})
+	return node
+}
+
+// diffPlanNodes walks two plan trees in parallel, matching children by
+// position, and records every node whose description differs.
+func diffPlanNodes(label string, before, after map[string]interface{}, changes *[]string) {
+	switch {
+	case before == nil && after == nil:
+		return
+	case before == nil:
+		*changes = append(*changes, fmt.Sprintf("%s: added %s", label, describePlanNode(after)))
+		return
+	case after == nil:
+		*changes = append(*changes, fmt.Sprintf("%s: removed %s", label, describePlanNode(before)))
+		return
+	}
+
+	if b, a := describePlanNode(before), describePlanNode(after); b != a {
+		*changes = append(*changes, fmt.Sprintf("%s: %s → %s", label, b, a))
+	} else {
+		for _, prop := range planNodeProperties {
+			bv, av := formatPlanValue(before[prop.key]), formatPlanValue(after[prop.key])
+			if bv != av {
+				*changes = append(*changes, fmt.Sprintf("%s: %s: %s %s → %s", label, b, prop.label, bv, av))
+			}
+		}
+	}
+
+	beforeChildren := planChildren(before)
+	afterChildren := planChildren(after)
+	for i := 0; i < len(beforeChildren) || i < len(afterChildren); i++ {
+		var b, a map[string]interface{}
+		if i < len(beforeChildren) {
+			b = beforeChildren[i]
+		}
+		if i < len(afterChildren) {
+			a = afterChildren[i]
+		}
+		diffPlanNodes(label, b, a, changes)
+	}
+}
+
+func planChildren(node map[string]interface{}) []map[string]interface{} {
+	raw, _ := node["Plans"].([]interface{})
+	children := make([]map[string]interface{}, 0, len(raw))
+	for _, c := range raw {
+		if child, ok := c.(map[string]interface{}); ok {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// describePlanNode renders a node the way EXPLAIN's text format does, e.g.
+// "Index Scan using users_email_idx on users".
+func describePlanNode(node map[string]interface{}) string {
+	desc, _ := node["Node Type"].(string)
+	if join, ok := node["Join Type"].(string); ok && join != "Inner" {
+		desc += " (" + join + ")"
+	}
+	if index, ok := node["Index Name"].(string); ok {
+		desc += " using " + index
+	}
+	if relation, ok := node["Relation Name"].(string); ok {
+		desc += " on " + relation
+	}
+	return desc
+}
+
+// planNodeProperties are the EXPLAIN fields compared between nodes of the same
+// type, with the wording used for them in the summary. "Plan Rows" is only
+// present in plans captured WithPlanEstimates.
+var planNodeProperties = []struct{ key, label string }{
+	{"Plan Rows", "estimated rows"},
+	{"Index Cond", "index condition"},
+	{"Recheck Cond", "recheck condition"},
+	{"Filter", "filter"},
+	{"Join Filter", "join filter"},
+	{"Hash Cond", "hash condition"},
+	{"Merge Cond", "merge condition"},
+	{"Sort Key", "sort key"},
+	{"Group Key", "group key"},
+	{"Strategy", "strategy"},
+	{"Scan Direction", "scan direction"},
+	{"Workers Planned", "workers planned"},
+}
+
+// formatPlanValue renders a decoded EXPLAIN JSON value for the summary.
+// Lists such as "Sort Key" are comma-joined and a missing value is "none".
+func formatPlanValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "none"
+	case []interface{}:
+		parts := make([]string, len(val))
+		for i, item := range val {
+			parts[i] = fmt.Sprint(item)
+		}
+		return strings.Join(parts, ", ")
+	}
+	return fmt.Sprint(v)
+}
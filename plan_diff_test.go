@@ -0,0 +1,182 @@
+package pgxkit
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func planBytes(t *testing.T, plans []QueryPlan) []byte {
+	t.Helper()
+	data, err := marshalPlans(plans)
+	if err != nil {
+		t.Fatalf("marshal plans: %v", err)
+	}
+	return data
+}
+
+func decodePlan(t *testing.T, raw string) []map[string]interface{} {
+	t.Helper()
+	var plan []map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &plan); err != nil {
+		t.Fatalf("unmarshal plan: %v", err)
+	}
+	return plan
+}
+
+func TestAssertPlan_DiffNamesChangedNode(t *testing.T) {
+	const sql = "SELECT * FROM users WHERE email = $1"
+	baseline := planBytes(t, []QueryPlan{{Query: 1, SQL: sql, Plan: decodePlan(t,
+		`[{"Plan": {"Node Type": "Index Scan", "Index Name": "users_email_idx", "Relation Name": "users"}}]`)}})
+	current := planBytes(t, []QueryPlan{{Query: 1, SQL: sql, Plan: decodePlan(t,
+		`[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "users"}}]`)}})
+
+	path := filepath.Join(t.TempDir(), "plans", "users.json")
+	if err := writeBaseline(path, baseline); err != nil {
+		t.Fatalf("write baseline: %v", err)
+	}
+
+	mt := &capturingT{}
+	assertBaselineSummarized(mt, path, current, "plan", false, summarizePlanDiff)
+	if !mt.failed {
+		t.Fatal("expected plan mismatch to fail")
+	}
+	want := "query 1: Index Scan using users_email_idx on users → Seq Scan on users"
+	if !strings.Contains(mt.errorMsg, want) {
+		t.Errorf("error should name the changed node %q, got:\n%s", want, mt.errorMsg)
+	}
+	if !strings.Contains(mt.errorMsg, "(baseline)") {
+		t.Errorf("error should still include the unified diff, got:\n%s", mt.errorMsg)
+	}
+}
+
+func TestSummarizePlanDiff_NestedAndAddedNodes(t *testing.T) {
+	before := planBytes(t, []QueryPlan{{Query: 1, SQL: "q", Plan: decodePlan(t,
+		`[{"Plan": {"Node Type": "Hash Join", "Plans": [
+			{"Node Type": "Seq Scan", "Relation Name": "orders"},
+			{"Node Type": "Hash", "Plans": [{"Node Type": "Seq Scan", "Relation Name": "users"}]}]}}]`)}})
+	after := planBytes(t, []QueryPlan{{Query: 1, SQL: "q", Plan: decodePlan(t,
+		`[{"Plan": {"Node Type": "Sort", "Plans": [{"Node Type": "Nested Loop", "Plans": [
+			{"Node Type": "Seq Scan", "Relation Name": "orders"},
+			{"Node Type": "Index Scan", "Index Name": "users_pkey", "Relation Name": "users"}]}]}}]`)}})
+
+	summary := summarizePlanDiff(before, after)
+	for _, want := range []string{
+		"query 1: Hash Join → Sort",
+		"query 1: Seq Scan on orders → Nested Loop",
+		"query 1: removed Hash",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, summary)
+		}
+	}
+}
+
+func TestSummarizePlanDiff_AddedAndRemovedQueries(t *testing.T) {
+	plan := decodePlan(t, `[{"Plan": {"Node Type": "Result"}}]`)
+	one := planBytes(t, []QueryPlan{{Query: 1, SQL: "SELECT 1", Plan: plan}})
+	two := planBytes(t, []QueryPlan{{Query: 1, SQL: "SELECT 1", Plan: plan}, {Query: 2, SQL: "SELECT 2", Plan: plan}})
+
+	if s := summarizePlanDiff(one, two); !strings.Contains(s, "query 2 added: SELECT 2") {
+		t.Errorf("expected added query in summary, got:\n%s", s)
+	}
+	if s := summarizePlanDiff(two, one); !strings.Contains(s, "query 2 removed: SELECT 2") {
+		t.Errorf("expected removed query in summary, got:\n%s", s)
+	}
+	if s := summarizePlanDiff(one, one); s != "" {
+		t.Errorf("identical plans should produce no summary, got:\n%s", s)
+	}
+	if s := summarizePlanDiff([]byte("not json"), one); s != "" {
+		t.Errorf("undecodable baseline should produce no summary, got:\n%s", s)
+	}
+}
+
+func TestAssertPlan_DiffReportsEstimatesAndConditions(t *testing.T) {
+	const sql = "SELECT * FROM orders JOIN users ON users.id = orders.user_id WHERE users.active"
+	baseline := planBytes(t, []QueryPlan{{Query: 1, SQL: sql, Plan: decodePlan(t,
+		`[{"Plan": {"Node Type": "Nested Loop", "Plan Rows": 100, "Plans": [
+			{"Node Type": "Index Scan", "Index Name": "users_pkey", "Relation Name": "users", "Plan Rows": 100, "Filter": "active"},
+			{"Node Type": "Index Scan", "Index Name": "orders_user_id_idx", "Relation Name": "orders", "Plan Rows": 1,
+			 "Index Cond": "(user_id = users.id)"}]}}]`)}})
+	current := planBytes(t, []QueryPlan{{Query: 1, SQL: sql, Plan: decodePlan(t,
+		`[{"Plan": {"Node Type": "Hash Join", "Plan Rows": 50000, "Plans": [
+			{"Node Type": "Seq Scan", "Relation Name": "users", "Plan Rows": 50000, "Filter": "active"},
+			{"Node Type": "Index Scan", "Index Name": "orders_user_id_idx", "Relation Name": "orders", "Plan Rows": 1,
+			 "Index Cond": "(user_id = users.id)", "Filter": "(total > 0)"}]}}]`)}})
+
+	path := filepath.Join(t.TempDir(), "plans", "orders.json")
+	if err := writeBaseline(path, baseline); err != nil {
+		t.Fatalf("write baseline: %v", err)
+	}
+
+	mt := &capturingT{}
+	assertBaselineSummarized(mt, path, current, "plan", false, summarizePlanDiff)
+	if !mt.failed {
+		t.Fatal("expected plan mismatch to fail")
+	}
+	for _, want := range []string{
+		"query 1: Nested Loop → Hash Join",
+		"query 1: Index Scan using users_pkey on users → Seq Scan on users",
+		"query 1: Index Scan using orders_user_id_idx on orders: filter none → (total > 0)",
+	} {
+		if !strings.Contains(mt.errorMsg, want) {
+			t.Errorf("error missing %q:\n%s", want, mt.errorMsg)
+		}
+	}
+	if strings.Contains(mt.errorMsg, "estimated rows 100 → 50000") {
+		t.Errorf("estimates of nodes whose type changed should not be reported separately:\n%s", mt.errorMsg)
+	}
+}
+
+func TestSummarizePlanDiff_EstimatedRows(t *testing.T) {
+	before := planBytes(t, []QueryPlan{{Query: 1, SQL: "q", Plan: decodePlan(t,
+		`[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "users", "Plan Rows": 100, "Sort Key": ["id"]}}]`)}})
+	after := planBytes(t, []QueryPlan{{Query: 1, SQL: "q", Plan: decodePlan(t,
+		`[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "users", "Plan Rows": 50000, "Sort Key": ["id", "created_at DESC"]}}]`)}})
+
+	summary := summarizePlanDiff(before, after)
+	for _, want := range []string{
+		"query 1: Seq Scan on users: estimated rows 100 → 50000",
+		"query 1: Seq Scan on users: sort key id → id, created_at DESC",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, summary)
+		}
+	}
+}
+
+func TestWithPlanEstimates(t *testing.T) {
+	h := &assertPlanHook{volatileFields: newVolatileFieldSet(defaultVolatilePlanFields)}
+	WithPlanEstimates()(h)
+	if !h.estimates {
+		t.Error("WithPlanEstimates should enable estimates")
+	}
+}
+
+func TestSummarizePlanDiff_UsesQueryNames(t *testing.T) {
+	before := planBytes(t, []QueryPlan{{Query: 1, Name: "GetUserByID", SQL: "q", Plan: decodePlan(t,
+		`[{"Plan": {"Node Type": "Index Scan", "Index Name": "users_pkey", "Relation Name": "users"}}]`)}})
+	after := planBytes(t, []QueryPlan{
+		{Query: 1, Name: "GetUserByID", SQL: "q", Plan: decodePlan(t, `[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "users"}}]`)},
+		{Query: 2, Name: "ListOrders", SQL: "SELECT * FROM orders", Plan: decodePlan(t, `[{"Plan": {"Node Type": "Seq Scan"}}]`)},
+	})
+
+	summary := summarizePlanDiff(before, after)
+	for _, want := range []string{
+		"query 1 GetUserByID: Index Scan using users_pkey on users → Seq Scan on users",
+		"query 2 ListOrders added: SELECT * FROM orders",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, summary)
+		}
+	}
+}
+
+func TestWithPlanAnalyze(t *testing.T) {
+	h := &assertPlanHook{volatileFields: newVolatileFieldSet(defaultVolatilePlanFields)}
+	WithPlanAnalyze()(h)
+	if !h.analyze {
+		t.Error("WithPlanAnalyze should enable analyze")
+	}
+}
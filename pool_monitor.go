@@ -0,0 +1,93 @@
+package pgxkit
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// poolSaturationInterval is how often MonitorPoolSaturation samples the pool.
+const poolSaturationInterval = time.Second
+
+// poolSample returns a stats snapshot with the acquired and maximum connection
+// counts it reports. Tests substitute fakes since pgxpool.Stat can't be built.
+type poolSample func() (stat *pgxpool.Stat, acquired, max int32)
+
+// MonitorPoolSaturation starts a background goroutine that samples the write
+// pool every second and calls fn with the current stats whenever
+// AcquiredConns/MaxConns exceeds threshold (e.g. 0.8), so pool exhaustion can
+// be logged or alerted on before requests start timing out. fn is called on
+// every sample while the pool stays above the threshold; keep it quick, since
+// Shutdown waits for an in-flight call.
+//
+// The monitor stops when ctx is cancelled or the DB shuts down. Calling it on
+// a DB that is already shut down does nothing.
+//
+// Example:
+//
+//	db.MonitorPoolSaturation(ctx, 0.8, func(stat *pgxpool.Stat) {
+//	    log.Printf("pool %d/%d acquired", stat.AcquiredConns(), stat.MaxConns())
+//	})
+func (db *DB) MonitorPoolSaturation(ctx context.Context, threshold float64, fn func(stat *pgxpool.Stat)) {
+	db.monitorSaturation(ctx, poolSaturationInterval, threshold, db.writePoolSample, fn)
+}
+
+func (db *DB) writePoolSample() (*pgxpool.Stat, int32, int32) {
+	stat := db.Stats()
+	if stat == nil {
+		return nil, 0, 0
+	}
+	return stat, stat.AcquiredConns(), stat.MaxConns()
+}
+
+func (db *DB) monitorSaturation(ctx context.Context, interval time.Duration, threshold float64, sample poolSample, fn func(stat *pgxpool.Stat)) {
+	if fn == nil {
+		return
+	}
+
+	db.mu.Lock()
+	if db.shutdown {
+		db.mu.Unlock()
+		return
+	}
+	if db.monitorStop == nil {
+		db.monitorStop = make(chan struct{})
+	}
+	stop := db.monitorStop
+	db.monitors.Add(1)
+	db.mu.Unlock()
+
+	go func() {
+		defer db.monitors.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-ticker.C:
+				stat, acquired, max := sample()
+				if max > 0 && float64(acquired)/float64(max) > threshold {
+					fn(stat)
+				}
+			}
+		}
+	}()
+}
+
+// stopMonitors stops every MonitorPoolSaturation goroutine and waits for them
+// to exit. Called from Shutdown after db.shutdown is set, so no new monitor
+// can start concurrently.
+func (db *DB) stopMonitors() {
+	db.mu.Lock()
+	if db.monitorStop != nil {
+		close(db.monitorStop)
+		db.monitorStop = nil
+	}
+	db.mu.Unlock()
+	db.monitors.Wait()
+}
@@ -0,0 +1,107 @@
+package pgxkit
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// fakeSample reports acquired/max from atomics so tests can move the pool in
+// and out of saturation.
+func fakeSample(acquired, max *atomic.Int32) poolSample {
+	return func() (*pgxpool.Stat, int32, int32) {
+		return nil, acquired.Load(), max.Load()
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMonitorPoolSaturationFiresAboveThreshold(t *testing.T) {
+	db := NewDB()
+	var acquired, max atomic.Int32
+	acquired.Store(5)
+	max.Store(10)
+
+	var calls atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db.monitorSaturation(ctx, time.Millisecond, 0.8, fakeSample(&acquired, &max), func(*pgxpool.Stat) {
+		calls.Add(1)
+	})
+
+	time.Sleep(20 * time.Millisecond)
+	if calls.Load() != 0 {
+		t.Fatalf("50%% utilisation should not trigger an 0.8 threshold, got %d calls", calls.Load())
+	}
+
+	acquired.Store(9)
+	waitFor(t, func() bool { return calls.Load() > 0 }, "monitor never reported saturation")
+}
+
+func TestMonitorPoolSaturationStopsOnCancel(t *testing.T) {
+	db := NewDB()
+	var acquired, max atomic.Int32
+	acquired.Store(10)
+	max.Store(10)
+
+	var calls atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	db.monitorSaturation(ctx, time.Millisecond, 0.5, fakeSample(&acquired, &max), func(*pgxpool.Stat) {
+		calls.Add(1)
+	})
+	waitFor(t, func() bool { return calls.Load() > 0 }, "monitor never reported saturation")
+
+	cancel()
+	db.monitors.Wait()
+	after := calls.Load()
+	time.Sleep(10 * time.Millisecond)
+	if calls.Load() != after {
+		t.Error("monitor kept running after context cancel")
+	}
+}
+
+func TestMonitorPoolSaturationStopsOnShutdown(t *testing.T) {
+	db := NewDB()
+	var acquired, max atomic.Int32
+	acquired.Store(10)
+	max.Store(10)
+
+	var calls atomic.Int32
+	db.monitorSaturation(context.Background(), time.Millisecond, 0.5, fakeSample(&acquired, &max), func(*pgxpool.Stat) {
+		calls.Add(1)
+	})
+	waitFor(t, func() bool { return calls.Load() > 0 }, "monitor never reported saturation")
+
+	if err := db.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	after := calls.Load()
+	time.Sleep(10 * time.Millisecond)
+	if calls.Load() != after {
+		t.Error("monitor kept running after Shutdown")
+	}
+
+	// Monitors requested after shutdown never start.
+	db.MonitorPoolSaturation(context.Background(), 0.5, func(*pgxpool.Stat) {
+		t.Error("monitor started after Shutdown")
+	})
+}
+
+func TestMonitorPoolSaturationNotConnected(t *testing.T) {
+	db := NewDB()
+	if stat, acquired, max := db.writePoolSample(); stat != nil || acquired != 0 || max != 0 {
+		t.Error("unconnected DB should sample as empty")
+	}
+}
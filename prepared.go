@@ -0,0 +1,214 @@
+package pgxkit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Prepare registers sql under name for ExecPrepared and QueryPrepared, and
+// prepares it once on a write-pool connection so mistakes in the SQL surface
+// here rather than on first use.
+//
+// pgxpool fixes its connection callbacks when the pool is created, so
+// statements are prepared on every other pooled connection lazily, the first
+// time they run there. After that a connection reuses its server-side
+// statement without another parse.
+//
+// Calling Prepare again with the same name and SQL is a no-op; reusing a name
+// for different SQL is an error, since connections that already prepared the
+// old statement would keep running it.
+//
+// Explicitly prepared statements bypass WithStatementCacheMode and
+// WithDefaultQueryExecMode: they are always run as named server-side
+// statements. Behind PgBouncer in transaction pooling mode that only works
+// with PgBouncer 1.21+ and max_prepared_statements enabled.
+func (db *DB) Prepare(ctx context.Context, name, sql string) error {
+	if name == "" {
+		return fmt.Errorf("prepared statement name must not be empty")
+	}
+
+	db.mu.Lock()
+	if db.shutdown {
+		db.mu.Unlock()
+		return fmt.Errorf("database is shutting down")
+	}
+	pool := db.writePool
+	if pool == nil {
+		db.mu.Unlock()
+		return fmt.Errorf("database is not connected")
+	}
+	if existing, ok := db.prepared[name]; ok {
+		db.mu.Unlock()
+		if existing != sql {
+			return fmt.Errorf("prepared statement %q is already registered with different SQL", name)
+		}
+		return nil
+	}
+	db.activeOps.Add(1)
+	users := db.holdPool(pool)
+	db.mu.Unlock()
+	defer db.endOp(users)
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+	if _, err := conn.Conn().Prepare(ctx, name, sql); err != nil {
+		return fmt.Errorf("failed to prepare %q: %w", name, err)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if existing, ok := db.prepared[name]; ok && existing != sql {
+		return fmt.Errorf("prepared statement %q is already registered with different SQL", name)
+	}
+	if db.prepared == nil {
+		db.prepared = make(map[string]string)
+	}
+	db.prepared[name] = sql
+	return nil
+}
+
+// ExecPrepared runs the statement registered under name by Prepare on the
+// write pool. Operation hooks see the statement's SQL, not its name.
+func (db *DB) ExecPrepared(ctx context.Context, name string, args ...interface{}) (pgconn.CommandTag, error) {
+	pool, sql, users, err := db.preparedTarget(name)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	defer db.endOp(users)
+
+	ctx, cancel := db.operationContext(ctx)
+	defer cancel()
+
+	ctx = withHookState(ctx)
+	if err := db.hooks.executeBeforeOperation(ctx, sql, args, pgconn.CommandTag{}, nil); err != nil {
+		return pgconn.CommandTag{}, fmt.Errorf("before operation hook failed: %w", err)
+	}
+
+	var tag pgconn.CommandTag
+	conn, err := acquirePrepared(ctx, pool, name, sql)
+	if err == nil {
+		tag, err = conn.Exec(ctx, name, args...)
+		conn.Release()
+	}
+
+	if hookErr := db.hooks.executeAfterOperation(ctx, sql, args, tag, err); hookErr != nil {
+		if err == nil {
+			return tag, fmt.Errorf("after operation hook failed: %w", hookErr)
+		}
+	}
+
+	return tag, err
+}
+
+// QueryPrepared runs the statement registered under name by Prepare on the
+// write pool. The connection goes back to the pool when the rows are closed
+// or exhausted. Operation hooks see the statement's SQL, not its name.
+func (db *DB) QueryPrepared(ctx context.Context, name string, args ...interface{}) (pgx.Rows, error) {
+	pool, sql, users, err := db.preparedTarget(name)
+	if err != nil {
+		return nil, err
+	}
+	defer db.endOp(users)
+
+	ctx, cancel := db.operationContext(ctx)
+
+	ctx = withHookState(ctx)
+	if err := db.hooks.executeBeforeOperation(ctx, sql, args, pgconn.CommandTag{}, nil); err != nil {
+		cancel()
+		return nil, fmt.Errorf("before operation hook failed: %w", err)
+	}
+
+	var rows pgx.Rows
+	conn, err := acquirePrepared(ctx, pool, name, sql)
+	if err == nil {
+		rows, err = conn.Query(ctx, name, args...)
+		if err != nil {
+			conn.Release()
+		}
+	}
+
+	if hookErr := db.hooks.executeAfterOperation(ctx, sql, args, pgconn.CommandTag{}, err); hookErr != nil {
+		if err == nil {
+			rows.Close()
+			conn.Release()
+			cancel()
+			return nil, fmt.Errorf("after operation hook failed: %w", hookErr)
+		}
+	}
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &cancelRows{Rows: &releaseRows{Rows: rows, conn: conn}, cancel: cancel}, nil
+}
+
+// preparedTarget looks up a registered statement and the pool to run it on,
+// and starts an operation on that pool as startOp does; end it with endOp.
+func (db *DB) preparedTarget(name string) (*pgxpool.Pool, string, *sync.WaitGroup, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.shutdown {
+		return nil, "", nil, fmt.Errorf("database is shutting down")
+	}
+	if db.writePool == nil {
+		return nil, "", nil, fmt.Errorf("database is not connected")
+	}
+	sql, ok := db.prepared[name]
+	if !ok {
+		return nil, "", nil, fmt.Errorf("prepared statement %q is not registered", name)
+	}
+	db.activeOps.Add(1)
+	return db.writePool, sql, db.holdPool(db.writePool), nil
+}
+
+// acquirePrepared acquires a connection and makes sure name is prepared on
+// it. pgx remembers what each connection has prepared, so this only goes to
+// the server the first time a connection sees the statement.
+func acquirePrepared(ctx context.Context, pool *pgxpool.Pool, name, sql string) (*pgxpool.Conn, error) {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	if _, err := conn.Conn().Prepare(ctx, name, sql); err != nil {
+		conn.Release()
+		return nil, fmt.Errorf("failed to prepare %q: %w", name, err)
+	}
+	return conn, nil
+}
+
+// releaseRows returns its connection to the pool once the rows are exhausted
+// or closed.
+type releaseRows struct {
+	pgx.Rows
+	conn     *pgxpool.Conn
+	released bool
+}
+
+func (r *releaseRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.release()
+	return false
+}
+
+func (r *releaseRows) Close() {
+	r.Rows.Close()
+	r.release()
+}
+
+func (r *releaseRows) release() {
+	if !r.released {
+		r.released = true
+		r.conn.Release()
+	}
+}
@@ -0,0 +1,119 @@
+package pgxkit
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPrepareNotConnected(t *testing.T) {
+	db := NewDB()
+	ctx := context.Background()
+
+	if err := db.Prepare(ctx, "get_user", "SELECT 1"); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected not connected error from Prepare, got %v", err)
+	}
+	if _, err := db.ExecPrepared(ctx, "get_user"); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected not connected error from ExecPrepared, got %v", err)
+	}
+	if _, err := db.QueryPrepared(ctx, "get_user"); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected not connected error from QueryPrepared, got %v", err)
+	}
+}
+
+func TestPrepareValidation(t *testing.T) {
+	db := NewDB()
+	db.writePool = newLazyPool(t)
+	db.readPool = db.writePool
+	db.prepared = map[string]string{"get_user": "SELECT * FROM users WHERE id = $1"}
+	ctx := context.Background()
+
+	if err := db.Prepare(ctx, "", "SELECT 1"); err == nil {
+		t.Error("expected error for empty name")
+	}
+	if err := db.Prepare(ctx, "get_user", "SELECT * FROM users WHERE id = $1"); err != nil {
+		t.Errorf("re-preparing the same SQL should be a no-op, got %v", err)
+	}
+	if err := db.Prepare(ctx, "get_user", "SELECT 2"); err == nil || !strings.Contains(err.Error(), "different SQL") {
+		t.Errorf("expected error when redefining a name, got %v", err)
+	}
+	if _, err := db.ExecPrepared(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "not registered") {
+		t.Errorf("expected not registered error, got %v", err)
+	}
+	if _, err := db.QueryPrepared(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "not registered") {
+		t.Errorf("expected not registered error, got %v", err)
+	}
+}
+
+func TestPreparedStatementsAcrossConnections(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+	ctx := context.Background()
+
+	db := NewDB()
+	if err := db.Connect(ctx, dsn, WithMaxConns(4), WithMinConns(0)); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer db.Shutdown(ctx)
+	before, after := recordingHooks(db)
+
+	if err := db.Prepare(ctx, "bad_stmt", "SELEC 1"); err == nil {
+		t.Error("expected Prepare to report a syntax error")
+	}
+	if err := db.Prepare(ctx, "pid_plus", "SELECT pg_backend_pid(), $1::int + 1"); err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+
+	// Hold several result sets open at once so each runs on its own pooled
+	// connection, most of which have never seen the statement.
+	pids := map[uint32]bool{}
+	var open []interface{ Close() }
+	for i := 0; i < 3; i++ {
+		rows, err := db.QueryPrepared(ctx, "pid_plus", i)
+		if err != nil {
+			t.Fatalf("QueryPrepared %d failed: %v", i, err)
+		}
+		open = append(open, rows)
+		if !rows.Next() {
+			t.Fatalf("QueryPrepared %d returned no rows: %v", i, rows.Err())
+		}
+		var pid uint32
+		var n int
+		if err := rows.Scan(&pid, &n); err != nil {
+			t.Fatalf("scan %d failed: %v", i, err)
+		}
+		if n != i+1 {
+			t.Errorf("expected %d, got %d", i+1, n)
+		}
+		pids[pid] = true
+	}
+	for _, rows := range open {
+		rows.Close()
+	}
+	if len(pids) != 3 {
+		t.Errorf("expected 3 distinct connections, got %d", len(pids))
+	}
+
+	tag, err := db.ExecPrepared(ctx, "pid_plus", 41)
+	if err != nil {
+		t.Fatalf("ExecPrepared failed: %v", err)
+	}
+	if tag.RowsAffected() != 1 {
+		t.Errorf("expected SELECT 1, got %s", tag)
+	}
+
+	for _, sql := range append(*before, *after...) {
+		if sql != "SELECT pg_backend_pid(), $1::int + 1" {
+			t.Errorf("hooks should see the statement SQL, got %q", sql)
+		}
+	}
+	if len(*after) != 4 {
+		t.Errorf("expected 4 hooked operations, got %d", len(*after))
+	}
+	if db.InFlight() != 0 {
+		t.Errorf("expected no in-flight operations, got %d", db.InFlight())
+	}
+}
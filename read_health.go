@@ -0,0 +1,142 @@
+package pgxkit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// defaultReadHealthCheckInterval is how often ConnectReadWrite pings the read
+// pool in the background when no interval is configured.
+const defaultReadHealthCheckInterval = 5 * time.Second
+
+// WithReadFallbackToWrite makes ReadQuery/ReadQueryRow transparently use the
+// write pool while the read replica is marked unhealthy by the background
+// health check. Without it, reads keep going to the replica and fail hard.
+// Only meaningful with ConnectReadWrite.
+func WithReadFallbackToWrite(enabled bool) ConnectOption {
+	return func(c *connectConfig) {
+		c.readFallbackToWrite = enabled
+	}
+}
+
+// WithReadHealthCheckInterval sets how often ConnectReadWrite pings the read
+// pool to track replica health. Defaults to 5 seconds.
+func WithReadHealthCheckInterval(d time.Duration) ConnectOption {
+	return func(c *connectConfig) {
+		if d > 0 {
+			c.readHealthCheckInterval = d
+		}
+	}
+}
+
+// startReadHealthCheck launches the background replica health check. The
+// caller must hold db.mu. It is a no-op in single pool mode.
+func (db *DB) startReadHealthCheck(interval time.Duration) {
+	if db.readPool == nil || db.readPool == db.writePool {
+		return
+	}
+	if interval <= 0 {
+		interval = defaultReadHealthCheckInterval
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	db.readHealthStop = stop
+	db.readHealthDone = done
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				db.checkReadHealth(ctx)
+				cancel()
+			}
+		}
+	}()
+}
+
+// stopReadHealthCheck stops the background health check and waits for it to
+// exit. Safe to call when no check is running. It must not be called with
+// db.mu held, since an in-flight check takes the read lock.
+func (db *DB) stopReadHealthCheck() {
+	db.mu.Lock()
+	stop, done := db.readHealthStop, db.readHealthDone
+	db.readHealthStop, db.readHealthDone = nil, nil
+	db.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// checkReadHealth pings the read pool and records the result.
+func (db *DB) checkReadHealth(ctx context.Context) {
+	db.mu.RLock()
+	pool := db.readPool
+	db.mu.RUnlock()
+	if pool == nil {
+		return
+	}
+	db.readUnhealthy.Store(pool.Ping(ctx) != nil)
+}
+
+// readTarget picks the pool for Read* methods: the read pool unless it is
+// marked unhealthy and fallback to the write pool is enabled. The role is
+// PoolRead either way. db.mu must be held.
+func (db *DB) readTarget() (*pgxpool.Pool, PoolSelector) {
+	if db.readFallbackToWrite && db.readPool != db.writePool && db.readUnhealthy.Load() {
+		return db.writePool, PoolRead
+	}
+	return db.readPool, PoolRead
+}
+
+// HealthyReadReplicas returns the number of read replicas currently passing
+// the background health check. It is 0 in single pool mode, before Connect,
+// and while the replica is marked unhealthy.
+//
+// Example:
+//
+//	if db.HealthyReadReplicas() == 0 {
+//	    log.Println("no healthy read replicas; reads are degraded")
+//	}
+func (db *DB) HealthyReadReplicas() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.readPool == nil || db.readPool == db.writePool || db.readUnhealthy.Load() {
+		return 0
+	}
+	return 1
+}
+
+// WithRequireDistinctPools makes ConnectReadWrite fail if the read and write
+// DSNs resolve to the same host, port, database and user. That catches the
+// easy misconfiguration of an empty or copy-pasted read DSN silently sending
+// replica traffic to the primary. Connect ignores it.
+func WithRequireDistinctPools() ConnectOption {
+	return func(c *connectConfig) {
+		c.requireDistinctPools = true
+	}
+}
+
+// sameDatabase reports whether two pool configs target the same database.
+func sameDatabase(a, b *pgxpool.Config) bool {
+	ac, bc := a.ConnConfig, b.ConnConfig
+	return ac.Host == bc.Host && ac.Port == bc.Port && ac.Database == bc.Database && ac.User == bc.User
+}
+
+// describeTarget renders a config's target for error messages, without the
+// password.
+func describeTarget(c *pgxpool.Config) string {
+	cc := c.ConnConfig
+	return fmt.Sprintf("%s@%s:%d/%s", cc.User, cc.Host, cc.Port, cc.Database)
+}
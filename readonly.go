@@ -0,0 +1,84 @@
+package pgxkit
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrWriteOnReadPool is returned by ReadQuery and ReadQueryRow under
+// WithReadOnlyGuard when the statement isn't a plain read.
+var ErrWriteOnReadPool = errors.New("write statement sent to the read pool")
+
+// dataModifyingCTE matches a WITH clause member such as
+// "x AS (DELETE ..." or "x AS MATERIALIZED (UPDATE ...".
+var dataModifyingCTE = regexp.MustCompile(`(?i)\bAS\s+(?:NOT\s+)?(?:MATERIALIZED\s+)?\(\s*(?:INSERT|UPDATE|DELETE|MERGE)\b`)
+
+// WithReadOnlyGuard makes ReadQuery and ReadQueryRow reject anything but a
+// SELECT or a WITH query whose members are all SELECTs, returning
+// ErrWriteOnReadPool before the statement reaches the database. Without it, a
+// write sent through ReadQuery fails on a replica with a confusing read-only
+// transaction error, or quietly succeeds on the primary in single pool mode.
+//
+// The check is a cheap prefix test, not a parser: it skips leading comments
+// and parentheses and looks for data-modifying CTEs, but won't catch side
+// effects hidden in functions (SELECT nextval(...), SELECT my_write_fn()).
+func WithReadOnlyGuard() ConnectOption {
+	return func(c *connectConfig) {
+		c.readOnlyGuard = true
+	}
+}
+
+// checkReadOnly returns ErrWriteOnReadPool, wrapped with the statement's
+// leading keyword, if sql isn't a read-only query.
+func checkReadOnly(sql string) error {
+	body := stripLeadingNoise(sql)
+	keyword := strings.ToUpper(leadingWord(body))
+	switch keyword {
+	case "SELECT":
+		return nil
+	case "WITH":
+		if !dataModifyingCTE.MatchString(body) {
+			return nil
+		}
+		return fmt.Errorf("%w: WITH contains a data-modifying statement", ErrWriteOnReadPool)
+	case "":
+		return fmt.Errorf("%w: no statement keyword found", ErrWriteOnReadPool)
+	}
+	return fmt.Errorf("%w: %s", ErrWriteOnReadPool, keyword)
+}
+
+// stripLeadingNoise drops whitespace, comments and opening parentheses in
+// front of the first keyword.
+func stripLeadingNoise(sql string) string {
+	for {
+		sql = strings.TrimLeft(sql, " \t\r\n(")
+		switch {
+		case strings.HasPrefix(sql, "--"):
+			end := strings.IndexByte(sql, '\n')
+			if end < 0 {
+				return ""
+			}
+			sql = sql[end+1:]
+		case strings.HasPrefix(sql, "/*"):
+			end := strings.Index(sql, "*/")
+			if end < 0 {
+				return ""
+			}
+			sql = sql[end+2:]
+		default:
+			return sql
+		}
+	}
+}
+
+func leadingWord(s string) string {
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_')
+	})
+	if end < 0 {
+		return s
+	}
+	return s[:end]
+}
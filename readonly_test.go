@@ -0,0 +1,107 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestCheckReadOnly(t *testing.T) {
+	allowed := []string{
+		"SELECT 1",
+		"  select * from users",
+		"(SELECT 1) UNION (SELECT 2)",
+		"-- list users\nSELECT * FROM users",
+		"/* report */ SELECT count(*) FROM orders",
+		"WITH recent AS (SELECT * FROM orders) SELECT * FROM recent",
+		"WITH x AS MATERIALIZED (SELECT 1) SELECT * FROM x",
+		"SELECT updated_at, deleted FROM audit",
+	}
+	for _, sql := range allowed {
+		if err := checkReadOnly(sql); err != nil {
+			t.Errorf("%q should be allowed, got %v", sql, err)
+		}
+	}
+
+	rejected := []string{
+		"INSERT INTO users (name) VALUES ($1)",
+		"update users set name = $1",
+		"DELETE FROM users",
+		"-- sneaky\nDELETE FROM users",
+		"/* x */ TRUNCATE users",
+		"WITH gone AS (DELETE FROM users RETURNING id) SELECT * FROM gone",
+		"with x as materialized ( update t set a = 1 returning a ) select 1",
+		"CREATE TABLE t (id int)",
+		"",
+		"-- only a comment",
+	}
+	for _, sql := range rejected {
+		if err := checkReadOnly(sql); !errors.Is(err, ErrWriteOnReadPool) {
+			t.Errorf("%q should be rejected with ErrWriteOnReadPool, got %v", sql, err)
+		}
+	}
+}
+
+func TestWithReadOnlyGuard(t *testing.T) {
+	cfg := newConnectConfig()
+	if cfg.readOnlyGuard {
+		t.Error("read-only guard should be off by default")
+	}
+	WithReadOnlyGuard()(cfg)
+	if !cfg.readOnlyGuard {
+		t.Error("WithReadOnlyGuard should enable the guard")
+	}
+}
+
+func TestReadQueryRejectsWriteWithGuard(t *testing.T) {
+	db := NewDB()
+	db.writePool = newLazyPool(t)
+	db.readPool = db.writePool
+	db.readOnlyGuard = true
+	ctx := context.Background()
+
+	var hookCalls int
+	db.hooks.addHook(BeforeOperation, func(context.Context, string, []interface{}, pgconn.CommandTag, error) error {
+		hookCalls++
+		return nil
+	})
+
+	rows, err := db.ReadQuery(ctx, "INSERT INTO users (name) VALUES ($1)", "alice")
+	if !errors.Is(err, ErrWriteOnReadPool) {
+		t.Fatalf("expected ErrWriteOnReadPool from ReadQuery, got %v", err)
+	}
+	if rows != nil {
+		t.Error("expected nil rows on rejection")
+	}
+
+	var id int
+	err = db.ReadQueryRow(ctx, "INSERT INTO users (name) VALUES ($1) RETURNING id", "alice").Scan(&id)
+	if !errors.Is(err, ErrWriteOnReadPool) {
+		t.Fatalf("expected ErrWriteOnReadPool from ReadQueryRow, got %v", err)
+	}
+
+	if hookCalls != 0 {
+		t.Errorf("rejected statements should not reach operation hooks, got %d calls", hookCalls)
+	}
+}
+
+func TestReadQueryGuardIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+	ctx := context.Background()
+
+	db := NewDB()
+	db.readPool = pool
+	db.writePool = pool
+
+	db.readOnlyGuard = true
+	if _, err := db.ReadQuery(ctx, "INSERT INTO readonly_guard_test VALUES (1)"); !errors.Is(err, ErrWriteOnReadPool) {
+		t.Errorf("expected ErrWriteOnReadPool, got %v", err)
+	}
+
+	var n int
+	if err := db.ReadQueryRow(ctx, "SELECT 41 + 1").Scan(&n); err != nil || n != 42 {
+		t.Errorf("SELECT should pass the guard, got %d, %v", n, err)
+	}
+}
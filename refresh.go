@@ -0,0 +1,70 @@
+package pgxkit
+
+import (
+	"time"
+)
+
+// minPoolRefreshInterval stops a burst of shutdown errors, one per pooled
+// connection, from resetting the pools over and over.
+const minPoolRefreshInterval = time.Second
+
+// WithRefreshOnAdminShutdown makes the DB reset its pools when an operation
+// fails with admin_shutdown (57P01) or crash_shutdown (57P02). During a
+// failover the old primary terminates every session at once, but the pool
+// only notices one dead connection at a time, as each is handed out and
+// fails. Resetting closes all idle connections and retires the checked-out
+// ones on release, so the next acquires dial the new primary straight away.
+//
+// The reset happens before the failed operation returns, so a caller that
+// retries it (RetryOperation, Retry; both treat these codes as retryable)
+// gets a fresh connection on the next attempt. Resets are limited to one per
+// second. Errors are seen where operation hooks see them, so QueryRow errors
+// that only surface from Scan don't trigger a reset.
+//
+// Example:
+//
+//	err := db.Connect(ctx, dsn, pgxkit.WithRefreshOnAdminShutdown())
+func WithRefreshOnAdminShutdown() ConnectOption {
+	return func(c *connectConfig) {
+		c.refreshOnAdminShutdown = true
+	}
+}
+
+// isServerShutdownError reports whether err means the server terminated the
+// session because it is shutting down.
+func isServerShutdownError(err error) bool {
+	if _, ok := pgErrorWithCode(err, "57P01"); ok { // admin_shutdown
+		return true
+	}
+	_, ok := pgErrorWithCode(err, "57P02") // crash_shutdown
+	return ok
+}
+
+// refreshOnShutdownError returns the operation error handler installed by
+// WithRefreshOnAdminShutdown. It resets the pools current at the time of the
+// error, so it keeps working after ReloadConfig swaps them.
+func (db *DB) refreshOnShutdownError() func(error) {
+	return func(err error) {
+		if !isServerShutdownError(err) {
+			return
+		}
+		now := time.Now().UnixNano()
+		last := db.lastPoolRefresh.Load()
+		if last != 0 && now-last < int64(minPoolRefreshInterval) {
+			return
+		}
+		if !db.lastPoolRefresh.CompareAndSwap(last, now) {
+			return
+		}
+		db.mu.RLock()
+		writePool, readPool := db.writePool, db.readPool
+		db.mu.RUnlock()
+		if writePool != nil {
+			writePool.Reset()
+		}
+		if readPool != nil && readPool != writePool {
+			readPool.Reset()
+		}
+		db.poolRefreshes.Add(1)
+	}
+}
@@ -0,0 +1,80 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsServerShutdownError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&pgconn.PgError{Code: "57P01"}, true},
+		{&pgconn.PgError{Code: "57P02"}, true},
+		{fmt.Errorf("query failed: %w", &pgconn.PgError{Code: "57P01"}), true},
+		{&pgconn.PgError{Code: "57P03"}, false},
+		{&pgconn.PgError{Code: "40P01"}, false},
+		{errors.New("connection reset"), false},
+	}
+	for _, tt := range tests {
+		if got := isServerShutdownError(tt.err); got != tt.want {
+			t.Errorf("isServerShutdownError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRefreshOnShutdownError(t *testing.T) {
+	db := NewDB()
+	pool := newLazyPool(t)
+	db.readPool, db.writePool = pool, pool
+	refresh := db.refreshOnShutdownError()
+
+	refresh(&pgconn.PgError{Code: "23505"})
+	if n := db.poolRefreshes.Load(); n != 0 {
+		t.Fatalf("unrelated errors should not refresh the pool, got %d refreshes", n)
+	}
+
+	refresh(&pgconn.PgError{Code: "57P01"})
+	refresh(&pgconn.PgError{Code: "57P01"})
+	if n := db.poolRefreshes.Load(); n != 1 {
+		t.Fatalf("expected a burst of shutdown errors to refresh once, got %d", n)
+	}
+
+	db.lastPoolRefresh.Store(time.Now().Add(-2 * minPoolRefreshInterval).UnixNano())
+	refresh(fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: "57P02"}))
+	if n := db.poolRefreshes.Load(); n != 2 {
+		t.Errorf("expected a refresh once the interval has passed, got %d", n)
+	}
+}
+
+func TestRefreshOnAdminShutdownFromOperation(t *testing.T) {
+	db := NewDB()
+	db.writePool = newLazyPool(t)
+	db.hooks.onOperationError = db.refreshOnShutdownError()
+	db.activeOps.Add(1)
+	shutdownErr := &pgconn.PgError{Code: "57P01", Message: "terminating connection due to administrator command"}
+	tx := &Tx{tx: &mockTx{execFunc: func(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+		return pgconn.CommandTag{}, shutdownErr
+	}}, db: db}
+
+	if _, err := tx.Exec(context.Background(), "UPDATE jobs SET done = true"); !errors.Is(err, shutdownErr) {
+		t.Fatalf("expected the shutdown error to be returned, got %v", err)
+	}
+	if n := db.poolRefreshes.Load(); n != 1 {
+		t.Errorf("expected the failed operation to refresh the pool, got %d refreshes", n)
+	}
+}
+
+func TestWithRefreshOnAdminShutdown(t *testing.T) {
+	cfg := newConnectConfig()
+	WithRefreshOnAdminShutdown()(cfg)
+	if !cfg.refreshOnAdminShutdown {
+		t.Error("expected WithRefreshOnAdminShutdown to enable the refresh")
+	}
+}
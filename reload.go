@@ -0,0 +1,173 @@
+package pgxkit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReloadConfig resizes the connection pools without dropping traffic, for
+// example on SIGHUP after the pool settings in a config file changed. It
+// opens new pools with the current settings plus opts and swaps them in.
+// Operations and transactions already started keep the old pools, which are
+// closed in the background once that work is done with them.
+//
+// Only the pool size and connection lifetime options take effect:
+// WithMaxConns, WithMinConns, WithReadMaxConns, WithReadMinConns,
+// WithWriteMaxConns, WithWriteMinConns, WithMaxConnLifetime and
+// WithMaxConnIdleTime. Everything else, including hooks, DSNs and
+// connection settings, is kept from Connect and other options are ignored.
+// Pools are opened the way Connect opened them, so WithPoolConstructor and
+// WithConnectRetry still apply.
+//
+// Example:
+//
+//	sighup := make(chan os.Signal, 1)
+//	signal.Notify(sighup, syscall.SIGHUP)
+//	for range sighup {
+//	    cfg := loadConfig()
+//	    if err := db.ReloadConfig(ctx, pgxkit.WithMaxConns(cfg.MaxConns)); err != nil {
+//	        log.Printf("pool reload failed: %v", err)
+//	    }
+//	}
+func (db *DB) ReloadConfig(ctx context.Context, opts ...ConnectOption) error {
+	db.reloadMu.Lock()
+	defer db.reloadMu.Unlock()
+
+	db.mu.RLock()
+	if db.shutdown {
+		db.mu.RUnlock()
+		return fmt.Errorf("database is shutting down")
+	}
+	readPool, writePool, open := db.readPool, db.writePool, db.openPool
+	db.mu.RUnlock()
+	if writePool == nil {
+		return fmt.Errorf("database is not connected")
+	}
+	if open == nil {
+		open = pgxpool.NewWithConfig
+	}
+
+	cfg := newConnectConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	split := readPool != writePool
+	var newRead, newWrite *pgxpool.Pool
+	if split {
+		readConfig := readPool.Config()
+		cfg.applyPoolSizing(readConfig, cfg.readMaxConns, cfg.readMinConns)
+		writeConfig := writePool.Config()
+		cfg.applyPoolSizing(writeConfig, cfg.writeMaxConns, cfg.writeMinConns)
+
+		var err error
+		newRead, err = open(ctx, readConfig)
+		if err != nil {
+			return fmt.Errorf("failed to create read pool: %w", err)
+		}
+		newWrite, err = open(ctx, writeConfig)
+		if err != nil {
+			newRead.Close()
+			return fmt.Errorf("failed to create write pool: %w", err)
+		}
+	} else {
+		config := writePool.Config()
+		cfg.applyPoolSizing(config, 0, 0)
+
+		pool, err := open(ctx, config)
+		if err != nil {
+			return fmt.Errorf("failed to create pool: %w", err)
+		}
+		newRead, newWrite = pool, pool
+	}
+
+	db.mu.Lock()
+	if db.shutdown {
+		db.mu.Unlock()
+		if split {
+			newRead.Close()
+		}
+		newWrite.Close()
+		return fmt.Errorf("database is shutting down")
+	}
+	readUsers, writeUsers := db.poolUsers[readPool], db.poolUsers[writePool]
+	db.readPool = newRead
+	db.writePool = newWrite
+	db.trackPools()
+	db.mu.Unlock()
+
+	// Operations that picked an old pool before the swap still run on it, so
+	// close it only once they are done with it.
+	go func() {
+		if split {
+			waitPoolUsers(readUsers)
+			readPool.Close()
+		}
+		waitPoolUsers(writeUsers)
+		writePool.Close()
+	}()
+	return nil
+}
+
+// trackPools starts counting the work on the current pools for
+// ReloadConfig, forgetting the pools they replaced. db.mu must be held for
+// writing.
+func (db *DB) trackPools() {
+	users := map[*pgxpool.Pool]*sync.WaitGroup{db.writePool: new(sync.WaitGroup)}
+	if db.readPool != db.writePool {
+		users[db.readPool] = new(sync.WaitGroup)
+	}
+	db.poolUsers = users
+}
+
+// holdPool keeps pool open across a ReloadConfig until releasePool is called
+// with the result. db.mu must be held, so the pool cannot be swapped out
+// between being picked and being held.
+func (db *DB) holdPool(pool *pgxpool.Pool) *sync.WaitGroup {
+	users := db.poolUsers[pool]
+	if users != nil {
+		users.Add(1)
+	}
+	return users
+}
+
+// releasePool ends a holdPool.
+func (db *DB) releasePool(users *sync.WaitGroup) {
+	if users != nil {
+		users.Done()
+	}
+}
+
+func waitPoolUsers(users *sync.WaitGroup) {
+	if users != nil {
+		users.Wait()
+	}
+}
+
+// startOp picks a pool and its role with pick and registers an operation on
+// it in one step under db.mu, so that Shutdown waits for the operation and
+// ReloadConfig keeps the pool open until endOp is called with users. pick
+// runs with db.mu held and must not take it again; writeTarget, readTarget
+// and queryTarget are written for this.
+func (db *DB) startOp(pick func() (*pgxpool.Pool, PoolSelector)) (pool *pgxpool.Pool, role PoolSelector, users *sync.WaitGroup, err error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.shutdown {
+		return nil, role, nil, fmt.Errorf("database is shutting down")
+	}
+	pool, role = pick()
+	if pool == nil {
+		return nil, role, nil, fmt.Errorf("database is not connected")
+	}
+	db.activeOps.Add(1)
+	return pool, role, db.holdPool(pool), nil
+}
+
+// endOp ends an operation begun by startOp.
+func (db *DB) endOp(users *sync.WaitGroup) {
+	db.releasePool(users)
+	db.activeOps.Done()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"net"
 	"strings"
 	"time"
@@ -13,10 +14,12 @@ import (
 )
 
 type retryConfig struct {
-	maxRetries int
-	baseDelay  time.Duration
-	maxDelay   time.Duration
-	multiplier float64
+	maxRetries     int
+	baseDelay      time.Duration
+	maxDelay       time.Duration
+	multiplier     float64
+	attemptTimeout time.Duration
+	backoff        BackoffStrategy
 }
 
 func defaultRetryConfig() *retryConfig {
@@ -67,8 +70,103 @@ func WithBackoffMultiplier(m float64) RetryOption {
 	}
 }
 
+// BackoffStrategy returns how long to wait before retry number attempt
+// (starting at 1), given the configured base and maximum delays. Results
+// above max are capped at max, and negative results are treated as 0.
+type BackoffStrategy func(attempt int, base, max time.Duration) time.Duration
+
+// WithBackoffStrategy replaces the default exponential backoff with fn,
+// for example one of ConstantBackoff, LinearBackoff or
+// DecorrelatedJitterBackoff. WithBackoffMultiplier has no effect once a
+// strategy is set.
+func WithBackoffStrategy(fn BackoffStrategy) RetryOption {
+	return func(c *retryConfig) {
+		if fn != nil {
+			c.backoff = fn
+		}
+	}
+}
+
+// ConstantBackoff waits base before every retry.
+func ConstantBackoff(attempt int, base, max time.Duration) time.Duration {
+	return base
+}
+
+// LinearBackoff waits base, 2*base, 3*base and so on, up to max.
+func LinearBackoff(attempt int, base, max time.Duration) time.Duration {
+	if attempt <= 0 {
+		return base
+	}
+	if base > max/time.Duration(attempt) {
+		return max
+	}
+	return base * time.Duration(attempt)
+}
+
+// DecorrelatedJitterBackoff waits a random time between base and three times
+// the previous retry's upper bound, up to max: [base, 3*base] before the
+// first retry, [base, 9*base] before the second, and so on. It is a
+// stateless form of decorrelated jitter, so concurrent retries spread out
+// instead of waking in lockstep.
+func DecorrelatedJitterBackoff(attempt int, base, max time.Duration) time.Duration {
+	upper := base
+	for i := 0; i < attempt && upper < max; i++ {
+		if upper > max/3 {
+			upper = max
+		} else {
+			upper *= 3
+		}
+	}
+	if upper > max {
+		upper = max
+	}
+	if upper <= base {
+		return base
+	}
+	return base + rand.N(upper-base+1)
+}
+
+// nextDelay returns the wait before retry number attempt under a custom
+// strategy, clamped to [0, cfg.maxDelay].
+func (c *retryConfig) nextDelay(attempt int) time.Duration {
+	d := c.backoff(attempt, c.baseDelay, c.maxDelay)
+	if d < 0 {
+		return 0
+	}
+	if d > c.maxDelay {
+		return c.maxDelay
+	}
+	return d
+}
+
+// WithAttemptTimeout bounds each attempt to d. An attempt that runs out of
+// time is retried like any other transient failure, as long as the caller's
+// context is still live. Each attempt gets the smaller of d and what remains
+// of the caller's deadline, so a late attempt is never promised more time
+// than the outer context will allow.
+func WithAttemptTimeout(d time.Duration) RetryOption {
+	return func(c *retryConfig) {
+		if d > 0 {
+			c.attemptTimeout = d
+		}
+	}
+}
+
+// attemptBudget returns the timeout for the next attempt: d, truncated to
+// the time left before ctx's deadline.
+func attemptBudget(ctx context.Context, d time.Duration) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining < d {
+			return remaining
+		}
+	}
+	return d
+}
+
 // Retry executes a generic operation with configurable retry logic.
-// It uses exponential backoff to avoid thundering herd problems.
+// It uses exponential backoff to avoid thundering herd problems, unless
+// WithBackoffStrategy chooses another. After a too_many_connections error
+// (see IsTooManyConnections) it waits the full max delay instead.
 func Retry[T any](ctx context.Context, fn func(context.Context) (T, error), opts ...RetryOption) (T, error) {
 	cfg := defaultRetryConfig()
 	for _, opt := range opts {
@@ -93,10 +191,19 @@ func Retry[T any](ctx context.Context, fn func(context.Context) (T, error), opts
 		}
 
 		if attempt > 0 {
+			if cfg.backoff != nil {
+				delay = cfg.nextDelay(attempt)
+			}
+			wait := delay
+			if IsTooManyConnections(lastErr) {
+				// The server is out of connection slots; retrying soon
+				// only adds to the pile-up.
+				wait = cfg.maxDelay
+			}
 			select {
 			case <-ctx.Done():
 				return zero, ctx.Err()
-			case <-time.After(delay):
+			case <-time.After(wait):
 			}
 
 			// Calculate next delay with overflow protection
@@ -108,7 +215,7 @@ func Retry[T any](ctx context.Context, fn func(context.Context) (T, error), opts
 			}
 		}
 
-		result, err := fn(ctx)
+		result, err := runAttempt(ctx, cfg.attemptTimeout, fn)
 		if err == nil {
 			return result, nil
 		}
@@ -123,6 +230,36 @@ func Retry[T any](ctx context.Context, fn func(context.Context) (T, error), opts
 	return zero, fmt.Errorf("operation failed after %d attempts, last error: %w", cfg.maxRetries+1, lastErr)
 }
 
+// runAttempt calls fn once, under the per-attempt timeout if one is set. An
+// attempt timeout is reported as a retryable error while ctx itself is live.
+func runAttempt[T any](ctx context.Context, timeout time.Duration, fn func(context.Context) (T, error)) (T, error) {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, attemptBudget(ctx, timeout))
+	defer cancel()
+	result, err := fn(attemptCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return result, &attemptTimeoutError{timeout: timeout, err: err}
+	}
+	return result, err
+}
+
+// attemptTimeoutError marks an attempt cut short by WithAttemptTimeout.
+// IsRetryableError treats it as retryable.
+type attemptTimeoutError struct {
+	timeout time.Duration
+	err     error
+}
+
+func (e *attemptTimeoutError) Error() string {
+	return fmt.Sprintf("attempt timed out after %v: %v", e.timeout, e.err)
+}
+
+func (e *attemptTimeoutError) Unwrap() error {
+	return e.err
+}
+
 // RetryOperation executes an operation with configurable retry logic.
 // It uses exponential backoff to avoid thundering herd problems.
 //
@@ -144,6 +281,11 @@ func IsRetryableError(err error) bool {
 		return false
 	}
 
+	var attemptErr *attemptTimeoutError
+	if errors.As(err, &attemptErr) {
+		return true
+	}
+
 	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 		return false
 	}
@@ -156,7 +298,8 @@ func IsRetryableError(err error) bool {
 			"08006", // connection_failure
 			"57P01", // admin_shutdown
 			"57P02", // crash_shutdown
-			"57P03": // cannot_connect_now
+			"57P03", // cannot_connect_now
+			"53300": // too_many_connections; Retry backs off to the max delay
 			return true
 		case "40001", // serialization_failure
 			"40P01": // deadlock_detected
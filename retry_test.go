@@ -368,6 +368,7 @@ func TestIsRetryableError_PostgreSQLRetryableCodes(t *testing.T) {
 		{"57P01", "admin_shutdown"},
 		{"57P02", "crash_shutdown"},
 		{"57P03", "cannot_connect_now"},
+		{"53300", "too_many_connections"},
 		{"40001", "serialization_failure"},
 		{"40P01", "deadlock_detected"},
 	}
@@ -614,3 +615,194 @@ func TestRetryOperation_ContextDeadlineExceededNoRetry(t *testing.T) {
 		t.Errorf("expected 1 call for context.DeadlineExceeded, got %d", callCount)
 	}
 }
+
+func TestWithAttemptTimeout(t *testing.T) {
+	cfg := defaultRetryConfig()
+	if cfg.attemptTimeout != 0 {
+		t.Errorf("expected no attempt timeout by default, got %v", cfg.attemptTimeout)
+	}
+	WithAttemptTimeout(time.Second)(cfg)
+	WithAttemptTimeout(0)(cfg)
+	WithAttemptTimeout(-time.Second)(cfg)
+	if cfg.attemptTimeout != time.Second {
+		t.Errorf("non-positive values should be ignored, got %v", cfg.attemptTimeout)
+	}
+}
+
+func TestAttemptBudget_TruncatedToOuterDeadline(t *testing.T) {
+	if got := attemptBudget(context.Background(), time.Second); got != time.Second {
+		t.Errorf("without an outer deadline expected 1s, got %v", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	got := attemptBudget(ctx, time.Second)
+	if got <= 0 || got > 50*time.Millisecond {
+		t.Errorf("expected budget truncated to the outer 50ms, got %v", got)
+	}
+	if got := attemptBudget(ctx, 10*time.Millisecond); got != 10*time.Millisecond {
+		t.Errorf("expected the shorter attempt timeout to win, got %v", got)
+	}
+}
+
+func TestRetry_AttemptDeadlineNeverExceedsOuter(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 80*time.Millisecond)
+	defer cancel()
+	outer, _ := ctx.Deadline()
+
+	var deadlines []time.Time
+	err := RetryOperation(ctx, func(ctx context.Context) error {
+		d, ok := ctx.Deadline()
+		if !ok {
+			t.Fatal("attempt context should carry a deadline")
+		}
+		deadlines = append(deadlines, d)
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithAttemptTimeout(30*time.Millisecond), WithMaxRetries(10), WithBaseDelay(time.Millisecond))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the outer deadline to end retries, got %v", err)
+	}
+	if len(deadlines) < 2 {
+		t.Fatalf("expected timed-out attempts to be retried, got %d attempts", len(deadlines))
+	}
+	for i, d := range deadlines {
+		if d.After(outer) {
+			t.Errorf("attempt %d deadline %v is past the outer deadline %v", i, d, outer)
+		}
+	}
+	if last := deadlines[len(deadlines)-1]; !last.Equal(outer) {
+		t.Errorf("last attempt should be truncated to the outer deadline, got %v want %v", last, outer)
+	}
+}
+
+func TestRetry_AttemptTimeoutIsRetried(t *testing.T) {
+	var calls int32
+	err := RetryOperation(context.Background(), func(ctx context.Context) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}, WithAttemptTimeout(10*time.Millisecond), WithBaseDelay(time.Millisecond))
+
+	if err != nil {
+		t.Errorf("expected success after a timed-out attempt, got %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestIsRetryableError_AttemptTimeout(t *testing.T) {
+	err := &attemptTimeoutError{timeout: time.Second, err: context.DeadlineExceeded}
+	if !IsRetryableError(err) {
+		t.Error("attempt timeouts should be retryable")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("attempt timeout should unwrap to the attempt's error")
+	}
+}
+
+func TestConstantBackoff(t *testing.T) {
+	for attempt := 1; attempt <= 5; attempt++ {
+		if d := ConstantBackoff(attempt, 50*time.Millisecond, time.Second); d != 50*time.Millisecond {
+			t.Errorf("attempt %d: expected 50ms, got %v", attempt, d)
+		}
+	}
+}
+
+func TestLinearBackoff(t *testing.T) {
+	base, max := 100*time.Millisecond, 350*time.Millisecond
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, max, max}
+	for i, w := range want {
+		if d := LinearBackoff(i+1, base, max); d != w {
+			t.Errorf("attempt %d: expected %v, got %v", i+1, w, d)
+		}
+	}
+	if d := LinearBackoff(1<<40, time.Hour, 2*time.Hour); d != 2*time.Hour {
+		t.Errorf("expected a huge attempt to cap at max without overflow, got %v", d)
+	}
+}
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+	base, max := 10*time.Millisecond, 200*time.Millisecond
+	uppers := []time.Duration{30 * time.Millisecond, 90 * time.Millisecond, max, max}
+	for i, upper := range uppers {
+		for n := 0; n < 100; n++ {
+			d := DecorrelatedJitterBackoff(i+1, base, max)
+			if d < base || d > upper {
+				t.Fatalf("attempt %d: delay %v outside [%v, %v]", i+1, d, base, upper)
+			}
+		}
+	}
+
+	seen := map[time.Duration]bool{}
+	for n := 0; n < 50; n++ {
+		seen[DecorrelatedJitterBackoff(3, base, max)] = true
+	}
+	if len(seen) < 2 {
+		t.Error("expected jittered delays to vary")
+	}
+}
+
+func TestWithBackoffStrategy(t *testing.T) {
+	cfg := defaultRetryConfig()
+	WithBackoffStrategy(nil)(cfg)
+	if cfg.backoff != nil {
+		t.Error("a nil strategy should be ignored")
+	}
+
+	cfg.backoff = func(attempt int, base, max time.Duration) time.Duration {
+		return time.Duration(attempt) * time.Hour
+	}
+	if d := cfg.nextDelay(1); d != cfg.maxDelay {
+		t.Errorf("expected delay capped at maxDelay, got %v", d)
+	}
+	cfg.backoff = func(int, time.Duration, time.Duration) time.Duration { return -time.Second }
+	if d := cfg.nextDelay(1); d != 0 {
+		t.Errorf("expected a negative delay to become 0, got %v", d)
+	}
+}
+
+func TestRetryOperation_UsesBackoffStrategy(t *testing.T) {
+	var attempts []int
+	var calls int
+	err := RetryOperation(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls < 4 {
+			return &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+		}
+		return nil
+	}, WithMaxRetries(5), WithBaseDelay(time.Millisecond), WithBackoffStrategy(func(attempt int, base, max time.Duration) time.Duration {
+		attempts = append(attempts, attempt)
+		return base
+	}))
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if len(attempts) != 3 || attempts[0] != 1 || attempts[1] != 2 || attempts[2] != 3 {
+		t.Errorf("expected the strategy to be asked for retries 1, 2, 3, got %v", attempts)
+	}
+}
+
+func TestRetryOperation_TooManyConnectionsWaitsMaxDelay(t *testing.T) {
+	var times []time.Time
+	err := RetryOperation(context.Background(), func(ctx context.Context) error {
+		times = append(times, time.Now())
+		if len(times) < 2 {
+			return &pgconn.PgError{Code: "53300", Message: "sorry, too many clients already"}
+		}
+		return nil
+	}, WithMaxRetries(2), WithBaseDelay(time.Millisecond), WithMaxDelay(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if len(times) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(times))
+	}
+	if gap := times[1].Sub(times[0]); gap < 100*time.Millisecond {
+		t.Errorf("expected too_many_connections to back off to the max delay, retried after %v", gap)
+	}
+}
@@ -0,0 +1,114 @@
+package pgxkit
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// lockingClause matches SELECT ... FOR UPDATE / NO KEY UPDATE / SHARE /
+	// KEY SHARE, which take row locks and so must run on the primary.
+	lockingClause = regexp.MustCompile(`(?i)\bFOR\s+(?:NO\s+KEY\s+UPDATE|UPDATE|KEY\s+SHARE|SHARE)\b`)
+	// selectInto matches SELECT ... INTO, which creates a table.
+	selectInto = regexp.MustCompile(`(?i)\bINTO\b`)
+)
+
+type forceWriteKey struct{}
+
+// WithAutoReadRouting makes Query and QueryRow, and their named variants,
+// send plain reads to the read pool, as if ReadQuery and ReadQueryRow had been
+// called. Everything else stays on the write pool, including SELECT ... FOR
+// UPDATE/SHARE, SELECT ... INTO, WITH queries containing
+// INSERT/UPDATE/DELETE/MERGE, and EXPLAIN, since EXPLAIN ANALYZE runs its
+// statement. Exec and ExecNamed always use the write pool.
+//
+// Routing is off by default, so Query keeps its read-your-writes guarantee.
+// With it on, a read issued right after a write may hit a lagging replica;
+// wrap such calls with WithForceWrite. The classifier is the same prefix check
+// WithReadOnlyGuard uses, so reads with side effects hidden in functions
+// (SELECT nextval(...)) need WithForceWrite as well.
+func WithAutoReadRouting(enabled bool) ConnectOption {
+	return func(c *connectConfig) {
+		c.autoReadRouting = enabled
+	}
+}
+
+// WithForceWrite returns a context that keeps Query and QueryRow on the write
+// pool under WithAutoReadRouting:
+//
+//	_, err := db.Exec(ctx, "UPDATE users SET name = $1 WHERE id = $2", name, id)
+//	...
+//	err = db.QueryRow(pgxkit.WithForceWrite(ctx), "SELECT name FROM users WHERE id = $1", id).Scan(&got)
+func WithForceWrite(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceWriteKey{}, true)
+}
+
+// isAutoReadable reports whether sql is safe to route to the read pool.
+func isAutoReadable(sql string) bool {
+	if checkReadOnly(sql) != nil {
+		return false
+	}
+	body := stripLeadingNoise(sql)
+	return !lockingClause.MatchString(body) && !selectInto.MatchString(body)
+}
+
+// queryTarget picks the pool and role for Query, QueryRow and their named
+// variants. db.mu must be held.
+func (db *DB) queryTarget(ctx context.Context, sql string) (*pgxpool.Pool, PoolSelector) {
+	if !db.autoReadRouting || db.readPool == nil {
+		return db.writeTarget()
+	}
+	if force, _ := ctx.Value(forceWriteKey{}).(bool); force {
+		return db.writeTarget()
+	}
+	if !isAutoReadable(sql) {
+		return db.writeTarget()
+	}
+	return db.readTarget()
+}
+
+// writeTarget returns the write pool. db.mu must be held: operations pick
+// their pool through startOp, because ReloadConfig can swap the pools.
+func (db *DB) writeTarget() (*pgxpool.Pool, PoolSelector) {
+	return db.writePool, PoolWrite
+}
+
+type poolRoleKey struct{}
+
+// withPoolRole records role, as picked by startOp, in ctx for
+// PoolFromContext. Write operations leave ctx alone, since that is the
+// default.
+func withPoolRole(ctx context.Context, role PoolSelector) context.Context {
+	if role == PoolRead {
+		return context.WithValue(ctx, poolRoleKey{}, PoolRead)
+	}
+	return ctx
+}
+
+// PoolFromContext reports which pool role the current operation asked for,
+// for hooks that treat reads and writes differently, such as a replica-lag
+// guard or metrics labeled by pool:
+//
+//	pgxkit.WithAfterOperation(func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+//	    pool := "write"
+//	    if pgxkit.PoolFromContext(ctx) == pgxkit.PoolRead {
+//	        pool = "read"
+//	    }
+//	    queryCount.WithLabelValues(pool).Inc()
+//	    return nil
+//	})
+//
+// It reports the role the operation asked for: PoolRead for ReadQuery,
+// ReadQueryRow and reads sent there by WithAutoReadRouting, and PoolWrite for
+// transactions, batches and everything else that runs on the write pool. As
+// with PingPool, a read is PoolRead even when it runs on the write pool, in
+// single pool mode or while WithReadFallbackToWrite is in effect. Statements
+// reported by WithHookTracer are PoolWrite, whichever pool ran them.
+func PoolFromContext(ctx context.Context) PoolSelector {
+	if role, ok := ctx.Value(poolRoleKey{}).(PoolSelector); ok {
+		return role
+	}
+	return PoolWrite
+}
@@ -0,0 +1,209 @@
+package pgxkit
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsAutoReadable(t *testing.T) {
+	reads := []string{
+		"SELECT * FROM users",
+		"  select id from users where id = $1",
+		"/* dashboard */ SELECT count(*) FROM orders",
+		"WITH recent AS (SELECT * FROM orders) SELECT * FROM recent",
+		"SELECT updated_at, for_sale FROM products",
+	}
+	for _, sql := range reads {
+		if !isAutoReadable(sql) {
+			t.Errorf("%q should route to the read pool", sql)
+		}
+	}
+
+	writes := []string{
+		"INSERT INTO users (name) VALUES ($1)",
+		"UPDATE users SET name = $1",
+		"WITH moved AS (DELETE FROM queue RETURNING *) INSERT INTO done SELECT * FROM moved",
+		"WITH x AS (INSERT INTO t VALUES (1) RETURNING id) SELECT * FROM x",
+		"SELECT * FROM jobs WHERE id = $1 FOR UPDATE",
+		"SELECT * FROM jobs FOR NO KEY UPDATE SKIP LOCKED",
+		"select * from accounts for share",
+		"SELECT * FROM jobs FOR KEY SHARE",
+		"SELECT * INTO archive FROM users",
+		"EXPLAIN SELECT * FROM users",
+		"EXPLAIN ANALYZE DELETE FROM users",
+		"SHOW search_path",
+		"",
+	}
+	for _, sql := range writes {
+		if isAutoReadable(sql) {
+			t.Errorf("%q should stay on the write pool", sql)
+		}
+	}
+}
+
+func TestQueryTarget(t *testing.T) {
+	db := NewDB()
+	db.writePool = newLazyPool(t)
+	db.readPool = newLazyPool(t)
+	ctx := context.Background()
+
+	if pool, _ := db.queryTarget(ctx, "SELECT 1"); pool != db.writePool {
+		t.Error("without auto routing, Query should use the write pool")
+	}
+
+	db.autoReadRouting = true
+	if pool, _ := db.queryTarget(ctx, "SELECT 1"); pool != db.readPool {
+		t.Error("a plain SELECT should route to the read pool")
+	}
+	if pool, _ := db.queryTarget(ctx, "SELECT 1 FOR UPDATE"); pool != db.writePool {
+		t.Error("a locking SELECT should use the write pool")
+	}
+	if pool, _ := db.queryTarget(WithForceWrite(ctx), "SELECT 1"); pool != db.writePool {
+		t.Error("WithForceWrite should keep reads on the write pool")
+	}
+
+	db.readFallbackToWrite = true
+	db.readUnhealthy.Store(true)
+	if pool, _ := db.queryTarget(ctx, "SELECT 1"); pool != db.writePool {
+		t.Error("auto routing should honor read fallback")
+	}
+}
+
+func TestWithAutoReadRouting(t *testing.T) {
+	cfg := newConnectConfig()
+	if cfg.autoReadRouting {
+		t.Error("auto read routing should be off by default")
+	}
+	WithAutoReadRouting(true)(cfg)
+	if !cfg.autoReadRouting {
+		t.Error("WithAutoReadRouting(true) should enable routing")
+	}
+	WithAutoReadRouting(false)(cfg)
+	if cfg.autoReadRouting {
+		t.Error("WithAutoReadRouting(false) should disable routing")
+	}
+}
+
+func TestAutoReadRoutingIntegration(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+	ctx := context.Background()
+
+	db := NewDB()
+	if err := db.ConnectReadWrite(ctx, dsn, dsn, WithAutoReadRouting(true)); err != nil {
+		t.Fatalf("ConnectReadWrite failed: %v", err)
+	}
+	defer db.Shutdown(ctx)
+
+	acquires := func() (read, write int64) {
+		return db.readPool.Stat().AcquireCount(), db.writePool.Stat().AcquireCount()
+	}
+
+	read0, write0 := acquires()
+	var n int
+	if err := db.QueryRow(ctx, "SELECT 1").Scan(&n); err != nil {
+		t.Fatalf("QueryRow failed: %v", err)
+	}
+	read1, write1 := acquires()
+	if read1 != read0+1 || write1 != write0 {
+		t.Errorf("SELECT should use the read pool: read %d→%d, write %d→%d", read0, read1, write0, write1)
+	}
+
+	rows, err := db.Query(ctx, "SELECT 1 FOR UPDATE")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	rows.Close()
+	if err := db.QueryRow(WithForceWrite(ctx), "SELECT 1").Scan(&n); err != nil {
+		t.Fatalf("QueryRow failed: %v", err)
+	}
+	read2, write2 := acquires()
+	if read2 != read1 || write2 != write1+2 {
+		t.Errorf("locking and forced reads should use the write pool: read %d→%d, write %d→%d", read1, read2, write1, write2)
+	}
+}
+
+// poolRoleDB returns a DB with distinct lazy read and write pools and the
+// pool role each BeforeOperation hook saw.
+func poolRoleDB(t *testing.T) (*DB, *[]PoolSelector) {
+	db := NewDB()
+	db.writePool = newLazyPool(t)
+	db.readPool = newLazyPool(t)
+	var roles []PoolSelector
+	db.hooks.addHook(BeforeOperation, func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+		roles = append(roles, PoolFromContext(ctx))
+		return nil
+	})
+	return db, &roles
+}
+
+func TestPoolFromContextInHooks(t *testing.T) {
+	db, roles := poolRoleDB(t)
+	ctx := context.Background()
+
+	// The lazy pools can't connect; the hooks fire before that matters.
+	_, _ = db.ReadQuery(ctx, "SELECT 1")
+	_ = db.ReadQueryRow(ctx, "SELECT 1").Scan(new(int))
+	_, _ = db.Query(ctx, "SELECT 1")
+	_ = db.QueryRow(ctx, "SELECT 1").Scan(new(int))
+	_, _ = db.Exec(ctx, "DELETE FROM t")
+
+	want := []PoolSelector{PoolRead, PoolRead, PoolWrite, PoolWrite, PoolWrite}
+	if !reflect.DeepEqual(*roles, want) {
+		t.Errorf("expected roles %v, got %v", want, *roles)
+	}
+}
+
+func TestPoolFromContextFollowsRouting(t *testing.T) {
+	db, roles := poolRoleDB(t)
+	ctx := context.Background()
+
+	db.autoReadRouting = true
+	_, _ = db.Query(ctx, "SELECT 1")
+	db.readFallbackToWrite = true
+	db.readUnhealthy.Store(true)
+	_, _ = db.ReadQuery(ctx, "SELECT 1")
+
+	db.readPool = db.writePool
+	db.readUnhealthy.Store(false)
+	_, _ = db.ReadQuery(ctx, "SELECT 1")
+
+	// Reads report the role they asked for, even when they run on the write
+	// pool.
+	want := []PoolSelector{PoolRead, PoolRead, PoolRead}
+	if !reflect.DeepEqual(*roles, want) {
+		t.Errorf("expected auto-routed read, fallback and single pool roles %v, got %v", want, *roles)
+	}
+}
+
+func TestNamedQueriesFollowRouting(t *testing.T) {
+	db, roles := poolRoleDB(t)
+	ctx := context.Background()
+	args := pgx.NamedArgs{"id": 1}
+
+	_, _ = db.QueryNamed(ctx, "SELECT @id", args)
+	db.autoReadRouting = true
+	_, _ = db.QueryNamed(ctx, "SELECT @id", args)
+	_ = db.QueryRowNamed(ctx, "SELECT @id", args).Scan(new(int))
+	_, _ = db.QueryNamed(ctx, "SELECT @id FOR UPDATE", args)
+	_ = db.QueryRowNamed(WithForceWrite(ctx), "SELECT @id", args).Scan(new(int))
+	_, _ = db.ExecNamed(ctx, "SELECT @id", args)
+
+	want := []PoolSelector{PoolWrite, PoolRead, PoolRead, PoolWrite, PoolWrite, PoolWrite}
+	if !reflect.DeepEqual(*roles, want) {
+		t.Errorf("expected named query roles %v, got %v", want, *roles)
+	}
+}
+
+func TestPoolFromContextDefault(t *testing.T) {
+	if PoolFromContext(context.Background()) != PoolWrite {
+		t.Error("expected PoolWrite outside a read operation")
+	}
+}
@@ -0,0 +1,128 @@
+package pgxkit
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// maxLoggedArgLength is the longest string SanitizeArgs passes through.
+// Longer values tend to be tokens, documents or payloads rather than IDs.
+const maxLoggedArgLength = 64
+
+var (
+	emailPattern = regexp.MustCompile(`[^@\s]+@[^@\s]+\.[A-Za-z]{2,}`)
+	// cardPattern matches 13-19 digits, optionally grouped by spaces or
+	// dashes. Candidates are confirmed with a Luhn check.
+	cardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+)
+
+// ArgRedactor replaces a query argument before it is logged. Return
+// (replacement, true) to take over, or (nil, false) to fall through to the
+// next redactor and finally the defaults.
+type ArgRedactor func(v any) (any, bool)
+
+// SanitizeArgs returns a copy of args that is safer to log from a hook:
+//
+//   - strings containing an email address become "[redacted email]"
+//   - strings containing a card-number-shaped digit run that passes the Luhn
+//     check become "[redacted card]"
+//   - strings longer than 64 characters become "[redacted string, N chars]"
+//   - []byte becomes "[N bytes]"
+//
+// Everything else, including numbers, booleans, times and UUIDs, passes
+// through unchanged. *string is treated like the string it points to. The
+// input slice is not modified.
+//
+// Example:
+//
+//	pgxkit.WithAfterOperation(func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+//	    slog.InfoContext(ctx, "query", "sql", sql, "args", pgxkit.SanitizeArgs(args))
+//	    return nil
+//	})
+func SanitizeArgs(args []interface{}) []interface{} {
+	return SanitizeArgsWith(args)
+}
+
+// SanitizeArgsWith is SanitizeArgs with custom redactors, which run in order
+// before the defaults. Use it to mask domain values the defaults can't
+// recognize:
+//
+//	sanitized := pgxkit.SanitizeArgsWith(args, func(v any) (any, bool) {
+//	    if s, ok := v.(string); ok && strings.HasPrefix(s, "sk_") {
+//	        return "[redacted api key]", true
+//	    }
+//	    return nil, false
+//	})
+func SanitizeArgsWith(args []interface{}, redactors ...ArgRedactor) []interface{} {
+	if args == nil {
+		return nil
+	}
+	result := make([]interface{}, len(args))
+	for i, arg := range args {
+		result[i] = sanitizeArg(arg, redactors)
+	}
+	return result
+}
+
+func sanitizeArg(v any, redactors []ArgRedactor) any {
+	for _, redact := range redactors {
+		if replacement, ok := redact(v); ok {
+			return replacement
+		}
+	}
+	switch val := v.(type) {
+	case string:
+		return sanitizeString(val)
+	case *string:
+		if val == nil {
+			return v
+		}
+		if sanitized := sanitizeString(*val); sanitized != *val {
+			return sanitized
+		}
+		return v
+	case []byte:
+		if val == nil {
+			return v
+		}
+		return fmt.Sprintf("[%d bytes]", len(val))
+	}
+	return v
+}
+
+func sanitizeString(s string) string {
+	if emailPattern.MatchString(s) {
+		return "[redacted email]"
+	}
+	for _, candidate := range cardPattern.FindAllString(s, -1) {
+		if luhnValid(candidate) {
+			return "[redacted card]"
+		}
+	}
+	if n := len([]rune(s)); n > maxLoggedArgLength {
+		return fmt.Sprintf("[redacted string, %d chars]", n)
+	}
+	return s
+}
+
+// luhnValid reports whether the digits in s pass the Luhn checksum used by
+// payment card numbers. Separators are ignored.
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n > 0 && sum%10 == 0
+}
@@ -0,0 +1,106 @@
+package pgxkit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSanitizeArgs_RedactsSensitive(t *testing.T) {
+	long := strings.Repeat("x", 65)
+	email := "alice@example.com"
+	tests := []struct {
+		name string
+		in   any
+		want any
+	}{
+		{"email", "alice@example.com", "[redacted email]"},
+		{"email in text", "contact bob.smith+tag@mail.example.org please", "[redacted email]"},
+		{"card", "4111111111111111", "[redacted card]"},
+		{"grouped card", "4111 1111 1111 1111", "[redacted card]"},
+		{"dashed card", "5500-0000-0000-0004", "[redacted card]"},
+		{"long string", long, "[redacted string, 65 chars]"},
+		{"string pointer", &email, "[redacted email]"},
+		{"bytes", []byte("secret"), "[6 bytes]"},
+	}
+	for _, tt := range tests {
+		got := SanitizeArgs([]interface{}{tt.in})
+		if got[0] != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got[0], tt.want)
+		}
+	}
+}
+
+func TestSanitizeArgs_PassesThroughSafe(t *testing.T) {
+	id := uuid.New()
+	now := time.Now()
+	short := "active"
+	var nilString *string
+	in := []interface{}{
+		42, int64(7), 3.14, true, nil, id, now,
+		"active",
+		strings.Repeat("y", 64),
+		"1234567890123", // 13 digits but fails Luhn
+		"order 4111111111111112",
+		&short,
+		nilString,
+	}
+	got := SanitizeArgs(in)
+	if len(got) != len(in) {
+		t.Fatalf("expected %d args, got %d", len(in), len(got))
+	}
+	for i := range in {
+		if got[i] != in[i] {
+			t.Errorf("arg %d: %v should pass through unchanged, got %v", i, in[i], got[i])
+		}
+	}
+}
+
+func TestSanitizeArgs_DoesNotModifyInput(t *testing.T) {
+	in := []interface{}{"alice@example.com"}
+	_ = SanitizeArgs(in)
+	if in[0] != "alice@example.com" {
+		t.Errorf("input slice was modified: %v", in)
+	}
+	if SanitizeArgs(nil) != nil {
+		t.Error("nil args should stay nil")
+	}
+}
+
+func TestSanitizeArgsWith_CustomRedactorRunsFirst(t *testing.T) {
+	apiKey := func(v any) (any, bool) {
+		if s, ok := v.(string); ok && strings.HasPrefix(s, "sk_") {
+			return "[redacted api key]", true
+		}
+		return nil, false
+	}
+	everything := func(v any) (any, bool) { return "[hidden]", true }
+
+	got := SanitizeArgsWith([]interface{}{"sk_live_abc", "alice@example.com", 5}, apiKey)
+	want := []interface{}{"[redacted api key]", "[redacted email]", 5}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("arg %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	got = SanitizeArgsWith([]interface{}{"sk_live_abc", 5}, apiKey, everything)
+	if got[0] != "[redacted api key]" || got[1] != "[hidden]" {
+		t.Errorf("redactors should run in order, got %v", got)
+	}
+}
+
+func TestLuhnValid(t *testing.T) {
+	for _, s := range []string{"4111111111111111", "4111 1111 1111 1111", "79927398713"} {
+		if !luhnValid(s) {
+			t.Errorf("%q should pass Luhn", s)
+		}
+	}
+	for _, s := range []string{"4111111111111112", "79927398710", ""} {
+		if luhnValid(s) {
+			t.Errorf("%q should fail Luhn", s)
+		}
+	}
+}
@@ -0,0 +1,389 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// QueryMaps executes a query using the write pool and returns every row as a
+// column-name to value map. It is intended for admin and debug tooling that
+// runs ad-hoc queries whose shape isn't known at compile time; prefer typed
+// scanning everywhere else.
+//
+// Values are decoded by pgx and then passed through DefaultValueMapper, so uuid
+// columns become uuid.UUID and numeric columns become float64. NULLs are nil.
+// If a query returns duplicate column names, the last one wins. For custom
+// typing, use pgx.CollectRows with RowToMapWith.
+//
+// Example:
+//
+//	rows, err := db.QueryMaps(ctx, "SELECT id, email, created_at FROM users LIMIT 10")
+//	for _, row := range rows {
+//	    fmt.Println(row["email"])
+//	}
+func (db *DB) QueryMaps(ctx context.Context, sql string, args ...interface{}) ([]map[string]any, error) {
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return collectMaps(rows)
+}
+
+// ErrMultipleRows is returned by QueryExactlyOne when the query matches more
+// than one row.
+var ErrMultipleRows = errors.New("query returned more than one row")
+
+// QueryExactlyOne is QueryRow for lookups that must match exactly one row.
+// QueryRow silently uses the first of several matches, which hides
+// data-integrity bugs such as a missing unique constraint; QueryExactlyOne
+// returns ErrMultipleRows instead, and a *NotFoundError when nothing matches.
+// dest scans the row. It runs on the write pool.
+//
+// Example:
+//
+//	var user User
+//	err := db.QueryExactlyOne(ctx, func(row pgx.Row) error {
+//	    return row.Scan(&user.ID, &user.Email)
+//	}, "SELECT id, email FROM users WHERE email = $1", email)
+//	if errors.Is(err, pgxkit.ErrMultipleRows) {
+//	    return fmt.Errorf("duplicate users for %s", email)
+//	}
+//
+// dest has already run on the first row when ErrMultipleRows is returned.
+func (db *DB) QueryExactlyOne(ctx context.Context, dest func(row pgx.Row) error, sql string, args ...interface{}) error {
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return NewNotFoundError("row", nil)
+	}
+	if err := dest(rows); err != nil {
+		return err
+	}
+	if rows.Next() {
+		return ErrMultipleRows
+	}
+	return rows.Err()
+}
+
+// ReadReduce runs a query on the read pool and folds its rows into an
+// accumulator, starting from init, without materializing them: memory stays
+// bounded however many rows the query returns. step receives the
+// accumulator and the current row and returns the new accumulator.
+//
+// Example:
+//
+//	totals, err := pgxkit.ReadReduce(ctx, db, map[string]float64{},
+//	    func(acc map[string]float64, rows pgx.Rows) (map[string]float64, error) {
+//	        var region string
+//	        var amount float64
+//	        if err := rows.Scan(&region, &amount); err != nil {
+//	            return acc, err
+//	        }
+//	        acc[region] += amount
+//	        return acc, nil
+//	    }, "SELECT region, amount FROM orders WHERE placed_at >= $1", since)
+//
+// On error, ReadReduce returns the accumulator as it stood and the error;
+// a step error stops the fold.
+func ReadReduce[A any](ctx context.Context, db *DB, init A, step func(A, pgx.Rows) (A, error), sql string, args ...interface{}) (A, error) {
+	rows, err := db.ReadQuery(ctx, sql, args...)
+	if err != nil {
+		return init, err
+	}
+	return reduceRows(rows, init, step)
+}
+
+// reduceRows folds rows into acc with step and closes rows.
+func reduceRows[A any](rows pgx.Rows, acc A, step func(A, pgx.Rows) (A, error)) (A, error) {
+	defer rows.Close()
+
+	for rows.Next() {
+		var err error
+		if acc, err = step(acc, rows); err != nil {
+			return acc, err
+		}
+	}
+	return acc, rows.Err()
+}
+
+// ValueMapper converts a value decoded by pgx into the Go value stored in a
+// row map. fd describes the column, including its type OID, so mappers can
+// key off the Postgres type rather than the Go type pgx happened to pick.
+type ValueMapper func(fd pgconn.FieldDescription, value any) any
+
+// DefaultValueMapper is the ValueMapper used by QueryMaps and RowToMap. It
+// keeps pgx's decoding for types that already have a natural Go form
+// (timestamps as time.Time, bytea as []byte, text as string, integers) and
+// replaces pgx wrappers that don't serialize cleanly: uuid columns become
+// uuid.UUID and numeric columns become float64 (NULL stays nil).
+func DefaultValueMapper(fd pgconn.FieldDescription, value any) any {
+	switch v := value.(type) {
+	case [16]byte:
+		if fd.DataTypeOID == pgtype.UUIDOID {
+			return uuid.UUID(v)
+		}
+	case pgtype.Numeric:
+		if f := FromPgxNumeric(v); f != nil {
+			return *f
+		}
+		return nil
+	}
+	return value
+}
+
+// RowToMap is a pgx.RowToFunc that returns the current row as a column-name
+// to value map using DefaultValueMapper. Use it with pgx.CollectRows:
+//
+//	rows, _ := db.Query(ctx, "SELECT * FROM users")
+//	users, err := pgx.CollectRows(rows, pgxkit.RowToMap)
+func RowToMap(row pgx.CollectableRow) (map[string]any, error) {
+	return rowToMap(row, DefaultValueMapper)
+}
+
+// RowToMapWith returns a pgx.RowToFunc like RowToMap that runs every value
+// through mapper instead of DefaultValueMapper. A mapper typically handles the
+// types it cares about and delegates the rest to DefaultValueMapper:
+//
+//	uuidAsString := func(fd pgconn.FieldDescription, v any) any {
+//	    if fd.DataTypeOID == pgtype.UUIDOID {
+//	        if b, ok := v.([16]byte); ok {
+//	            return uuid.UUID(b).String()
+//	        }
+//	    }
+//	    return pgxkit.DefaultValueMapper(fd, v)
+//	}
+//	rows, err := pgx.CollectRows(rows, pgxkit.RowToMapWith(uuidAsString))
+func RowToMapWith(mapper ValueMapper) pgx.RowToFunc[map[string]any] {
+	if mapper == nil {
+		mapper = DefaultValueMapper
+	}
+	return func(row pgx.CollectableRow) (map[string]any, error) {
+		return rowToMap(row, mapper)
+	}
+}
+
+func rowToMap(row pgx.CollectableRow, mapper ValueMapper) (map[string]any, error) {
+	values, err := row.Values()
+	if err != nil {
+		return nil, err
+	}
+	fields := row.FieldDescriptions()
+	result := make(map[string]any, len(fields))
+	for i, fd := range fields {
+		if i < len(values) {
+			result[fd.Name] = mapper(fd, values[i])
+		}
+	}
+	return result, nil
+}
+
+// collectMaps drains rows into column-name keyed maps and closes rows.
+func collectMaps(rows pgx.Rows) ([]map[string]any, error) {
+	defer rows.Close()
+
+	result := make([]map[string]any, 0)
+	for rows.Next() {
+		row, err := rowToMap(rows, DefaultValueMapper)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ScanNullable scans a single-column row into a T, returning def when the
+// value is NULL. A missing row is still an error: pgx.ErrNoRows is returned
+// unchanged, so callers can tell "no such row" from "row with a NULL value"
+// and map the former to a NotFoundError:
+//
+//	nickname, err := pgxkit.ScanNullable(db.QueryRow(ctx,
+//	    "SELECT nickname FROM users WHERE id = $1", id), "anonymous")
+//	if errors.Is(err, pgx.ErrNoRows) {
+//	    return "", pgxkit.NewNotFoundError("User", id)
+//	}
+//
+// On any error the zero T is returned, not def.
+func ScanNullable[T any](row pgx.Row, def T) (T, error) {
+	var v *T
+	if err := row.Scan(&v); err != nil {
+		var zero T
+		return zero, err
+	}
+	if v == nil {
+		return def, nil
+	}
+	return *v, nil
+}
+
+// ErrNullComposite is returned by ScanComposite when the composite value is
+// NULL and dest cannot represent that (it points to a struct rather than to a
+// struct pointer).
+var ErrNullComposite = errors.New("composite value is NULL")
+
+// ScanComposite scans a single composite-typed column (a value of a type
+// created with CREATE TYPE ... AS (...), or a ROW(...) cast to one) into a
+// struct. Exported struct fields map to the composite's attributes by
+// position, so declare them in the same order as the type.
+//
+// pgx only knows a composite type once it is registered on the connection;
+// use WithTypeRegistry with LoadTypes:
+//
+//	type Address struct {
+//	    Street string
+//	    City   string
+//	    Zip    *string
+//	}
+//
+//	db.Connect(ctx, dsn, pgxkit.WithTypeRegistry(
+//	    pgxkit.NewTypeRegistry().Register(pgxkit.LoadTypes("address"))))
+//
+//	var addr Address
+//	err := pgxkit.ScanComposite(db.QueryRow(ctx,
+//	    "SELECT home_address FROM users WHERE id = $1", id), &addr)
+//
+// dest is a pointer to a struct or a pointer to a struct pointer. For a NULL
+// composite, a struct pointer is set to nil, while a struct is zeroed and
+// ErrNullComposite is returned. Errors from the row, including
+// pgx.ErrNoRows, are returned unchanged.
+func ScanComposite(row pgx.Row, dest any) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("ScanComposite: dest must be a non-nil pointer to a struct or struct pointer, got %T", dest)
+	}
+	elem := v.Elem()
+	switch {
+	case elem.Kind() == reflect.Struct:
+		holder := reflect.New(reflect.PointerTo(elem.Type()))
+		if err := row.Scan(holder.Interface()); err != nil {
+			return err
+		}
+		if holder.Elem().IsNil() {
+			elem.SetZero()
+			return ErrNullComposite
+		}
+		elem.Set(holder.Elem().Elem())
+		return nil
+	case elem.Kind() == reflect.Pointer && elem.Type().Elem().Kind() == reflect.Struct:
+		return row.Scan(dest)
+	}
+	return fmt.Errorf("ScanComposite: dest must be a non-nil pointer to a struct or struct pointer, got %T", dest)
+}
+
+// NullScanner scans a row of nullable columns straight into pointer fields.
+// Each method registers the next column's destination; Scan reads the row
+// into the matching pgtype and applies the FromPgx* conversion, so NULL
+// becomes a nil pointer without any intermediate variables:
+//
+//	var user struct {
+//	    Name      *string
+//	    Age       *int64
+//	    DeletedAt *time.Time
+//	}
+//	var ns pgxkit.NullScanner
+//	err := ns.Text(&user.Name).Int8(&user.Age).Timestamptz(&user.DeletedAt).
+//	    Scan(db.QueryRow(ctx, "SELECT name, age, deleted_at FROM users WHERE id = $1", id))
+//
+// Register destinations in column order. The zero value is ready to use.
+// Destinations are only written when Scan succeeds.
+type NullScanner struct {
+	dests []any
+	apply []func()
+}
+
+// nullScan registers dst as the next column, scanned as P and converted
+// with convert.
+func nullScan[P, T any](s *NullScanner, dst *T, convert func(P) T) *NullScanner {
+	v := new(P)
+	s.dests = append(s.dests, v)
+	s.apply = append(s.apply, func() { *dst = convert(*v) })
+	return s
+}
+
+// Text registers a text column, converted with FromPgxText.
+func (s *NullScanner) Text(dst **string) *NullScanner {
+	return nullScan(s, dst, FromPgxText)
+}
+
+// Int8 registers a bigint column, converted with FromPgxInt8.
+func (s *NullScanner) Int8(dst **int64) *NullScanner {
+	return nullScan(s, dst, FromPgxInt8)
+}
+
+// Int4 registers an integer column, converted with FromPgxInt4.
+func (s *NullScanner) Int4(dst **int32) *NullScanner {
+	return nullScan(s, dst, FromPgxInt4)
+}
+
+// Int2 registers a smallint column, converted with FromPgxInt2.
+func (s *NullScanner) Int2(dst **int16) *NullScanner {
+	return nullScan(s, dst, FromPgxInt2)
+}
+
+// Bool registers a boolean column, converted with FromPgxBool.
+func (s *NullScanner) Bool(dst **bool) *NullScanner {
+	return nullScan(s, dst, FromPgxBool)
+}
+
+// Float8 registers a double precision column, converted with FromPgxFloat8.
+func (s *NullScanner) Float8(dst **float64) *NullScanner {
+	return nullScan(s, dst, FromPgxFloat8)
+}
+
+// Numeric registers a numeric column, converted with FromPgxNumeric.
+func (s *NullScanner) Numeric(dst **float64) *NullScanner {
+	return nullScan(s, dst, FromPgxNumeric)
+}
+
+// UUID registers a uuid column, converted with FromPgxUUIDToPtr.
+func (s *NullScanner) UUID(dst **uuid.UUID) *NullScanner {
+	return nullScan(s, dst, FromPgxUUIDToPtr)
+}
+
+// Timestamptz registers a timestamptz column, converted with
+// FromPgxTimestamptzPtr.
+func (s *NullScanner) Timestamptz(dst **time.Time) *NullScanner {
+	return nullScan(s, dst, FromPgxTimestamptzPtr)
+}
+
+// Timestamp registers a timestamp column, converted with FromPgxTimestamp.
+func (s *NullScanner) Timestamp(dst **time.Time) *NullScanner {
+	return nullScan(s, dst, FromPgxTimestamp)
+}
+
+// Date registers a date column, converted with FromPgxDate.
+func (s *NullScanner) Date(dst **time.Time) *NullScanner {
+	return nullScan(s, dst, FromPgxDate)
+}
+
+// Scan scans row into the registered columns and fills every destination.
+// Errors from the row, including pgx.ErrNoRows, are returned unchanged. Scan
+// may be called again, e.g. once per row of pgx.Rows, and refills the same
+// destinations each time.
+func (s *NullScanner) Scan(row pgx.Row) error {
+	if err := row.Scan(s.dests...); err != nil {
+		return err
+	}
+	for _, apply := range s.apply {
+		apply()
+	}
+	return nil
+}
@@ -0,0 +1,521 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// mockRows is an in-memory pgx.Rows over a fixed set of fields and values.
+type mockRows struct {
+	fields []pgconn.FieldDescription
+	values [][]any
+	idx    int
+	err    error
+	closed bool
+}
+
+func (m *mockRows) Close()                                       { m.closed = true }
+func (m *mockRows) Err() error                                   { return m.err }
+func (m *mockRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (m *mockRows) FieldDescriptions() []pgconn.FieldDescription { return m.fields }
+func (m *mockRows) RawValues() [][]byte                          { return nil }
+func (m *mockRows) Conn() *pgx.Conn                              { return nil }
+
+func (m *mockRows) Next() bool {
+	if m.closed || m.idx >= len(m.values) {
+		m.closed = true
+		return false
+	}
+	m.idx++
+	return true
+}
+
+func (m *mockRows) Values() ([]any, error) {
+	return m.values[m.idx-1], nil
+}
+
+func (m *mockRows) Scan(dest ...any) error {
+	return errors.New("mockRows: Scan not supported")
+}
+
+func TestCollectMaps(t *testing.T) {
+	id := uuid.New()
+	rows := &mockRows{
+		fields: []pgconn.FieldDescription{
+			{Name: "id", DataTypeOID: pgtype.UUIDOID},
+			{Name: "name", DataTypeOID: pgtype.TextOID},
+			{Name: "balance", DataTypeOID: pgtype.NumericOID},
+			{Name: "note", DataTypeOID: pgtype.TextOID},
+		},
+		values: [][]any{
+			{[16]byte(id), "alice", pgtype.Numeric{Int: big.NewInt(1250), Exp: -2, Valid: true}, nil},
+			{[16]byte(id), "bob", pgtype.Numeric{}, "vip"},
+		},
+	}
+
+	got, err := collectMaps(rows)
+	if err != nil {
+		t.Fatalf("collectMaps returned unexpected error: %v", err)
+	}
+	if !rows.closed {
+		t.Error("collectMaps should close rows")
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(got))
+	}
+
+	if got[0]["id"] != id {
+		t.Errorf("uuid column should map to uuid.UUID, got %T %v", got[0]["id"], got[0]["id"])
+	}
+	if got[0]["name"] != "alice" {
+		t.Errorf("expected name alice, got %v", got[0]["name"])
+	}
+	if got[0]["balance"] != 12.5 {
+		t.Errorf("numeric column should map to float64 12.5, got %T %v", got[0]["balance"], got[0]["balance"])
+	}
+	if v, ok := got[0]["note"]; !ok || v != nil {
+		t.Errorf("NULL column should be present with nil value, got %v (present=%v)", v, ok)
+	}
+	if got[1]["balance"] != nil {
+		t.Errorf("NULL numeric should map to nil, got %v", got[1]["balance"])
+	}
+	if got[1]["note"] != "vip" {
+		t.Errorf("expected note vip, got %v", got[1]["note"])
+	}
+}
+
+func TestCollectMapsEmpty(t *testing.T) {
+	got, err := collectMaps(&mockRows{fields: []pgconn.FieldDescription{{Name: "id"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil || len(got) != 0 {
+		t.Errorf("expected empty non-nil slice, got %v", got)
+	}
+}
+
+func TestCollectMapsRowsError(t *testing.T) {
+	wantErr := errors.New("stream broke")
+	_, err := collectMaps(&mockRows{err: wantErr})
+	if !errors.Is(err, wantErr) {
+		t.Errorf("expected rows error to surface, got %v", err)
+	}
+}
+
+func TestRowToMapUUIDIsTyped(t *testing.T) {
+	id := uuid.New()
+	rows := &mockRows{
+		fields: []pgconn.FieldDescription{{Name: "id", DataTypeOID: pgtype.UUIDOID}},
+		values: [][]any{{[16]byte(id)}},
+	}
+
+	got, err := pgx.CollectRows(rows, RowToMap)
+	if err != nil {
+		t.Fatalf("CollectRows returned unexpected error: %v", err)
+	}
+	if _, isRaw := got[0]["id"].([16]byte); isRaw {
+		t.Fatal("uuid column should not come back as [16]byte")
+	}
+	if got[0]["id"] != id {
+		t.Errorf("expected uuid.UUID %v, got %T %v", id, got[0]["id"], got[0]["id"])
+	}
+}
+
+func TestRowToMapWithCustomMapper(t *testing.T) {
+	id := uuid.New()
+	rows := &mockRows{
+		fields: []pgconn.FieldDescription{
+			{Name: "id", DataTypeOID: pgtype.UUIDOID},
+			{Name: "balance", DataTypeOID: pgtype.NumericOID},
+		},
+		values: [][]any{{[16]byte(id), pgtype.Numeric{Int: big.NewInt(3), Valid: true}}},
+	}
+
+	uuidAsString := func(fd pgconn.FieldDescription, v any) any {
+		if fd.DataTypeOID == pgtype.UUIDOID {
+			if b, ok := v.([16]byte); ok {
+				return uuid.UUID(b).String()
+			}
+		}
+		return DefaultValueMapper(fd, v)
+	}
+
+	got, err := pgx.CollectRows(rows, RowToMapWith(uuidAsString))
+	if err != nil {
+		t.Fatalf("CollectRows returned unexpected error: %v", err)
+	}
+	if got[0]["id"] != id.String() {
+		t.Errorf("custom mapper should render uuid as string, got %T %v", got[0]["id"], got[0]["id"])
+	}
+	if got[0]["balance"] != 3.0 {
+		t.Errorf("delegated values should use DefaultValueMapper, got %T %v", got[0]["balance"], got[0]["balance"])
+	}
+}
+
+func TestRowToMapWithNilMapperUsesDefault(t *testing.T) {
+	id := uuid.New()
+	rows := &mockRows{
+		fields: []pgconn.FieldDescription{{Name: "id", DataTypeOID: pgtype.UUIDOID}},
+		values: [][]any{{[16]byte(id)}},
+	}
+	got, err := pgx.CollectRows(rows, RowToMapWith(nil))
+	if err != nil {
+		t.Fatalf("CollectRows returned unexpected error: %v", err)
+	}
+	if got[0]["id"] != id {
+		t.Errorf("nil mapper should fall back to DefaultValueMapper, got %T", got[0]["id"])
+	}
+}
+
+func TestQueryMapsNotConnected(t *testing.T) {
+	_, err := NewDB().QueryMaps(context.Background(), "SELECT 1")
+	if err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected not connected error, got %v", err)
+	}
+}
+
+func TestQueryMapsIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+
+	db := NewDB()
+	db.readPool = pool
+	db.writePool = pool
+
+	got, err := db.QueryMaps(context.Background(),
+		"SELECT 1::int AS n, 'x'::text AS s, 1.5::numeric AS d, NULL::text AS missing, gen_random_uuid() AS id")
+	if err != nil {
+		t.Fatalf("QueryMaps failed: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(got))
+	}
+	row := got[0]
+	if row["n"] != int32(1) || row["s"] != "x" || row["d"] != 1.5 || row["missing"] != nil {
+		t.Errorf("unexpected row: %#v", row)
+	}
+	if _, ok := row["id"].(uuid.UUID); !ok {
+		t.Errorf("expected uuid.UUID for id, got %T", row["id"])
+	}
+}
+
+func TestQueryExactlyOneNotConnected(t *testing.T) {
+	err := NewDB().QueryExactlyOne(context.Background(), func(row pgx.Row) error { return nil }, "SELECT 1")
+	if err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected not connected error, got %v", err)
+	}
+}
+
+func TestQueryExactlyOneIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+	ctx := context.Background()
+
+	db := NewDB()
+	db.readPool = pool
+	db.writePool = pool
+
+	var n int
+	scan := func(row pgx.Row) error { return row.Scan(&n) }
+	const sql = "SELECT g FROM generate_series(1, $1::int) AS g"
+
+	err := db.QueryExactlyOne(ctx, scan, sql, 0)
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError for no rows, got %v", err)
+	}
+
+	if err := db.QueryExactlyOne(ctx, scan, sql, 1); err != nil || n != 1 {
+		t.Errorf("expected the single row, got %d, %v", n, err)
+	}
+
+	if err := db.QueryExactlyOne(ctx, scan, sql, 3); !errors.Is(err, ErrMultipleRows) {
+		t.Errorf("expected ErrMultipleRows, got %v", err)
+	}
+
+	scanErr := errors.New("scan failed")
+	if err := db.QueryExactlyOne(ctx, func(row pgx.Row) error { return scanErr }, sql, 1); !errors.Is(err, scanErr) {
+		t.Errorf("expected dest's error, got %v", err)
+	}
+}
+
+// valueRow is a pgx.Row that scans a single value, or nil for NULL, into a
+// pointer-to-pointer destination the way pgx does.
+type valueRow[T any] struct {
+	value *T
+	err   error
+}
+
+func (r valueRow[T]) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	*(dest[0].(**T)) = r.value
+	return nil
+}
+
+func TestScanNullable(t *testing.T) {
+	n, err := ScanNullable[int](valueRow[int]{}, 7)
+	if err != nil || n != 7 {
+		t.Errorf("NULL should return the default, got %d, %v", n, err)
+	}
+
+	present := 0
+	n, err = ScanNullable[int](valueRow[int]{value: &present}, 7)
+	if err != nil || n != 0 {
+		t.Errorf("a present zero value should not be replaced by the default, got %d, %v", n, err)
+	}
+
+	s, err := ScanNullable[string](valueRow[string]{err: pgx.ErrNoRows}, "default")
+	if !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("expected pgx.ErrNoRows for a missing row, got %v", err)
+	}
+	if s != "" {
+		t.Errorf("expected zero value on error, got %q", s)
+	}
+}
+
+func TestScanNullableIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+	ctx := context.Background()
+
+	name, err := ScanNullable(pool.QueryRow(ctx, "SELECT NULL::text"), "anonymous")
+	if err != nil || name != "anonymous" {
+		t.Errorf("NULL should return the default, got %q, %v", name, err)
+	}
+
+	name, err = ScanNullable(pool.QueryRow(ctx, "SELECT 'alice'::text"), "anonymous")
+	if err != nil || name != "alice" {
+		t.Errorf("expected alice, got %q, %v", name, err)
+	}
+
+	n, err := ScanNullable(pool.QueryRow(ctx, "SELECT 1::int WHERE false"), 5)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("expected pgx.ErrNoRows, got %d, %v", n, err)
+	}
+}
+
+type testAddress struct {
+	Street string
+	City   string
+	Zip    *string
+}
+
+func TestScanComposite(t *testing.T) {
+	zip := "94110"
+	present := &testAddress{Street: "1 Main St", City: "SF", Zip: &zip}
+
+	var addr testAddress
+	if err := ScanComposite(valueRow[testAddress]{value: present}, &addr); err != nil {
+		t.Fatalf("ScanComposite failed: %v", err)
+	}
+	if addr.City != "SF" || addr.Zip == nil || *addr.Zip != "94110" {
+		t.Errorf("unexpected address %+v", addr)
+	}
+
+	addr = testAddress{Street: "stale"}
+	if err := ScanComposite(valueRow[testAddress]{}, &addr); !errors.Is(err, ErrNullComposite) {
+		t.Errorf("expected ErrNullComposite for NULL into a struct, got %v", err)
+	}
+	if addr.Street != "" {
+		t.Errorf("NULL should zero the struct, got %+v", addr)
+	}
+
+	ptr := &testAddress{Street: "stale"}
+	if err := ScanComposite(valueRow[testAddress]{}, &ptr); err != nil || ptr != nil {
+		t.Errorf("NULL into a struct pointer should set it to nil, got %+v, %v", ptr, err)
+	}
+
+	if err := ScanComposite(valueRow[testAddress]{err: pgx.ErrNoRows}, &addr); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("expected pgx.ErrNoRows, got %v", err)
+	}
+
+	for _, dest := range []any{nil, addr, new(int), (*testAddress)(nil)} {
+		if err := ScanComposite(valueRow[testAddress]{value: present}, dest); err == nil {
+			t.Errorf("expected error for dest %T", dest)
+		}
+	}
+}
+
+func TestScanCompositeIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `DO $$ BEGIN
+		CREATE TYPE scan_test_address AS (street text, city text, zip text);
+	EXCEPTION WHEN duplicate_object THEN NULL; END $$`)
+	if err != nil {
+		t.Fatalf("Failed to create composite type: %v", err)
+	}
+	defer CleanupTestData("DROP TYPE IF EXISTS scan_test_address")
+
+	db := NewDB()
+	err = db.Connect(ctx, os.Getenv("TEST_DATABASE_URL"),
+		WithTypeRegistry(NewTypeRegistry().Register(LoadTypes("scan_test_address"))))
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer db.Shutdown(ctx)
+
+	var addr testAddress
+	err = ScanComposite(db.QueryRow(ctx, "SELECT ROW('1 Main St', 'SF', NULL)::scan_test_address"), &addr)
+	if err != nil {
+		t.Fatalf("ScanComposite failed: %v", err)
+	}
+	if addr.Street != "1 Main St" || addr.City != "SF" || addr.Zip != nil {
+		t.Errorf("unexpected address %+v", addr)
+	}
+
+	var ptr *testAddress
+	if err := ScanComposite(db.QueryRow(ctx, "SELECT NULL::scan_test_address"), &ptr); err != nil || ptr != nil {
+		t.Errorf("NULL composite should scan to a nil pointer, got %+v, %v", ptr, err)
+	}
+	if err := ScanComposite(db.QueryRow(ctx, "SELECT NULL::scan_test_address"), &addr); !errors.Is(err, ErrNullComposite) {
+		t.Errorf("expected ErrNullComposite, got %v", err)
+	}
+}
+
+func TestNullScanner(t *testing.T) {
+	when := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	row := &mockRow{scanFunc: func(dest ...interface{}) error {
+		if len(dest) != 4 {
+			t.Fatalf("expected 4 destinations, got %d", len(dest))
+		}
+		*dest[0].(*pgtype.Text) = pgtype.Text{String: "alice", Valid: true}
+		*dest[1].(*pgtype.Int8) = pgtype.Int8{}
+		*dest[2].(*pgtype.Bool) = pgtype.Bool{Bool: true, Valid: true}
+		*dest[3].(*pgtype.Timestamptz) = pgtype.Timestamptz{Time: when, Valid: true}
+		return nil
+	}}
+
+	var (
+		name   *string
+		age    = new(int64)
+		active *bool
+		seen   *time.Time
+	)
+	var ns NullScanner
+	if err := ns.Text(&name).Int8(&age).Bool(&active).Timestamptz(&seen).Scan(row); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if name == nil || *name != "alice" {
+		t.Errorf("expected name alice, got %v", name)
+	}
+	if age != nil {
+		t.Errorf("expected NULL age to set nil, got %d", *age)
+	}
+	if active == nil || !*active {
+		t.Errorf("expected active true, got %v", active)
+	}
+	if seen == nil || !seen.Equal(when) {
+		t.Errorf("expected %v, got %v", when, seen)
+	}
+}
+
+func TestNullScannerError(t *testing.T) {
+	name := new(string)
+	*name = "unchanged"
+	var ns NullScanner
+	err := ns.Text(&name).Scan(&mockRow{scanFunc: func(dest ...interface{}) error { return pgx.ErrNoRows }})
+	if !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("expected pgx.ErrNoRows, got %v", err)
+	}
+	if name == nil || *name != "unchanged" {
+		t.Errorf("destinations should not be written on error, got %v", name)
+	}
+}
+
+func TestNullScannerIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+	ctx := context.Background()
+
+	var (
+		name    *string
+		age     *int64
+		score   *float64
+		id      *uuid.UUID
+		deleted *time.Time
+	)
+	var ns NullScanner
+	err := ns.Text(&name).Int8(&age).Numeric(&score).UUID(&id).Timestamptz(&deleted).Scan(pool.QueryRow(ctx,
+		"SELECT 'bob'::text, NULL::bigint, 4.5::numeric, '6ba7b810-9dad-11d1-80b4-00c04fd430c8'::uuid, NULL::timestamptz"))
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if name == nil || *name != "bob" || age != nil || deleted != nil {
+		t.Errorf("unexpected values: name=%v age=%v deleted=%v", name, age, deleted)
+	}
+	if score == nil || *score != 4.5 {
+		t.Errorf("expected score 4.5, got %v", score)
+	}
+	if id == nil || id.String() != "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
+		t.Errorf("unexpected uuid %v", id)
+	}
+}
+
+func TestReduceRowsSums(t *testing.T) {
+	rows := &mockRows{
+		fields: []pgconn.FieldDescription{{Name: "amount", DataTypeOID: pgtype.Int8OID}},
+		values: [][]any{{int64(10)}, {int64(25)}, {int64(7)}},
+	}
+	var seen int
+	sum, err := reduceRows(rows, int64(0), func(acc int64, rows pgx.Rows) (int64, error) {
+		seen++
+		values, err := rows.Values()
+		if err != nil {
+			return acc, err
+		}
+		return acc + values[0].(int64), nil
+	})
+	if err != nil {
+		t.Fatalf("reduceRows returned unexpected error: %v", err)
+	}
+	if sum != 42 || seen != 3 {
+		t.Errorf("expected sum 42 over 3 rows, got %d over %d", sum, seen)
+	}
+	if !rows.closed {
+		t.Error("expected rows to be closed")
+	}
+}
+
+func TestReduceRowsErrors(t *testing.T) {
+	stepErr := errors.New("bad row")
+	rows := &mockRows{values: [][]any{{1}, {2}, {3}}}
+	count, err := reduceRows(rows, 0, func(acc int, rows pgx.Rows) (int, error) {
+		if acc == 2 {
+			return acc, stepErr
+		}
+		return acc + 1, nil
+	})
+	if !errors.Is(err, stepErr) || count != 2 {
+		t.Errorf("expected the step error after 2 rows, got %d, %v", count, err)
+	}
+	if !rows.closed {
+		t.Error("expected rows to be closed after a step error")
+	}
+
+	iterErr := errors.New("connection lost")
+	count, err = reduceRows(&mockRows{values: [][]any{{1}}, err: iterErr}, 0, func(acc int, rows pgx.Rows) (int, error) {
+		return acc + 1, nil
+	})
+	if !errors.Is(err, iterErr) || count != 1 {
+		t.Errorf("expected the rows error with the partial result, got %d, %v", count, err)
+	}
+}
+
+func TestReadReduceNotConnected(t *testing.T) {
+	got, err := ReadReduce(context.Background(), NewDB(), 5, func(acc int, rows pgx.Rows) (int, error) {
+		return acc + 1, nil
+	}, "SELECT 1")
+	if err == nil || got != 5 {
+		t.Errorf("expected init and an error when not connected, got %d, %v", got, err)
+	}
+}
@@ -0,0 +1,167 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// validSettingName reports whether name is a plain run-time parameter name
+// such as work_mem or a dotted custom one such as app.tenant_id.
+func validSettingName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, part := range strings.Split(name, ".") {
+		if !identifierPattern.MatchString(part) {
+			return false
+		}
+	}
+	return true
+}
+
+// WithSessionSettings runs fn on a single write-pool connection with the
+// given run-time parameters applied, then resets them before the connection
+// goes back to the pool:
+//
+//	err := db.WithSessionSettings(ctx, map[string]string{
+//	    "work_mem":       "256MB",
+//	    "enable_seqscan": "off",
+//	}, func(e pgxkit.Executor) error {
+//	    rows, err := e.Query(ctx, reportSQL)
+//	    ...
+//	})
+//
+// Values are bound as parameters to set_config, so they need no quoting.
+// Names must be plain identifiers, optionally dotted for custom settings;
+// anything else is rejected before a connection is acquired. Every statement
+// fn runs must go through the provided Executor, which fires the DB's
+// operation hooks; using db directly runs on other connections without the
+// settings.
+//
+// If applying or resetting a setting fails, the connection is closed rather
+// than returned to the pool, so a half-configured session is never reused.
+func (db *DB) WithSessionSettings(ctx context.Context, settings map[string]string, fn func(e Executor) error) (err error) {
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		if !validSettingName(name) {
+			return fmt.Errorf("invalid setting name %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pool, _, users, err := db.startOp(db.writeTarget)
+	if err != nil {
+		return err
+	}
+	defer db.endOp(users)
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	for _, name := range names {
+		if _, err := conn.Exec(ctx, "SELECT set_config($1, $2, false)", name, settings[name]); err != nil {
+			discardConn(ctx, conn)
+			return fmt.Errorf("failed to set %s: %w", name, err)
+		}
+	}
+
+	defer func() {
+		if resetErr := resetSession(ctx, conn, names); resetErr != nil {
+			err = errors.Join(err, resetErr)
+		}
+	}()
+	return fn(&sessionConn{db: db, conn: conn})
+}
+
+// resetSession resets the named settings on conn and releases it, closing it
+// first if a reset fails. WithSessionSettings defers it, so a panicking
+// callback neither leaks the connection nor returns it with the settings.
+func resetSession(ctx context.Context, conn *pgxpool.Conn, names []string) error {
+	for _, name := range names {
+		if _, err := conn.Exec(ctx, "RESET "+name); err != nil {
+			discardConn(ctx, conn)
+			return fmt.Errorf("failed to reset %s: %w", name, err)
+		}
+	}
+	conn.Release()
+	return nil
+}
+
+// discardConn closes conn before releasing it, which makes the pool destroy
+// it instead of handing it to the next caller.
+func discardConn(ctx context.Context, conn *pgxpool.Conn) {
+	_ = conn.Conn().Close(ctx)
+	conn.Release()
+}
+
+// sessionConn is the Executor handed to WithSessionSettings callbacks. It runs
+// statements on the pinned connection and fires the DB's operation hooks.
+type sessionConn struct {
+	db   *DB
+	conn *pgxpool.Conn
+}
+
+func (s *sessionConn) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	sql, args, err := s.db.hooks.executeRewrite(ctx, sql, args)
+	if err != nil {
+		return nil, err
+	}
+	ctx = withHookState(ctx)
+	if err := s.db.hooks.executeBeforeOperation(ctx, sql, args, pgconn.CommandTag{}, nil); err != nil {
+		return nil, fmt.Errorf("before operation hook failed: %w", err)
+	}
+	rows, err := s.conn.Query(ctx, sql, args...)
+	if hookErr := s.db.hooks.executeAfterOperation(ctx, sql, args, pgconn.CommandTag{}, err); hookErr != nil {
+		if rows != nil {
+			rows.Close()
+		}
+		if err == nil {
+			return nil, fmt.Errorf("after operation hook failed: %w", hookErr)
+		}
+	}
+	return rows, err
+}
+
+func (s *sessionConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	sql, args, err := s.db.hooks.executeRewrite(ctx, sql, args)
+	if err != nil {
+		return &shutdownRow{err: err}
+	}
+	ctx = withHookState(ctx)
+	if err := s.db.hooks.executeBeforeOperation(ctx, sql, args, pgconn.CommandTag{}, nil); err != nil {
+		return &shutdownRow{err: fmt.Errorf("before operation hook failed: %w", err)}
+	}
+	row := s.conn.QueryRow(ctx, sql, args...)
+	if hookErr := s.db.hooks.executeAfterOperation(ctx, sql, args, pgconn.CommandTag{}, nil); hookErr != nil {
+		return &shutdownRow{err: fmt.Errorf("after operation hook failed: %w", hookErr)}
+	}
+	return row
+}
+
+func (s *sessionConn) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	sql, args, err := s.db.hooks.executeRewrite(ctx, sql, args)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	ctx = withHookState(ctx)
+	if err := s.db.hooks.executeBeforeOperation(ctx, sql, args, pgconn.CommandTag{}, nil); err != nil {
+		return pgconn.CommandTag{}, fmt.Errorf("before operation hook failed: %w", err)
+	}
+	tag, err := s.conn.Exec(ctx, sql, args...)
+	if hookErr := s.db.hooks.executeAfterOperation(ctx, sql, args, tag, err); hookErr != nil {
+		if err == nil {
+			return tag, fmt.Errorf("after operation hook failed: %w", hookErr)
+		}
+	}
+	return tag, err
+}
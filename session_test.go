@@ -0,0 +1,178 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestValidSettingName(t *testing.T) {
+	for _, name := range []string{"work_mem", "enable_seqscan", "app.tenant_id", "Search_Path"} {
+		if !validSettingName(name) {
+			t.Errorf("expected %q to be valid", name)
+		}
+	}
+	for _, name := range []string{"", "work mem", "work_mem; DROP TABLE users", "app.", ".x", "1abc", `"quoted"`} {
+		if validSettingName(name) {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestWithSessionSettings_RejectsInvalidName(t *testing.T) {
+	db := NewDB()
+	called := false
+	err := db.WithSessionSettings(context.Background(), map[string]string{
+		"work_mem; RESET ALL": "1MB",
+	}, func(Executor) error {
+		called = true
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid setting name") {
+		t.Fatalf("expected invalid setting name error, got %v", err)
+	}
+	if called {
+		t.Error("fn should not run when a setting name is invalid")
+	}
+}
+
+func TestWithSessionSettings_NotConnected(t *testing.T) {
+	db := NewDB()
+	err := db.WithSessionSettings(context.Background(), map[string]string{"work_mem": "1MB"}, func(Executor) error {
+		t.Error("fn should not run without a connection")
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Fatalf("expected not connected error, got %v", err)
+	}
+}
+
+func TestWithSessionSettings_WorkMemIsReset(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+	ctx := context.Background()
+
+	// One connection, so the follow-up query lands on the connection the
+	// settings were applied to.
+	db := NewDB()
+	if err := db.Connect(ctx, dsn, WithMaxConns(1)); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer db.Shutdown(ctx)
+
+	var before string
+	if err := db.QueryRow(ctx, "SHOW work_mem").Scan(&before); err != nil {
+		t.Fatalf("SHOW work_mem failed: %v", err)
+	}
+
+	var ops int
+	db.hooks.addHook(BeforeOperation, func(context.Context, string, []interface{}, pgconn.CommandTag, error) error {
+		ops++
+		return nil
+	})
+
+	err := db.WithSessionSettings(ctx, map[string]string{"work_mem": "64MB"}, func(e Executor) error {
+		var during string
+		if err := e.QueryRow(ctx, "SHOW work_mem").Scan(&during); err != nil {
+			return err
+		}
+		if during != "64MB" {
+			t.Errorf("work_mem inside fn = %q, want 64MB", during)
+		}
+		rows, err := e.Query(ctx, "SELECT g FROM generate_series(1, 100000) g ORDER BY g DESC")
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		t.Fatalf("WithSessionSettings failed: %v", err)
+	}
+	if ops != 2 {
+		t.Errorf("expected operation hooks for 2 statements, got %d", ops)
+	}
+
+	var after string
+	if err := db.QueryRow(ctx, "SHOW work_mem").Scan(&after); err != nil {
+		t.Fatalf("SHOW work_mem failed: %v", err)
+	}
+	if after != before {
+		t.Errorf("work_mem after = %q, want reset to %q", after, before)
+	}
+}
+
+func TestWithSessionSettings_ReturnsFnError(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+	ctx := context.Background()
+
+	db := NewDB()
+	if err := db.Connect(ctx, dsn, WithMaxConns(1)); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer db.Shutdown(ctx)
+
+	fnErr := errors.New("report failed")
+	err := db.WithSessionSettings(ctx, map[string]string{"enable_seqscan": "off"}, func(Executor) error {
+		return fnErr
+	})
+	if !errors.Is(err, fnErr) {
+		t.Fatalf("expected fn error, got %v", err)
+	}
+
+	var setting string
+	if err := db.QueryRow(ctx, "SHOW enable_seqscan").Scan(&setting); err != nil {
+		t.Fatalf("SHOW enable_seqscan failed: %v", err)
+	}
+	if setting != "on" {
+		t.Errorf("enable_seqscan = %q, want reset to on", setting)
+	}
+}
+
+func TestWithSessionSettings_ResetsOnPanic(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+	ctx := context.Background()
+
+	// One connection, so a leaked connection would block the query below.
+	db := NewDB()
+	if err := db.Connect(ctx, dsn, WithMaxConns(1)); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer db.Shutdown(ctx)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("expected the panic to propagate, got %v", r)
+			}
+		}()
+		_ = db.WithSessionSettings(ctx, map[string]string{"enable_seqscan": "off"}, func(Executor) error {
+			panic("boom")
+		})
+	}()
+
+	queryCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	var setting string
+	if err := db.QueryRow(queryCtx, "SHOW enable_seqscan").Scan(&setting); err != nil {
+		t.Fatalf("SHOW enable_seqscan failed, was the connection leaked? %v", err)
+	}
+	if setting != "on" {
+		t.Errorf("enable_seqscan = %q, want reset to on", setting)
+	}
+}
@@ -0,0 +1,60 @@
+package pgxkit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// shutdownQueryTimeout bounds each statement registered with
+// AddShutdownQuery. The statements run even if Shutdown's context has
+// already ended, so they need a limit of their own.
+const shutdownQueryTimeout = 5 * time.Second
+
+type shutdownQuery struct {
+	sql  string
+	args []any
+}
+
+// AddShutdownQuery registers a statement for Shutdown to run on the write
+// pool once in-flight operations have finished, before the OnShutdown hooks
+// and before the pools close. Use it for final bookkeeping such as marking
+// the instance offline or releasing a lease:
+//
+//	err := db.AddShutdownQuery("UPDATE instances SET online = false WHERE id = $1", instanceID)
+//
+// Statements run in registration order, each with its own 5 second timeout
+// (even when Shutdown's context has already expired). A failure is logged
+// with slog.Default and does not stop the others or fail Shutdown. They run
+// directly on the pool, without operation hooks.
+func (db *DB) AddShutdownQuery(sql string, args ...any) error {
+	if sql == "" {
+		return fmt.Errorf("shutdown query cannot be empty")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.shutdown {
+		return fmt.Errorf("database is shutting down")
+	}
+	db.shutdownQueries = append(db.shutdownQueries, shutdownQuery{sql: sql, args: args})
+	return nil
+}
+
+// runShutdownQueries executes the AddShutdownQuery statements. It is called
+// by Shutdown after the DB is marked as shutting down, when the list can no
+// longer change.
+func (db *DB) runShutdownQueries(ctx context.Context) {
+	if db.writePool == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	for _, q := range db.shutdownQueries {
+		queryCtx, cancel := context.WithTimeout(ctx, shutdownQueryTimeout)
+		_, err := db.writePool.Exec(queryCtx, q.sql, q.args...)
+		cancel()
+		if err != nil {
+			slog.ErrorContext(ctx, "shutdown query failed", "sql", q.sql, "error", err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package pgxkit
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// captureSlog routes slog.Default to a buffer for the rest of the test.
+func captureSlog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func TestAddShutdownQueryValidation(t *testing.T) {
+	db := NewDB()
+	if err := db.AddShutdownQuery(""); err == nil {
+		t.Error("expected error for empty SQL")
+	}
+	if err := db.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if err := db.AddShutdownQuery("SELECT 1"); err == nil || !strings.Contains(err.Error(), "shutting down") {
+		t.Errorf("expected shutting down error, got %v", err)
+	}
+}
+
+func TestShutdownRunsQueriesAndLogsFailures(t *testing.T) {
+	logs := captureSlog(t)
+	db := NewDB()
+	db.writePool = newLazyPool(t)
+	db.readPool = db.writePool
+
+	if err := db.AddShutdownQuery("UPDATE instances SET online = false WHERE id = $1", 7); err != nil {
+		t.Fatalf("AddShutdownQuery failed: %v", err)
+	}
+	if err := db.AddShutdownQuery("SELECT pg_advisory_unlock_all()"); err != nil {
+		t.Fatalf("AddShutdownQuery failed: %v", err)
+	}
+	// The lazy pool can't connect, so each statement is attempted and its
+	// failure logged without failing Shutdown.
+	if err := db.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown should not fail on shutdown query errors, got %v", err)
+	}
+
+	out := logs.String()
+	first := strings.Index(out, "UPDATE instances")
+	second := strings.Index(out, "pg_advisory_unlock_all")
+	if first < 0 || second < first {
+		t.Errorf("expected both statements attempted in order, got logs:\n%s", out)
+	}
+	if strings.Count(out, "shutdown query failed") != 2 {
+		t.Errorf("expected two logged failures, got:\n%s", out)
+	}
+}
+
+func TestShutdownQueriesIntegration(t *testing.T) {
+	shared := requireTestPool(t)
+	ctx := context.Background()
+	if _, err := shared.Exec(ctx, "CREATE TABLE IF NOT EXISTS shutdown_marks (instance text)"); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	t.Cleanup(func() { _, _ = shared.Exec(context.Background(), "DROP TABLE IF EXISTS shutdown_marks") })
+	if _, err := shared.Exec(ctx, "TRUNCATE shutdown_marks"); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+
+	db := NewDB()
+	db.writePool = newIsolatedTestPool(t)
+	db.readPool = db.writePool
+	if err := db.AddShutdownQuery("INSERT INTO shutdown_marks VALUES ($1)", "worker-1"); err != nil {
+		t.Fatalf("AddShutdownQuery failed: %v", err)
+	}
+	if err := db.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	var instance string
+	if err := shared.QueryRow(ctx, "SELECT instance FROM shutdown_marks").Scan(&instance); err != nil || instance != "worker-1" {
+		t.Errorf("expected the shutdown query to have run, got %q, %v", instance, err)
+	}
+}
@@ -0,0 +1,69 @@
+package pgxkit
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SlowTransaction describes a transaction that ran longer than the
+// WithSlowTransactionThreshold threshold.
+type SlowTransaction struct {
+	// Duration is the time from BeginTx to the end of Commit or Rollback.
+	Duration time.Duration
+	// Committed is true only when Commit succeeded; rollbacks and failed
+	// commits report false.
+	Committed bool
+	// Err is the error returned by Commit or Rollback, if any.
+	Err error
+	// Info is the transaction's final state, including its statement count.
+	Info TxInfo
+}
+
+// WithSlowTransactionThreshold calls fn whenever a transaction begun with
+// BeginTx takes longer than threshold from begin to commit or rollback.
+// Long transactions hold locks and a pooled connection the whole time, so
+// they often explain lock contention or pool exhaustion elsewhere. fn runs
+// as an AfterTransaction hook, after the transaction has ended; keep it
+// quick. A non-positive threshold or nil fn disables the option.
+//
+// Example:
+//
+//	err := db.Connect(ctx, dsn, pgxkit.WithSlowTransactionThreshold(2*time.Second,
+//	    func(ctx context.Context, slow pgxkit.SlowTransaction) {
+//	        slog.WarnContext(ctx, "slow transaction",
+//	            "duration", slow.Duration, "committed", slow.Committed,
+//	            "statements", slow.Info.Statements)
+//	    }))
+func WithSlowTransactionThreshold(threshold time.Duration, fn func(ctx context.Context, slow SlowTransaction)) ConnectOption {
+	return func(c *connectConfig) {
+		if threshold <= 0 || fn == nil {
+			return
+		}
+		c.hooks.addHook(AfterTransaction, slowTransactionHook(threshold, fn))
+	}
+}
+
+// slowTransactionHook times the finalizing transaction from its start. A
+// BeginTx failure also fires AfterTransaction, but without a Tx in ctx, so it
+// is ignored.
+func slowTransactionHook(threshold time.Duration, fn func(ctx context.Context, slow SlowTransaction)) HookFunc {
+	return func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+		tx, ok := txFromContext(ctx)
+		if !ok {
+			return nil
+		}
+		duration := time.Since(tx.startedAt)
+		if duration <= threshold {
+			return nil
+		}
+		fn(ctx, SlowTransaction{
+			Duration:  duration,
+			Committed: sql == TxCommit && err == nil,
+			Err:       err,
+			Info:      tx.Info(),
+		})
+		return nil
+	}
+}
@@ -0,0 +1,137 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// slowTxDB returns a DB whose hooks come from WithSlowTransactionThreshold,
+// and the slow transactions it reports.
+func slowTxDB(threshold time.Duration) (*DB, *[]SlowTransaction) {
+	var slow []SlowTransaction
+	cfg := newConnectConfig()
+	WithSlowTransactionThreshold(threshold, func(ctx context.Context, s SlowTransaction) {
+		slow = append(slow, s)
+	})(cfg)
+	db := NewDB()
+	db.hooks = cfg.hooks
+	return db, &slow
+}
+
+func newTimedTx(db *DB, mock *mockTx, startedAt time.Time) *Tx {
+	db.activeOps.Add(1)
+	return &Tx{tx: mock, db: db, startedAt: startedAt}
+}
+
+func TestSlowTransactionReported(t *testing.T) {
+	db, slow := slowTxDB(10 * time.Millisecond)
+	ctx := context.Background()
+
+	tx := newTimedTx(db, &mockTx{}, time.Now())
+	time.Sleep(20 * time.Millisecond)
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	if len(*slow) != 1 {
+		t.Fatalf("expected one slow transaction, got %d", len(*slow))
+	}
+	got := (*slow)[0]
+	if got.Duration < 20*time.Millisecond || !got.Committed || got.Err != nil || !got.Info.Finalized {
+		t.Errorf("unexpected report: %+v", got)
+	}
+}
+
+func TestSlowTransactionRollbackAndFailedCommit(t *testing.T) {
+	db, slow := slowTxDB(time.Millisecond)
+	ctx := context.Background()
+	old := time.Now().Add(-time.Second)
+
+	_ = newTimedTx(db, &mockTx{}, old).Rollback(ctx)
+	commitErr := errors.New("serialization failure")
+	_ = newTimedTx(db, &mockTx{commitFunc: func(ctx context.Context) error { return commitErr }}, old).Commit(ctx)
+
+	if len(*slow) != 2 {
+		t.Fatalf("expected two slow transactions, got %d", len(*slow))
+	}
+	if (*slow)[0].Committed {
+		t.Error("a rollback should not be reported as committed")
+	}
+	if (*slow)[1].Committed || !errors.Is((*slow)[1].Err, commitErr) {
+		t.Errorf("a failed commit should report its error, got %+v", (*slow)[1])
+	}
+}
+
+func TestSlowTransactionFastIgnored(t *testing.T) {
+	db, slow := slowTxDB(time.Hour)
+	if err := newTimedTx(db, &mockTx{}, time.Now()).Commit(context.Background()); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if len(*slow) != 0 {
+		t.Errorf("fast transactions should not be reported, got %+v", *slow)
+	}
+}
+
+func TestWithSlowTransactionThresholdDisabled(t *testing.T) {
+	for _, opt := range []ConnectOption{
+		WithSlowTransactionThreshold(0, func(context.Context, SlowTransaction) {}),
+		WithSlowTransactionThreshold(time.Second, nil),
+	} {
+		cfg := newConnectConfig()
+		opt(cfg)
+		if n := cfg.hooks.count(AfterTransaction); n != 0 {
+			t.Errorf("expected no hook for a disabled option, got %d", n)
+		}
+	}
+}
+
+func TestSlowTransactionIntegration(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var slow []SlowTransaction
+	db := NewDB()
+	err := db.Connect(ctx, dsn, WithMaxConns(2), WithSlowTransactionThreshold(50*time.Millisecond,
+		func(ctx context.Context, s SlowTransaction) {
+			mu.Lock()
+			defer mu.Unlock()
+			slow = append(slow, s)
+		}))
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer db.Shutdown(ctx)
+
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "SELECT pg_sleep(0.1)"); err != nil {
+		t.Fatalf("pg_sleep failed: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	fast, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	_ = fast.Rollback(ctx)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(slow) != 1 || !slow[0].Committed || slow[0].Info.Statements != 1 {
+		t.Errorf("expected exactly the slow committed transaction, got %+v", slow)
+	}
+}
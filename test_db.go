@@ -6,17 +6,20 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
 // TestDB is a testing utility that wraps DB with testing-specific functionality.
 type TestDB struct {
 	*DB
+	goldenDir       string
+	goldenByPackage bool
 }
 
 func NewTestDB() *TestDB {
@@ -45,6 +48,86 @@ func (tdb *TestDB) Clean() error {
 	return nil
 }
 
+// RunInRollbackTx runs fn inside a transaction on the write pool that is always
+// rolled back when the test finishes, so the test never leaves data behind.
+//
+// Every statement in fn must go through the provided tx. Queries issued on the
+// TestDB (or its pools) run on other connections: they won't see the
+// transaction's uncommitted rows and their own writes are not rolled back.
+//
+// Example:
+//
+//	testDB.RunInRollbackTx(t, func(tx *pgxkit.Tx) {
+//	    _, err := tx.Exec(ctx, "INSERT INTO users (name) VALUES ($1)", "alice")
+//	    require.NoError(t, err)
+//	})
+func (tdb *TestDB) RunInRollbackTx(t *testing.T, fn func(tx *Tx)) {
+	t.Helper()
+	ctx := context.Background()
+	tx, err := tdb.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("failed to begin rollback transaction: %v", err)
+		return
+	}
+	t.Cleanup(func() {
+		if err := tx.Rollback(context.Background()); err != nil {
+			t.Errorf("failed to roll back test transaction: %v", err)
+		}
+	})
+	fn(tx)
+}
+
+// goldenEnvVar gates golden transcript and plan capture. Unset (or false),
+// EnableGolden/EnableAssertPlan return a plain passthrough *DB and
+// AssertGolden/AssertPlan are no-ops, so the same test file is safe to run in
+// unit-only CI stages that have no database to EXPLAIN against.
+const goldenEnvVar = "PGXKIT_GOLDEN"
+
+// goldenEnabled reports whether PGXKIT_GOLDEN is set to a true value.
+func goldenEnabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv(goldenEnvVar))
+	return err == nil && enabled
+}
+
+// passthroughDB returns a *DB sharing tdb's pools with no capture hooks
+// installed. It stands in for the golden/plan DB when the gate is off.
+func (tdb *TestDB) passthroughDB() *DB {
+	return &DB{
+		readPool:  tdb.readPool,
+		writePool: tdb.writePool,
+		hooks:     newHooks(),
+	}
+}
+
+// GoldenDir sets the directory golden transcripts and plans are stored
+// under, in place of testdata: transcripts go to <path>/golden and plans to
+// <path>/plans. It affects EnableGolden and EnableAssertPlan calls made
+// afterwards.
+func (tdb *TestDB) GoldenDir(path string) {
+	tdb.goldenDir = path
+}
+
+// GoldenPackageNamespace namespaces baselines by the import path of the
+// package calling EnableGolden or EnableAssertPlan, e.g.
+// testdata/golden/github.com/acme/app/store/TestCreate.json, so packages
+// sharing a GoldenDir can reuse test names without colliding. Call
+// EnableGolden and EnableAssertPlan directly from the test package when it is
+// on; the namespace is taken from their caller.
+func (tdb *TestDB) GoldenPackageNamespace(enabled bool) {
+	tdb.goldenByPackage = enabled
+}
+
+// baselinePath returns where the baseline of the given kind ("golden" or
+// "plans") for testName lives. skip counts the frames between the caller
+// and the Enable method whose caller names the package.
+func (tdb *TestDB) baselinePath(kind, testName string, skip int) string {
+	var namespace string
+	if tdb.goldenByPackage {
+		namespace = callerPackage(skip + 1)
+	}
+	return baselinePath(tdb.goldenDir, kind, namespace, testName)
+}
+
 // GoldenOption configures the assertGoldenHook installed by EnableGolden.
 type GoldenOption func(*assertGoldenHook)
 
@@ -62,13 +145,14 @@ func WithGoldenNormalizer(fn func(any) (any, bool)) GoldenOption {
 // behind AssertGolden.
 type assertGoldenHook struct {
 	testName   string
+	path       string
 	mu         sync.Mutex
 	events     []transcriptEvent
 	step       int
 	normalizer *normalizer
 }
 
-func (h *assertGoldenHook) afterOp(_ context.Context, sql string, args []any, tag pgconn.CommandTag, err error) error {
+func (h *assertGoldenHook) afterOp(ctx context.Context, sql string, args []any, tag pgconn.CommandTag, err error) error {
 	if err != nil {
 		return nil
 	}
@@ -78,6 +162,7 @@ func (h *assertGoldenHook) afterOp(_ context.Context, sql string, args []any, ta
 	ev := transcriptEvent{
 		Step:  h.step,
 		Event: transcriptEventQuery,
+		Name:  queryNameFrom(ctx),
 		SQL:   sql,
 		Args:  h.normalizer.normalizeArgs(args),
 	}
@@ -119,9 +204,16 @@ func (h *assertGoldenHook) afterTx(_ context.Context, sql string, _ []any, _ pgc
 // EnableGolden returns a *DB that records database events (BEGIN, QUERY,
 // COMMIT, ROLLBACK) for the test scenario via the hook system. Call
 // AssertGolden after the scenario to compare against
-// testdata/golden/<testName>.json.
+// testdata/golden/<testName>.json, or the location set by GoldenDir and
+// GoldenPackageNamespace.
+//
+// Capture only happens when PGXKIT_GOLDEN=1; otherwise the returned *DB runs
+// queries without recording anything.
 func (tdb *TestDB) EnableGolden(testName string, opts ...GoldenOption) *DB {
-	hook := &assertGoldenHook{testName: testName, normalizer: newNormalizer()}
+	if !goldenEnabled() {
+		return tdb.passthroughDB()
+	}
+	hook := &assertGoldenHook{testName: testName, path: tdb.baselinePath("golden", testName, 1), normalizer: newNormalizer()}
 	for _, opt := range opts {
 		opt(hook)
 	}
@@ -137,11 +229,16 @@ func (tdb *TestDB) EnableGolden(testName string, opts ...GoldenOption) *DB {
 	return goldenDB
 }
 
-// AssertGolden compares the captured transcript against
-// testdata/golden/<testName>.json. First run (or with -overwrite-golden) writes
-// the baseline; later runs fail with a unified diff if it changes.
+// AssertGolden compares the captured transcript against the baseline chosen
+// by EnableGolden, testdata/golden/<testName>.json by default. First run (or
+// with -overwrite-golden, -update or PGXKIT_UPDATE_GOLDEN=1) writes the
+// baseline; later runs fail with a unified diff if it changes. It is a
+// no-op unless PGXKIT_GOLDEN=1.
 func (db *DB) AssertGolden(t *testing.T, testName string) {
 	t.Helper()
+	if !goldenEnabled() {
+		return
+	}
 	db.assertGolden(t, testName)
 }
 
@@ -164,7 +261,11 @@ func (db *DB) assertGolden(t goldenT, testName string) {
 		t.Errorf("failed to marshal transcript: %v", err)
 		return
 	}
-	assertBaseline(t, goldenPath(testName), current, "golden transcript", overwriteGolden != nil && *overwriteGolden)
+	path := db.goldenHook.path
+	if path == "" {
+		path = goldenPath(testName)
+	}
+	assertBaselineSummarized(t, path, current, "golden transcript", shouldUpdateBaseline(overwriteGolden), summarizeTranscriptDiff)
 }
 
 func cleanupGolden(testName string) error {
@@ -180,30 +281,174 @@ func cleanupGolden(testName string) error {
 
 var overwritePlan = flag.Bool("overwrite-plan", false, "regenerate testdata/plans baselines instead of asserting")
 
+// PlanOption configures the assertPlanHook installed by EnableAssertPlan.
+type PlanOption func(*assertPlanHook)
+
+// defaultVolatilePlanFields are EXPLAIN JSON keys whose values vary from run to
+// run (timings, buffer counts, actual row counts) and so are stripped before a
+// plan is stored, written, or compared.
+var defaultVolatilePlanFields = []string{
+	"Actual Startup Time",
+	"Actual Total Time",
+	"Actual Rows",
+	"Actual Loops",
+	"Planning Time",
+	"Execution Time",
+	"Planning",
+	"Triggers",
+	"Shared Hit Blocks",
+	"Shared Read Blocks",
+	"Shared Dirtied Blocks",
+	"Shared Written Blocks",
+	"Local Hit Blocks",
+	"Local Read Blocks",
+	"Local Dirtied Blocks",
+	"Local Written Blocks",
+	"Temp Read Blocks",
+	"Temp Written Blocks",
+	"I/O Read Time",
+	"I/O Write Time",
+	"Rows Removed by Filter",
+	"Rows Removed by Index Recheck",
+	"Rows Removed by Join Filter",
+	"Heap Fetches",
+	"Peak Memory Usage",
+	"Memory Usage",
+	"Disk Usage",
+	"Sort Space Used",
+	"Sort Space Type",
+	"Workers Launched",
+}
+
+// WithPlanVolatileFields replaces the set of EXPLAIN JSON keys stripped from
+// captured plans. Structural keys such as "Node Type", "Relation Name" and
+// "Index Name" should not be listed. Pass no fields to disable stripping.
+func WithPlanVolatileFields(fields ...string) PlanOption {
+	return func(h *assertPlanHook) {
+		h.volatileFields = newVolatileFieldSet(fields)
+	}
+}
+
+// planCostFields are stripped from plans captured WithPlanEstimates, which
+// keep only the row estimates: costs and widths shift with every stats
+// refresh and would make baselines churn.
+var planCostFields = []string{"Startup Cost", "Total Cost", "Plan Width"}
+
+// WithPlanEstimates captures plans with the planner's estimated row counts
+// ("Plan Rows"), so the AssertPlan failure summary can report changes such
+// as "estimated rows 100 → 50000". Costs and widths are still stripped.
+// Estimates depend on table statistics, so baselines captured this way are
+// only stable when the test data and ANALYZE runs are deterministic.
+func WithPlanEstimates() PlanOption {
+	return func(h *assertPlanHook) {
+		h.estimates = true
+	}
+}
+
+// WithPlanAnalyze captures plans with EXPLAIN ANALYZE, which runs each
+// statement to report the plan the executor actually used. The EXPLAIN
+// runs in its own transaction on the write pool that is always rolled back,
+// so INSERT, UPDATE and DELETE leave no rows behind before the real
+// statement runs; sequences still advance and other non-transactional side
+// effects still happen. Run-dependent fields such as actual rows and
+// timings are stripped as usual.
+//
+// Statements run in a transaction from the plan DB's BeginTx are explained
+// inside that transaction instead, under a savepoint that is rolled back, so
+// the transaction's own changes and locks are visible and left as they were.
+func WithPlanAnalyze() PlanOption {
+	return func(h *assertPlanHook) {
+		h.analyze = true
+	}
+}
+
+func newVolatileFieldSet(fields []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		set[f] = struct{}{}
+	}
+	return set
+}
+
 // EnableAssertPlan returns a *DB that captures the structural EXPLAIN plan
-// of each SELECT/INSERT/UPDATE/DELETE/WITH query into memory.
-func (tdb *TestDB) EnableAssertPlan(testName string) *DB {
+// of each SELECT/INSERT/UPDATE/DELETE/WITH query into memory. Like
+// EnableGolden, capture only happens when PGXKIT_GOLDEN=1. Volatile fields
+// (timings, buffer counts) are stripped from each plan; see
+// WithPlanVolatileFields.
+func (tdb *TestDB) EnableAssertPlan(testName string, opts ...PlanOption) *DB {
+	if !goldenEnabled() {
+		return tdb.passthroughDB()
+	}
 	planDB := &DB{
 		readPool:  tdb.readPool,
 		writePool: tdb.writePool,
 		hooks:     newHooks(),
 	}
-	planHook := &assertPlanHook{testName: testName, db: planDB}
+	planHook := &assertPlanHook{
+		testName:       testName,
+		path:           tdb.baselinePath("plans", testName, 1),
+		db:             planDB,
+		volatileFields: newVolatileFieldSet(defaultVolatilePlanFields),
+	}
+	for _, opt := range opts {
+		opt(planHook)
+	}
+	if planHook.estimates {
+		for _, f := range planCostFields {
+			planHook.volatileFields[f] = struct{}{}
+		}
+	}
 	planDB.planHook = planHook
 	planDB.hooks.addHook(BeforeOperation, planHook.captureExplainPlan)
 	return planDB
 }
 
 type assertPlanHook struct {
-	testName string
-	mu       sync.Mutex
-	plans    []QueryPlan
-	db       *DB
+	testName       string
+	path           string
+	mu             sync.Mutex
+	plans          []QueryPlan
+	db             *DB
+	volatileFields map[string]struct{}
+	estimates      bool
+	analyze        bool
+}
+
+// stripVolatile removes the hook's volatile keys from a decoded EXPLAIN JSON
+// document, recursing through nested "Plans" and any other maps or slices.
+func (g *assertPlanHook) stripVolatile(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if _, volatile := g.volatileFields[key]; volatile {
+				delete(v, key)
+				continue
+			}
+			v[key] = g.stripVolatile(child)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = g.stripVolatile(child)
+		}
+		return v
+	}
+	return value
 }
 
-// QueryPlan is one captured structural query plan.
+// normalizePlan strips volatile fields from every top-level EXPLAIN entry.
+func (g *assertPlanHook) normalizePlan(plan []map[string]interface{}) []map[string]interface{} {
+	for i, entry := range plan {
+		plan[i] = g.stripVolatile(entry).(map[string]interface{})
+	}
+	return plan
+}
+
+// QueryPlan is one captured structural query plan. Name is set when the
+// query ran under WithQueryName.
 type QueryPlan struct {
 	Query int                      `json:"query"`
+	Name  string                   `json:"name,omitempty"`
 	SQL   string                   `json:"sql"`
 	Plan  []map[string]interface{} `json:"plan"`
 }
@@ -223,22 +468,10 @@ func (g *assertPlanHook) captureExplainPlan(ctx context.Context, sql string, arg
 		!strings.HasPrefix(upperSQL, "WITH") {
 		return nil
 	}
-	explainSQL := fmt.Sprintf("EXPLAIN (FORMAT JSON, COSTS OFF) %s", sql)
-
-	var explainResult string
-	rows, err := g.db.writePool.Query(ctx, explainSQL, args...)
+	explainResult, err := g.explain(ctx, sql, args)
 	if err != nil {
 		return nil
 	}
-	defer rows.Close()
-	if rows.Next() {
-		if err := rows.Scan(&explainResult); err != nil {
-			return nil
-		}
-	}
-	if rows.Err() != nil {
-		return nil
-	}
 
 	var explainData []map[string]interface{}
 	if err := json.Unmarshal([]byte(explainResult), &explainData); err != nil {
@@ -248,15 +481,53 @@ func (g *assertPlanHook) captureExplainPlan(ctx context.Context, sql string, arg
 	g.mu.Lock()
 	g.plans = append(g.plans, QueryPlan{
 		Query: len(g.plans) + 1,
+		Name:  queryNameFrom(ctx),
 		SQL:   sql,
-		Plan:  explainData,
+		Plan:  g.normalizePlan(explainData),
 	})
 	g.mu.Unlock()
 	return nil
 }
 
+// explain returns the EXPLAIN JSON for sql. A statement running in one of
+// the DB's transactions is explained inside that transaction, under a
+// savepoint that is rolled back, so it sees the transaction's uncommitted
+// changes and a failed EXPLAIN doesn't abort it. Otherwise the EXPLAIN runs
+// on the write pool; under WithPlanAnalyze it gets a transaction of its own
+// that is rolled back.
+func (g *assertPlanHook) explain(ctx context.Context, sql string, args []interface{}) (string, error) {
+	costs := "OFF"
+	if g.estimates {
+		costs = "ON"
+	}
+	var q Executor = g.db.writePool
+	if tx, ok := txFromContext(ctx); ok && tx.db == g.db {
+		savepoint, err := tx.tx.Begin(ctx)
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = savepoint.Rollback(context.WithoutCancel(ctx)) }()
+		q = savepoint
+	} else if g.analyze {
+		tx, err := g.db.writePool.Begin(ctx)
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = tx.Rollback(context.WithoutCancel(ctx)) }()
+		q = tx
+	}
+	analyze := ""
+	if g.analyze {
+		analyze = "ANALYZE, "
+	}
+
+	var result string
+	err := q.QueryRow(ctx, fmt.Sprintf("EXPLAIN (%sFORMAT JSON, COSTS %s) %s", analyze, costs, sql), args...).Scan(&result)
+	return result, err
+}
+
 func planPath(name string) string {
-	return filepath.Join("testdata", "plans", name+".json")
+	return baselinePath("", "plans", "", name)
 }
 
 func marshalPlans(plans []QueryPlan) ([]byte, error) {
@@ -270,9 +541,14 @@ func marshalPlans(plans []QueryPlan) ([]byte, error) {
 	return append(data, '\n'), nil
 }
 
-// AssertPlan compares the captured plans against testdata/plans/<testName>.json.
+// AssertPlan compares the captured plans against the baseline chosen by
+// EnableAssertPlan, testdata/plans/<testName>.json by default.
+// It is a no-op unless PGXKIT_GOLDEN=1.
 func (db *DB) AssertPlan(t *testing.T, testName string) {
 	t.Helper()
+	if !goldenEnabled() {
+		return
+	}
 	db.assertPlan(t, testName)
 }
 
@@ -296,7 +572,11 @@ func (db *DB) assertPlan(t goldenT, testName string) {
 		t.Errorf("failed to marshal plans: %v", err)
 		return
 	}
-	assertBaseline(t, planPath(testName), current, "plan", overwritePlan != nil && *overwritePlan)
+	path := db.planHook.path
+	if path == "" {
+		path = planPath(testName)
+	}
+	assertBaselineSummarized(t, path, current, "plan", shouldUpdateBaseline(overwritePlan), summarizePlanDiff)
 }
 
 // RequireDB ensures a test database is available or skips the test.
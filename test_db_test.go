@@ -2,8 +2,12 @@ package pgxkit
 
 import (
 	"context"
+	"encoding/json"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/jackc/pgx/v5"
 )
 
 func TestNewTestDB(t *testing.T) {
@@ -50,6 +54,45 @@ func TestClean(t *testing.T) {
 	}
 }
 
+func TestRunInRollbackTx(t *testing.T) {
+	testDB := RequireDB(t)
+	if testDB == nil {
+		return
+	}
+	ctx := context.Background()
+
+	_, err := testDB.Exec(ctx, "CREATE TABLE IF NOT EXISTS rollback_tx_test (id SERIAL PRIMARY KEY, name TEXT NOT NULL)")
+	if err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = testDB.Exec(context.Background(), "DROP TABLE IF EXISTS rollback_tx_test")
+	})
+
+	t.Run("inner", func(t *testing.T) {
+		testDB.RunInRollbackTx(t, func(tx *Tx) {
+			if _, err := tx.Exec(ctx, "INSERT INTO rollback_tx_test (name) VALUES ($1)", "ephemeral"); err != nil {
+				t.Fatalf("insert: %v", err)
+			}
+			var n int
+			if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM rollback_tx_test").Scan(&n); err != nil {
+				t.Fatalf("count inside tx: %v", err)
+			}
+			if n != 1 {
+				t.Errorf("expected row visible inside tx, got count %d", n)
+			}
+		})
+	})
+
+	var n int
+	if err := testDB.QueryRow(ctx, "SELECT COUNT(*) FROM rollback_tx_test").Scan(&n); err != nil {
+		t.Fatalf("count after rollback: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("expected row to be rolled back, got count %d", n)
+	}
+}
+
 func TestEnableAssertPlan(t *testing.T) {
 	testDB := RequireDB(t)
 	if testDB == nil {
@@ -104,6 +147,26 @@ func TestAssertPlan(t *testing.T) {
 	defer cleanupPlan("TestAssertPlan")
 }
 
+func TestAssertPlan_QueryNameCaptured(t *testing.T) {
+	testDB := RequireDB(t)
+	if testDB == nil {
+		return
+	}
+	t.Setenv(goldenEnvVar, "1")
+
+	planDB := testDB.EnableAssertPlan("TestAssertPlan_QueryNameCaptured")
+	ctx := WithQueryName(context.Background(), "SelectOne")
+	var n int
+	if err := planDB.QueryRow(ctx, "SELECT 1").Scan(&n); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+
+	plans := planDB.planHook.plans
+	if len(plans) != 1 || plans[0].Name != "SelectOne" {
+		t.Fatalf("expected one plan named SelectOne, got %+v", plans)
+	}
+}
+
 func TestAssertPlan_RerunIsStable(t *testing.T) {
 	testDB := RequireDB(t)
 	if testDB == nil {
@@ -184,6 +247,67 @@ func TestAssertPlan_OverwriteRegeneratesBaseline(t *testing.T) {
 	}
 }
 
+func TestAssertPlan_NormalizesVolatileFields(t *testing.T) {
+	decode := func(raw string) []map[string]interface{} {
+		var plan []map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &plan); err != nil {
+			t.Fatalf("unmarshal plan: %v", err)
+		}
+		return plan
+	}
+	run1 := decode(`[{"Plan": {"Node Type": "Hash Join", "Actual Total Time": 1.23, "Actual Rows": 10, "Actual Loops": 1,
+		"Shared Hit Blocks": 4, "Plans": [{"Node Type": "Seq Scan", "Relation Name": "users", "Actual Total Time": 0.5},
+		{"Node Type": "Index Scan", "Index Name": "orders_user_id_idx", "Actual Startup Time": 0.01}]},
+		"Planning Time": 0.2, "Execution Time": 1.9}]`)
+	run2 := decode(`[{"Plan": {"Node Type": "Hash Join", "Actual Total Time": 9.87, "Actual Rows": 11, "Actual Loops": 1,
+		"Shared Hit Blocks": 40, "Plans": [{"Node Type": "Seq Scan", "Relation Name": "users", "Actual Total Time": 3.1},
+		{"Node Type": "Index Scan", "Index Name": "orders_user_id_idx", "Actual Startup Time": 0.4}]},
+		"Planning Time": 0.7, "Execution Time": 12.4}]`)
+
+	hook := &assertPlanHook{volatileFields: newVolatileFieldSet(defaultVolatilePlanFields)}
+	a, err := marshalPlans([]QueryPlan{{Query: 1, SQL: "q", Plan: hook.normalizePlan(run1)}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	b, err := marshalPlans([]QueryPlan{{Query: 1, SQL: "q", Plan: hook.normalizePlan(run2)}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(a) != string(b) {
+		t.Errorf("plans differing only in timing should compare equal after normalization:\n%s\nvs\n%s", a, b)
+	}
+	for _, keep := range []string{"Hash Join", "Seq Scan", "users", "orders_user_id_idx"} {
+		if !strings.Contains(string(a), keep) {
+			t.Errorf("normalized plan lost structural value %q", keep)
+		}
+	}
+	if strings.Contains(string(a), "Time") {
+		t.Errorf("normalized plan should not contain timing fields:\n%s", a)
+	}
+}
+
+func TestWithPlanVolatileFields(t *testing.T) {
+	hook := &assertPlanHook{volatileFields: newVolatileFieldSet(defaultVolatilePlanFields)}
+	WithPlanVolatileFields("Relation Name")(hook)
+
+	plan := hook.normalizePlan([]map[string]interface{}{{
+		"Plan": map[string]interface{}{"Node Type": "Seq Scan", "Relation Name": "users", "Actual Total Time": 1.0},
+	}})
+	node := plan[0]["Plan"].(map[string]interface{})
+	if _, ok := node["Relation Name"]; ok {
+		t.Error("custom volatile field should be stripped")
+	}
+	if _, ok := node["Actual Total Time"]; !ok {
+		t.Error("custom set should replace the defaults, not extend them")
+	}
+
+	WithPlanVolatileFields()(hook)
+	plan = hook.normalizePlan([]map[string]interface{}{{"Planning Time": 1.0}})
+	if _, ok := plan[0]["Planning Time"]; !ok {
+		t.Error("an empty field set should disable stripping")
+	}
+}
+
 func TestRequireDB(t *testing.T) {
 	// This test depends on TEST_DATABASE_URL being set
 	originalURL := os.Getenv("TEST_DATABASE_URL")
@@ -286,6 +410,119 @@ func TestPlanDML(t *testing.T) {
 	})
 }
 
+func TestPlanAnalyzeUpdate(t *testing.T) {
+	testDB := RequireDB(t)
+	if testDB == nil {
+		return
+	}
+	t.Setenv(goldenEnvVar, "1")
+	ctx := context.Background()
+
+	_, err := testDB.Exec(ctx, "CREATE TABLE IF NOT EXISTS plan_analyze_counters (id int PRIMARY KEY, n int NOT NULL)")
+	if err != nil {
+		t.Fatalf("Failed to create test table: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = testDB.Exec(context.Background(), "DROP TABLE IF EXISTS plan_analyze_counters")
+	})
+	if _, err := testDB.Exec(ctx, "TRUNCATE plan_analyze_counters; INSERT INTO plan_analyze_counters VALUES (1, 0)"); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	const name = "TestPlanAnalyzeUpdate"
+	defer cleanupPlan(name)
+	_ = cleanupPlan(name)
+
+	run := func() *DB {
+		planDB := testDB.EnableAssertPlan(name, WithPlanAnalyze())
+		if _, err := planDB.Exec(ctx, "UPDATE plan_analyze_counters SET n = n + 1 WHERE id = $1", 1); err != nil {
+			t.Fatalf("UPDATE should not fail: %v", err)
+		}
+		return planDB
+	}
+
+	planDB := run()
+	plans := planDB.planHook.plans
+	if len(plans) != 1 || len(plans[0].Plan) != 1 {
+		t.Fatalf("expected one captured UPDATE plan, got %+v", plans)
+	}
+	root, _ := plans[0].Plan[0]["Plan"].(map[string]interface{})
+	if root["Node Type"] != "ModifyTable" || root["Operation"] != "Update" {
+		t.Errorf("expected a ModifyTable Update root, got %v", root)
+	}
+	if _, ok := root["Actual Rows"]; ok {
+		t.Error("run-dependent ANALYZE fields should be stripped")
+	}
+	planDB.AssertPlan(t, name)
+
+	// The rolled-back EXPLAIN ANALYZE must not apply the update a second time.
+	var n int
+	if err := testDB.QueryRow(ctx, "SELECT n FROM plan_analyze_counters WHERE id = 1").Scan(&n); err != nil || n != 1 {
+		t.Errorf("expected the UPDATE to apply exactly once, got n=%d, %v", n, err)
+	}
+
+	// A second run compares against the baseline written by the first.
+	run().AssertPlan(t, name)
+}
+
+func TestPlanAnalyzeDeleteLeavesRows(t *testing.T) {
+	testDB := RequireDB(t)
+	if testDB == nil {
+		return
+	}
+	t.Setenv(goldenEnvVar, "1")
+	ctx := context.Background()
+
+	_, err := testDB.Exec(ctx, "CREATE TABLE IF NOT EXISTS plan_analyze_items (id int PRIMARY KEY)")
+	if err != nil {
+		t.Fatalf("Failed to create test table: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = testDB.Exec(context.Background(), "DROP TABLE IF EXISTS plan_analyze_items")
+	})
+	if _, err := testDB.Exec(ctx, "TRUNCATE plan_analyze_items; INSERT INTO plan_analyze_items VALUES (1), (2), (3)"); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	planDB := testDB.EnableAssertPlan("TestPlanAnalyzeDeleteLeavesRows", WithPlanAnalyze())
+
+	// Outside a transaction: had the EXPLAIN ANALYZE deleted the row, the
+	// real DELETE would find nothing.
+	tag, err := planDB.Exec(ctx, "DELETE FROM plan_analyze_items WHERE id = $1", 1)
+	if err != nil || tag.RowsAffected() != 1 {
+		t.Fatalf("expected the DELETE itself to remove 1 row, got %d, %v", tag.RowsAffected(), err)
+	}
+
+	// Inside a transaction the EXPLAIN runs under a savepoint of that
+	// transaction, which is left usable.
+	tx, err := planDB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "INSERT INTO plan_analyze_items VALUES (4)"); err != nil {
+		t.Fatalf("INSERT in tx: %v", err)
+	}
+	tag, err = tx.Exec(ctx, "DELETE FROM plan_analyze_items WHERE id > $1", 1)
+	if err != nil || tag.RowsAffected() != 3 {
+		t.Fatalf("expected the DELETE in the tx to see its own insert and remove 3 rows, got %d, %v", tag.RowsAffected(), err)
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+
+	if n := len(planDB.planHook.plans); n != 3 {
+		t.Errorf("expected 3 captured plans, got %d", n)
+	}
+	rows, err := testDB.Query(ctx, "SELECT id FROM plan_analyze_items ORDER BY id")
+	if err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil || len(ids) != 2 || ids[0] != 2 || ids[1] != 3 {
+		t.Errorf("expected rows 2 and 3 to remain, got %v, %v", ids, err)
+	}
+}
+
 func TestPlanCTE(t *testing.T) {
 	testDB := RequireDB(t)
 	if testDB == nil {
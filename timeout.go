@@ -0,0 +1,83 @@
+package pgxkit
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type operationTimeoutKey struct{}
+
+// WithOperationTimeout returns a context that overrides the DB's query
+// timeouts, both WithQueryTimeout and WithDefaultQueryTimeout, for
+// operations run with it. The override replaces them rather than stacking on
+// top, so a heavy report query can be given more time without disabling the
+// global settings:
+//
+//	ctx := pgxkit.WithOperationTimeout(ctx, 2*time.Minute)
+//	rows, err := db.ReadQuery(ctx, reportSQL)
+//
+// A zero or negative d disables the timeout for the call. Deadlines already
+// present on ctx still apply; a context cannot outlive its parent.
+func WithOperationTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, operationTimeoutKey{}, d)
+}
+
+// operationTimeout resolves the timeout for one operation: the per-call
+// override if present, otherwise the WithQueryTimeout cap, tightened to the
+// WithDefaultQueryTimeout value when ctx has no deadline.
+func (db *DB) operationTimeout(ctx context.Context) time.Duration {
+	if d, ok := ctx.Value(operationTimeoutKey{}).(time.Duration); ok {
+		return d
+	}
+	timeout := db.queryTimeout
+	if d := db.defaultQueryTimeout; d > 0 && (timeout <= 0 || d < timeout) {
+		if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+			timeout = d
+		}
+	}
+	return timeout
+}
+
+// operationContext derives the context an operation runs under. The returned
+// cancel func is always non-nil.
+func (db *DB) operationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := db.operationTimeout(ctx)
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// cancelRows releases the operation context once the rows are exhausted or
+// closed. Cancelling earlier would abort the in-flight result stream.
+type cancelRows struct {
+	pgx.Rows
+	cancel context.CancelFunc
+}
+
+func (r *cancelRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.cancel()
+	return false
+}
+
+func (r *cancelRows) Close() {
+	r.Rows.Close()
+	r.cancel()
+}
+
+// cancelRow releases the operation context after Scan, which is when pgx
+// reads the row off the wire.
+type cancelRow struct {
+	row    pgx.Row
+	cancel context.CancelFunc
+}
+
+func (r *cancelRow) Scan(dest ...interface{}) error {
+	defer r.cancel()
+	return r.row.Scan(dest...)
+}
@@ -0,0 +1,237 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestWithQueryTimeout(t *testing.T) {
+	cfg := newConnectConfig()
+
+	WithQueryTimeout(5 * time.Second)(cfg)
+	if cfg.queryTimeout != 5*time.Second {
+		t.Errorf("WithQueryTimeout: expected 5s, got %v", cfg.queryTimeout)
+	}
+
+	WithQueryTimeout(0)(cfg)
+	WithQueryTimeout(-time.Second)(cfg)
+	if cfg.queryTimeout != 5*time.Second {
+		t.Errorf("WithQueryTimeout: non-positive values should be ignored, got %v", cfg.queryTimeout)
+	}
+}
+
+func TestOperationContextUsesDefault(t *testing.T) {
+	db := NewDB()
+	db.queryTimeout = time.Minute
+
+	ctx, cancel := db.operationContext(context.Background())
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("expected default query timeout to set a deadline")
+	}
+	if remaining := time.Until(deadline); remaining > time.Minute || remaining < 50*time.Second {
+		t.Errorf("expected deadline about 1m away, got %v", remaining)
+	}
+}
+
+func TestOperationContextNoDefault(t *testing.T) {
+	db := NewDB()
+
+	ctx, cancel := db.operationContext(context.Background())
+	defer cancel()
+
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("expected no deadline without a default query timeout")
+	}
+}
+
+func TestWithOperationTimeoutOverridesShortDefault(t *testing.T) {
+	db := NewDB()
+	db.queryTimeout = time.Millisecond
+
+	ctx, cancel := db.operationContext(WithOperationTimeout(context.Background(), time.Hour))
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("expected override to set a deadline")
+	}
+	if remaining := time.Until(deadline); remaining < 59*time.Minute {
+		t.Errorf("override should replace the 1ms default, got %v remaining", remaining)
+	}
+}
+
+func TestWithOperationTimeoutZeroDisablesDefault(t *testing.T) {
+	db := NewDB()
+	db.queryTimeout = time.Millisecond
+
+	ctx, cancel := db.operationContext(WithOperationTimeout(context.Background(), 0))
+	defer cancel()
+
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("zero override should disable the default timeout for the call")
+	}
+}
+
+func TestWithDefaultQueryTimeout(t *testing.T) {
+	cfg := newConnectConfig()
+
+	WithDefaultQueryTimeout(2 * time.Second)(cfg)
+	if cfg.defaultTimeout != 2*time.Second {
+		t.Errorf("WithDefaultQueryTimeout: expected 2s, got %v", cfg.defaultTimeout)
+	}
+
+	WithDefaultQueryTimeout(0)(cfg)
+	WithDefaultQueryTimeout(-time.Second)(cfg)
+	if cfg.defaultTimeout != 2*time.Second {
+		t.Errorf("WithDefaultQueryTimeout: non-positive values should be ignored, got %v", cfg.defaultTimeout)
+	}
+}
+
+// slowOperationDB returns a DB whose operations block in a BeforeOperation
+// hook until their context ends or a second passes, reporting how long each
+// one ran and with which error.
+func slowOperationDB(t *testing.T) (*DB, *time.Duration) {
+	t.Helper()
+	db := NewDB()
+	db.writePool = newLazyPool(t)
+	db.readPool = db.writePool
+	elapsed := new(time.Duration)
+	db.hooks.addHook(BeforeOperation, func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+		start := time.Now()
+		defer func() { *elapsed = time.Since(start) }()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return nil
+		}
+	})
+	return db, elapsed
+}
+
+func TestDefaultQueryTimeoutFiresWithoutDeadline(t *testing.T) {
+	db, elapsed := slowOperationDB(t)
+	db.defaultQueryTimeout = 20 * time.Millisecond
+
+	_, err := db.Exec(context.Background(), "SELECT pg_sleep(1)")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected default timeout to cancel the operation, got %v", err)
+	}
+	if *elapsed > 500*time.Millisecond {
+		t.Errorf("default timeout should fire after about 20ms, took %v", *elapsed)
+	}
+
+	if err := db.QueryRow(context.Background(), "SELECT 1").Scan(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected default timeout on QueryRow, got %v", err)
+	}
+	if _, err := db.Query(context.Background(), "SELECT 1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected default timeout on Query, got %v", err)
+	}
+}
+
+func TestDefaultQueryTimeoutKeepsShorterCallerDeadline(t *testing.T) {
+	db, elapsed := slowOperationDB(t)
+	db.defaultQueryTimeout = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := db.Exec(ctx, "SELECT pg_sleep(1)")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected caller deadline to cancel the operation, got %v", err)
+	}
+	if *elapsed > 500*time.Millisecond {
+		t.Errorf("caller deadline of 20ms should win, took %v", *elapsed)
+	}
+}
+
+func TestDefaultQueryTimeoutLeavesLongerCallerDeadline(t *testing.T) {
+	db := NewDB()
+	db.defaultQueryTimeout = time.Millisecond
+
+	parent, cancelParent := context.WithTimeout(context.Background(), time.Hour)
+	defer cancelParent()
+
+	ctx, cancel := db.operationContext(parent)
+	defer cancel()
+
+	deadline, _ := ctx.Deadline()
+	if remaining := time.Until(deadline); remaining < 59*time.Minute {
+		t.Errorf("default timeout should not shorten a caller deadline, got %v remaining", remaining)
+	}
+}
+
+func TestDefaultQueryTimeoutTightensQueryTimeout(t *testing.T) {
+	db := NewDB()
+	db.queryTimeout = time.Hour
+	db.defaultQueryTimeout = time.Minute
+
+	ctx, cancel := db.operationContext(context.Background())
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("expected a deadline")
+	}
+	if remaining := time.Until(deadline); remaining > time.Minute {
+		t.Errorf("deadline-free call should get the shorter default timeout, got %v remaining", remaining)
+	}
+}
+
+func TestCancelRowCancelsAfterScan(t *testing.T) {
+	cancelled := false
+	row := &cancelRow{
+		row:    &mockRow{scanFunc: func(dest ...interface{}) error { return nil }},
+		cancel: func() { cancelled = true },
+	}
+	if err := row.Scan(); err != nil {
+		t.Fatalf("Scan returned unexpected error: %v", err)
+	}
+	if !cancelled {
+		t.Error("cancelRow.Scan should release the operation context")
+	}
+}
+
+func TestOperationTimeoutIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+
+	db := NewDB()
+	db.readPool = pool
+	db.writePool = pool
+	db.queryTimeout = 20 * time.Millisecond
+
+	_, err := db.Exec(context.Background(), "SELECT pg_sleep(0.2)")
+	if err == nil {
+		t.Fatal("expected short default timeout to cancel the query")
+	}
+
+	ctx := WithOperationTimeout(context.Background(), 5*time.Second)
+	if _, err := db.Exec(ctx, "SELECT pg_sleep(0.2)"); err != nil {
+		t.Errorf("long override should let the query finish, got %v", err)
+	}
+
+	var n int
+	if err := db.QueryRow(ctx, "SELECT 1 FROM pg_sleep(0.2)").Scan(&n); err != nil {
+		t.Errorf("QueryRow with override failed: %v", err)
+	}
+
+	rows, err := db.Query(ctx, "SELECT generate_series(1, 3) FROM pg_sleep(0.2)")
+	if err != nil {
+		t.Fatalf("Query with override failed: %v", err)
+	}
+	count := 0
+	for rows.Next() {
+		count++
+	}
+	rows.Close()
+	if rows.Err() != nil || count != 3 {
+		t.Errorf("expected 3 rows without error, got %d rows, err=%v", count, rows.Err())
+	}
+}
@@ -0,0 +1,82 @@
+package pgxkit
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// WithHookTracer installs a pgx.QueryTracer on every pool connection that
+// runs the BeforeOperation and AfterOperation hooks for statements that
+// don't fire them already: sqlc-generated code or anything else using
+// WritePool(), ReadPool(), Acquire or the pgx.Tx behind Tx.Tx(), plus
+// pgxkit's own housekeeping such as advisory lock and Verify queries.
+// Logging, metrics and tracing hooks then see every query whatever the entry
+// point.
+//
+// Statements run through DB, Tx and Session methods already fire the hooks
+// and are not reported a second time, nor are the BEGIN, COMMIT and ROLLBACK
+// that BeginTx, Commit and Rollback send. Traced statements are reported
+// exactly as pgx sends them, without rewrite hooks, and a BeforeOperation
+// error cannot stop the query, so it is ignored; AfterOperation errors are
+// ignored too. The tracer replaces ConnConfig.Tracer, so don't combine it
+// with a WithPoolConstructor that installs its own tracer.
+//
+// Example:
+//
+//	err := db.Connect(ctx, dsn,
+//	    pgxkit.WithHookTracer(),
+//	    pgxkit.WithAfterOperation(logQuery),
+//	)
+//	queries := sqlc.New(db.WritePool()) // logQuery sees these too
+func WithHookTracer() ConnectOption {
+	return func(c *connectConfig) {
+		c.hookTracer = true
+	}
+}
+
+// hookTracer forwards pgx query tracing into the operation hooks.
+type hookTracer struct {
+	hooks *hooks
+}
+
+// untracedKey marks a context whose statements pgxkit reports itself.
+type untracedKey struct{}
+
+// withoutTracer marks ctx so hookTracer skips statements run with it.
+func withoutTracer(ctx context.Context) context.Context {
+	return context.WithValue(ctx, untracedKey{}, struct{}{})
+}
+
+// tracerSkips reports whether the statement's hooks already ran, or will run,
+// in pgxkit: operation methods attach hook state before calling pgx.
+func tracerSkips(ctx context.Context) bool {
+	return ctx.Value(hookStateKey{}) != nil || ctx.Value(untracedKey{}) != nil
+}
+
+// tracedQueryKey carries a traced statement from TraceQueryStart to
+// TraceQueryEnd.
+type tracedQueryKey struct{}
+
+type tracedQuery struct {
+	sql  string
+	args []interface{}
+}
+
+func (t *hookTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if tracerSkips(ctx) {
+		return ctx
+	}
+	ctx = withHookState(ctx)
+	_ = t.hooks.executeBeforeOperation(ctx, data.SQL, data.Args, pgconn.CommandTag{}, nil)
+	return context.WithValue(ctx, tracedQueryKey{}, &tracedQuery{sql: data.SQL, args: data.Args})
+}
+
+func (t *hookTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	q, ok := ctx.Value(tracedQueryKey{}).(*tracedQuery)
+	if !ok {
+		return
+	}
+	_ = t.hooks.executeAfterOperation(ctx, q.sql, q.args, data.CommandTag, data.Err)
+}
@@ -0,0 +1,155 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// tracerRecorder collects the SQL seen by operation hooks.
+type tracerRecorder struct {
+	mu     sync.Mutex
+	before []string
+	after  []string
+	errs   []error
+}
+
+func (r *tracerRecorder) hooks() *hooks {
+	h := newHooks()
+	h.addHook(BeforeOperation, func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.before = append(r.before, sql)
+		return nil
+	})
+	h.addHook(AfterOperation, func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.after = append(r.after, sql)
+		r.errs = append(r.errs, err)
+		return nil
+	})
+	return h
+}
+
+func (r *tracerRecorder) count(sql string) (before, after int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.before {
+		if s == sql {
+			before++
+		}
+	}
+	for _, s := range r.after {
+		if s == sql {
+			after++
+		}
+	}
+	return before, after
+}
+
+func TestHookTracerForwardsToHooks(t *testing.T) {
+	rec := &tracerRecorder{}
+	tracer := &hookTracer{hooks: rec.hooks()}
+	queryErr := errors.New("boom")
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1", Args: []any{1}})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: queryErr})
+
+	if before, after := rec.count("SELECT 1"); before != 1 || after != 1 {
+		t.Fatalf("expected one before and one after hook call, got %d and %d", before, after)
+	}
+	if !errors.Is(rec.errs[0], queryErr) {
+		t.Errorf("expected the query error to reach the after hook, got %v", rec.errs[0])
+	}
+}
+
+func TestHookTracerSkipsHookedOperations(t *testing.T) {
+	rec := &tracerRecorder{}
+	tracer := &hookTracer{hooks: rec.hooks()}
+
+	for name, ctx := range map[string]context.Context{
+		"hooked operation": withHookState(context.Background()),
+		"transaction":      withoutTracer(context.Background()),
+	} {
+		ctx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: name})
+		tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+		if before, after := rec.count(name); before != 0 || after != 0 {
+			t.Errorf("%s: expected no hook calls, got %d and %d", name, before, after)
+		}
+	}
+}
+
+func TestWithHookTracerSetsConnTracer(t *testing.T) {
+	config, err := pgxpool.ParseConfig("postgres://user@127.0.0.1:1/db")
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	cfg := newConnectConfig()
+	cfg.applyConnConfig(config)
+	if config.ConnConfig.Tracer != nil {
+		t.Fatal("no tracer should be installed by default")
+	}
+
+	WithHookTracer()(cfg)
+	cfg.applyConnConfig(config)
+	if tracer, ok := config.ConnConfig.Tracer.(*hookTracer); !ok || tracer.hooks != cfg.hooks {
+		t.Errorf("expected a hookTracer bound to the connect hooks, got %T", config.ConnConfig.Tracer)
+	}
+}
+
+func TestHookTracerIntegration(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+	ctx := context.Background()
+
+	rec := &tracerRecorder{}
+	h := rec.hooks()
+	db := NewDB()
+	err := db.Connect(ctx, dsn, WithMaxConns(2), WithHookTracer(),
+		WithBeforeOperation(h.beforeOperation[0]), WithAfterOperation(h.afterOperation[0]))
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer db.Shutdown(ctx)
+
+	const rawSQL = "SELECT 'raw pool'"
+	if _, err := db.WritePool().Exec(ctx, rawSQL); err != nil {
+		t.Fatalf("raw Exec failed: %v", err)
+	}
+	if before, after := rec.count(rawSQL); before != 1 || after != 1 {
+		t.Errorf("expected hooks to see the raw pool query once, got %d before and %d after", before, after)
+	}
+
+	const kitSQL = "SELECT 'pgxkit'"
+	if _, err := db.Exec(ctx, kitSQL); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if before, after := rec.count(kitSQL); before != 1 || after != 1 {
+		t.Errorf("expected pgxkit's own query to fire hooks once, got %d before and %d after", before, after)
+	}
+
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	for _, sql := range rec.before {
+		if s := strings.ToLower(sql); strings.HasPrefix(s, "begin") || s == "commit" {
+			t.Errorf("transaction control should not reach operation hooks, got %q", sql)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package pgxkit
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// identifierPattern matches a plain, unquoted PostgreSQL identifier.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+// migrationTables are left alone by TruncateAll so migration state survives.
+var migrationTables = map[string]struct{}{
+	"schema_migrations":      {},
+	"goose_db_version":       {},
+	"atlas_schema_revisions": {},
+	"flyway_schema_history":  {},
+	"gorp_migrations":        {},
+}
+
+// parseTableName validates a table name of the form "table" or
+// "schema.table" and returns it as a pgx.Identifier. The parts are folded to
+// lower case, as PostgreSQL folds unquoted names, so "Users" and
+// "Public.users" name the same table they would in SQL.
+func parseTableName(name string) (pgx.Identifier, error) {
+	parts := strings.Split(name, ".")
+	if len(parts) > 2 {
+		return nil, fmt.Errorf("invalid table name %q", name)
+	}
+	for i, part := range parts {
+		if !identifierPattern.MatchString(part) {
+			return nil, fmt.Errorf("invalid table name %q", name)
+		}
+		parts[i] = strings.ToLower(part)
+	}
+	return pgx.Identifier(parts), nil
+}
+
+// buildTruncate returns a single TRUNCATE statement covering tables.
+func buildTruncate(tables []pgx.Identifier) string {
+	quoted := make([]string, len(tables))
+	for i, table := range tables {
+		quoted[i] = table.Sanitize()
+	}
+	return "TRUNCATE " + strings.Join(quoted, ", ") + " RESTART IDENTITY CASCADE"
+}
+
+// TruncateTables empties the given tables in one
+// TRUNCATE ... RESTART IDENTITY CASCADE statement. Names may be
+// schema-qualified and must be plain identifiers; anything else is rejected
+// before reaching the database. Like unquoted names in SQL, they are not
+// case-sensitive.
+//
+// Example:
+//
+//	t.Cleanup(func() {
+//	    _ = testDB.TruncateTables(context.Background(), "orders", "users")
+//	})
+func (tdb *TestDB) TruncateTables(ctx context.Context, tables ...string) error {
+	if len(tables) == 0 {
+		return fmt.Errorf("no tables to truncate")
+	}
+	idents := make([]pgx.Identifier, len(tables))
+	for i, table := range tables {
+		ident, err := parseTableName(table)
+		if err != nil {
+			return err
+		}
+		idents[i] = ident
+	}
+	return tdb.truncate(ctx, idents)
+}
+
+func (tdb *TestDB) truncate(ctx context.Context, tables []pgx.Identifier) error {
+	if _, err := tdb.Exec(ctx, buildTruncate(tables)); err != nil {
+		return fmt.Errorf("failed to truncate tables: %w", err)
+	}
+	return nil
+}
+
+// TruncateAll truncates every user table visible in information_schema in
+// one statement, skipping the system schemas and common migration-tracking
+// tables (schema_migrations, goose_db_version, and similar).
+func (tdb *TestDB) TruncateAll(ctx context.Context) error {
+	rows, err := tdb.Query(ctx, `
+		SELECT table_schema, table_name
+		FROM information_schema.tables
+		WHERE table_type = 'BASE TABLE'
+		  AND table_schema NOT IN ('pg_catalog', 'information_schema')
+		  AND table_schema NOT LIKE 'pg\_%'
+		ORDER BY table_schema, table_name`)
+	if err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+	var tables []pgx.Identifier
+	for rows.Next() {
+		var schema, name string
+		if err := rows.Scan(&schema, &name); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan table name: %w", err)
+		}
+		if _, ok := migrationTables[name]; ok {
+			continue
+		}
+		tables = append(tables, pgx.Identifier{schema, name})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+	if len(tables) == 0 {
+		return nil
+	}
+	return tdb.truncate(ctx, tables)
+}
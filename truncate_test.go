@@ -0,0 +1,139 @@
+package pgxkit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestBuildTruncate(t *testing.T) {
+	got := buildTruncate([]pgx.Identifier{{"users"}, {"app", "orders"}})
+	want := `TRUNCATE "users", "app"."orders" RESTART IDENTITY CASCADE`
+	if got != want {
+		t.Errorf("buildTruncate = %q, want %q", got, want)
+	}
+}
+
+func TestTruncateTablesValidation(t *testing.T) {
+	tdb := NewTestDB()
+	ctx := context.Background()
+	if err := tdb.TruncateTables(ctx); err == nil {
+		t.Error("expected error with no tables")
+	}
+	if err := tdb.TruncateTables(ctx, "users", "orders; DROP TABLE users"); err == nil || !strings.Contains(err.Error(), "invalid table name") {
+		t.Errorf("expected invalid table name error, got %v", err)
+	}
+}
+
+func TestParseTableName(t *testing.T) {
+	valid := map[string]string{
+		"users":        `"users"`,
+		"public.users": `"public"."users"`,
+		"_tmp$1":       `"_tmp$1"`,
+		"Users":        `"users"`,
+		"Public.Users": `"public"."users"`,
+	}
+	for name, want := range valid {
+		ident, err := parseTableName(name)
+		if err != nil {
+			t.Errorf("parseTableName(%q) returned unexpected error: %v", name, err)
+			continue
+		}
+		if got := ident.Sanitize(); got != want {
+			t.Errorf("parseTableName(%q) = %s, want %s", name, got, want)
+		}
+	}
+
+	for _, name := range []string{"", "users; DROP TABLE users", `"users"`, "a.b.c", "1users", "users--", "public."} {
+		if _, err := parseTableName(name); err == nil {
+			t.Errorf("parseTableName(%q) should be rejected", name)
+		}
+	}
+}
+
+func TestTruncateTablesIntegration(t *testing.T) {
+	testDB := RequireDB(t)
+	if testDB == nil {
+		return
+	}
+	ctx := context.Background()
+	if _, err := testDB.Exec(ctx, "CREATE TABLE IF NOT EXISTS truncate_test (id SERIAL PRIMARY KEY, name TEXT)"); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = testDB.Exec(context.Background(), "DROP TABLE IF EXISTS truncate_test")
+	})
+	if _, err := testDB.Exec(ctx, "INSERT INTO truncate_test (name) VALUES ('a'), ('b')"); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	if err := testDB.TruncateTables(ctx, "Public.Truncate_Test"); err != nil {
+		t.Fatalf("TruncateTables failed: %v", err)
+	}
+
+	var count int
+	if err := testDB.QueryRow(ctx, "SELECT COUNT(*) FROM truncate_test").Scan(&count); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected empty table after truncate, got %d rows", count)
+	}
+
+	var id int
+	if err := testDB.QueryRow(ctx, "INSERT INTO truncate_test (name) VALUES ('c') RETURNING id").Scan(&id); err != nil {
+		t.Fatalf("insert after truncate: %v", err)
+	}
+	if id != 1 {
+		t.Errorf("expected identity to restart at 1, got %d", id)
+	}
+}
+
+func TestTruncateAllKeepsMigrations(t *testing.T) {
+	testDB := RequireDB(t)
+	if testDB == nil {
+		return
+	}
+	ctx := context.Background()
+	var hadMigrations bool
+	if err := testDB.QueryRow(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&hadMigrations); err != nil {
+		t.Fatalf("check schema_migrations: %v", err)
+	}
+	for _, stmt := range []string{
+		"CREATE TABLE IF NOT EXISTS truncate_all_test (id INT)",
+		"CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT)",
+		"INSERT INTO truncate_all_test VALUES (1)",
+		"INSERT INTO schema_migrations VALUES (42)",
+	} {
+		if _, err := testDB.Exec(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	t.Cleanup(func() {
+		_, _ = testDB.Exec(context.Background(), "DROP TABLE IF EXISTS truncate_all_test")
+		if hadMigrations {
+			_, _ = testDB.Exec(context.Background(), "DELETE FROM schema_migrations WHERE version = 42")
+		} else {
+			_, _ = testDB.Exec(context.Background(), "DROP TABLE IF EXISTS schema_migrations")
+		}
+	})
+
+	if err := testDB.TruncateAll(ctx); err != nil {
+		t.Fatalf("TruncateAll failed: %v", err)
+	}
+
+	var count int
+	if err := testDB.QueryRow(ctx, "SELECT COUNT(*) FROM truncate_all_test").Scan(&count); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected user table to be empty, got %d rows", count)
+	}
+	if err := testDB.QueryRow(ctx, "SELECT COUNT(*) FROM schema_migrations WHERE version = 42").Scan(&count); err != nil {
+		t.Fatalf("count migrations: %v", err)
+	}
+	if count != 1 {
+		t.Error("TruncateAll should leave migration tables alone")
+	}
+}
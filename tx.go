@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync/atomic"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -17,6 +18,33 @@ const (
 
 var ErrTxFinalized = errors.New("transaction already finalized")
 
+// ErrTooManyOpenTransactions is returned by BeginTx when the limit set with
+// WithMaxOpenTransactions is reached.
+var ErrTooManyOpenTransactions = errors.New("too many open transactions")
+
+// WithMaxOpenTransactions caps how many transactions begun with BeginTx may
+// be open at once. Beyond n, BeginTx fails fast with an error wrapping
+// ErrTooManyOpenTransactions instead of blocking on an exhausted pool, which
+// turns a transaction leak (a missing Commit or Rollback) into a clear error.
+// Set n below the write pool's MaxConns to leave room for plain queries.
+// Committing or rolling back frees a slot. n <= 0 means no limit, the
+// default.
+func WithMaxOpenTransactions(n int) ConnectOption {
+	return func(c *connectConfig) {
+		c.maxOpenTx = n
+	}
+}
+
+// reserveTxSlot claims an open-transaction slot, reporting false when the
+// WithMaxOpenTransactions limit is reached.
+func (db *DB) reserveTxSlot() bool {
+	if db.openTx.Add(1) > int64(db.maxOpenTx) {
+		db.openTx.Add(-1)
+		return false
+	}
+	return true
+}
+
 type finalizedRow struct{}
 
 func (f *finalizedRow) Scan(dest ...any) error {
@@ -25,6 +53,22 @@ func (f *finalizedRow) Scan(dest ...any) error {
 
 var _ Executor = (*Tx)(nil)
 
+// txContextKey carries the *Tx into the operation hooks of statements run on
+// it, and the finalizing *Tx into AfterTransaction and AfterCommitSuccess
+// hooks, so per-transaction state can be looked up.
+type txContextKey struct{}
+
+func txFromContext(ctx context.Context) (*Tx, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*Tx)
+	return tx, ok && tx != nil
+}
+
+// withoutTx hides any *Tx carried by ctx, for statements that a transaction
+// hook runs on the DB after the transaction has finished.
+func withoutTx(ctx context.Context) context.Context {
+	return context.WithValue(ctx, txContextKey{}, (*Tx)(nil))
+}
+
 // Tx wraps a pgx.Tx to implement the Executor interface and provide
 // transaction lifecycle management integrated with pgxkit's activeOps tracking
 // and hook system.
@@ -32,6 +76,28 @@ type Tx struct {
 	tx        pgx.Tx
 	db        *DB
 	finalized atomic.Bool
+
+	// done, if set, is called once the underlying transaction has ended
+	// (see WithConnAffinity).
+	done func()
+
+	// statements is non-nil when WithRollbackLog is enabled.
+	statements *txStatements
+
+	startedAt      time.Time
+	options        pgx.TxOptions
+	statementCount atomic.Int64
+
+	// holdsSlot is set when the transaction counts against
+	// WithMaxOpenTransactions.
+	holdsSlot bool
+}
+
+// releaseSlot frees the transaction's WithMaxOpenTransactions slot, if any.
+func (t *Tx) releaseSlot() {
+	if t.holdsSlot {
+		t.db.openTx.Add(-1)
+	}
 }
 
 // Query executes a query within the transaction. Fires BeforeOperation /
@@ -40,9 +106,16 @@ func (t *Tx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Ro
 	if t.finalized.Load() {
 		return nil, ErrTxFinalized
 	}
+	sql, args, err := t.db.hooks.executeRewrite(ctx, sql, args)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(withHookState(ctx), txContextKey{}, t)
 	if err := t.db.hooks.executeBeforeOperation(ctx, sql, args, pgconn.CommandTag{}, nil); err != nil {
 		return nil, fmt.Errorf("before operation hook failed: %w", err)
 	}
+	t.statementCount.Add(1)
+	t.recordStatement(sql, args)
 	rows, err := t.tx.Query(ctx, sql, args...)
 	if hookErr := t.db.hooks.executeAfterOperation(ctx, sql, args, pgconn.CommandTag{}, err); hookErr != nil {
 		if rows != nil {
@@ -61,9 +134,16 @@ func (t *Tx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.
 	if t.finalized.Load() {
 		return &finalizedRow{}
 	}
+	sql, args, err := t.db.hooks.executeRewrite(ctx, sql, args)
+	if err != nil {
+		return &shutdownRow{err: err}
+	}
+	ctx = context.WithValue(withHookState(ctx), txContextKey{}, t)
 	if err := t.db.hooks.executeBeforeOperation(ctx, sql, args, pgconn.CommandTag{}, nil); err != nil {
 		return &shutdownRow{err: fmt.Errorf("before operation hook failed: %w", err)}
 	}
+	t.statementCount.Add(1)
+	t.recordStatement(sql, args)
 	row := t.tx.QueryRow(ctx, sql, args...)
 	if hookErr := t.db.hooks.executeAfterOperation(ctx, sql, args, pgconn.CommandTag{}, nil); hookErr != nil {
 		return &shutdownRow{err: fmt.Errorf("after operation hook failed: %w", hookErr)}
@@ -77,9 +157,16 @@ func (t *Tx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.
 	if t.finalized.Load() {
 		return pgconn.CommandTag{}, ErrTxFinalized
 	}
+	sql, args, err := t.db.hooks.executeRewrite(ctx, sql, args)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	ctx = context.WithValue(withHookState(ctx), txContextKey{}, t)
 	if err := t.db.hooks.executeBeforeOperation(ctx, sql, args, pgconn.CommandTag{}, nil); err != nil {
 		return pgconn.CommandTag{}, fmt.Errorf("before operation hook failed: %w", err)
 	}
+	t.statementCount.Add(1)
+	t.recordStatement(sql, args)
 	tag, err := t.tx.Exec(ctx, sql, args...)
 	if hookErr := t.db.hooks.executeAfterOperation(ctx, sql, args, tag, err); hookErr != nil {
 		if err == nil {
@@ -91,14 +178,28 @@ func (t *Tx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.
 
 // Commit commits the transaction and fires AfterTransaction. Atomic
 // finalization makes "defer Rollback() + explicit Commit()" safe.
+// AfterCommitSuccess fires only if the commit succeeds.
 func (t *Tx) Commit(ctx context.Context) error {
 	if !t.finalized.CompareAndSwap(false, true) {
 		return nil
 	}
 	defer t.db.activeOps.Done()
+	defer t.releaseSlot()
 
-	err := t.tx.Commit(ctx)
+	err := t.tx.Commit(withoutTracer(ctx))
+	if t.done != nil {
+		t.done()
+	}
+	if err != nil {
+		t.logRollback(ctx, "commit failed", err)
+	}
+	ctx = context.WithValue(ctx, txContextKey{}, t)
 	hookErr := t.db.hooks.executeAfterTransaction(ctx, TxCommit, nil, pgconn.CommandTag{}, err)
+	if err == nil {
+		if successErr := t.db.hooks.executeAfterCommitSuccess(ctx, TxCommit, nil, pgconn.CommandTag{}, nil); successErr != nil {
+			hookErr = errors.Join(hookErr, successErr)
+		}
+	}
 	if hookErr != nil {
 		if err != nil {
 			return errors.Join(err, fmt.Errorf("after commit hook failed: %w", hookErr))
@@ -115,8 +216,14 @@ func (t *Tx) Rollback(ctx context.Context) error {
 		return nil
 	}
 	defer t.db.activeOps.Done()
+	defer t.releaseSlot()
 
-	err := t.tx.Rollback(ctx)
+	err := t.tx.Rollback(withoutTracer(ctx))
+	if t.done != nil {
+		t.done()
+	}
+	t.logRollback(ctx, "rollback", err)
+	ctx = context.WithValue(ctx, txContextKey{}, t)
 	hookErr := t.db.hooks.executeAfterTransaction(ctx, TxRollback, nil, pgconn.CommandTag{}, err)
 	if hookErr != nil {
 		if err != nil {
@@ -137,3 +244,35 @@ func (t *Tx) Tx() pgx.Tx {
 func (t *Tx) IsFinalized() bool {
 	return t.finalized.Load()
 }
+
+// TxInfo is a snapshot of a transaction's state, for debugging.
+type TxInfo struct {
+	// StartedAt is when BeginTx returned the transaction.
+	StartedAt time.Time
+	// IsoLevel and AccessMode are as requested in the TxOptions passed to
+	// BeginTx; empty means the server default.
+	IsoLevel   pgx.TxIsoLevel
+	AccessMode pgx.TxAccessMode
+	// Finalized is true once Commit or Rollback has been called.
+	Finalized bool
+	// Statements counts the Query, QueryRow and Exec calls sent to the
+	// server through the Tx, whether or not they succeeded. Calls stopped by
+	// ErrTxFinalized or a BeforeOperation hook are not counted.
+	Statements int
+}
+
+// Info returns a snapshot of the transaction's state.
+//
+// Example:
+//
+//	info := tx.Info()
+//	slog.Debug("tx", "age", time.Since(info.StartedAt), "iso", info.IsoLevel, "statements", info.Statements)
+func (t *Tx) Info() TxInfo {
+	return TxInfo{
+		StartedAt:  t.startedAt,
+		IsoLevel:   t.options.IsoLevel,
+		AccessMode: t.options.AccessMode,
+		Finalized:  t.finalized.Load(),
+		Statements: int(t.statementCount.Load()),
+	}
+}
@@ -6,17 +6,19 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type mockTx struct {
-	queryFunc    func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
-	queryRowFunc func(ctx context.Context, sql string, args ...interface{}) pgx.Row
-	execFunc     func(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
-	commitFunc   func(ctx context.Context) error
-	rollbackFunc func(ctx context.Context) error
+	queryFunc     func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	queryRowFunc  func(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	execFunc      func(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	commitFunc    func(ctx context.Context) error
+	rollbackFunc  func(ctx context.Context) error
+	sendBatchFunc func(ctx context.Context, b *pgx.Batch) pgx.BatchResults
 }
 
 func (m *mockTx) Begin(ctx context.Context) (pgx.Tx, error) { return nil, nil }
@@ -35,8 +37,13 @@ func (m *mockTx) Rollback(ctx context.Context) error {
 func (m *mockTx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
 	return 0, nil
 }
-func (m *mockTx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults { return nil }
-func (m *mockTx) LargeObjects() pgx.LargeObjects                               { return pgx.LargeObjects{} }
+func (m *mockTx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	if m.sendBatchFunc != nil {
+		return m.sendBatchFunc(ctx, b)
+	}
+	return nil
+}
+func (m *mockTx) LargeObjects() pgx.LargeObjects { return pgx.LargeObjects{} }
 func (m *mockTx) Prepare(ctx context.Context, name, sql string) (*pgconn.StatementDescription, error) {
 	return nil, nil
 }
@@ -204,6 +211,32 @@ func TestTxExec(t *testing.T) {
 	}
 }
 
+func TestTxOperationHooksSeeTx(t *testing.T) {
+	db := NewDB()
+	var seen []*Tx
+	db.hooks.addHook(BeforeOperation, func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, err error) error {
+		tx, _ := txFromContext(ctx)
+		seen = append(seen, tx)
+		return nil
+	})
+
+	db.activeOps.Add(1)
+	tx := &Tx{tx: &mockTx{}, db: db}
+	ctx := context.Background()
+	_, _ = tx.Query(ctx, "SELECT 1")
+	_ = tx.QueryRow(ctx, "SELECT 1")
+	_, _ = tx.Exec(ctx, "SELECT 1")
+
+	if len(seen) != 3 {
+		t.Fatalf("expected 3 hook calls, got %d", len(seen))
+	}
+	for i, got := range seen {
+		if got != tx {
+			t.Errorf("hook %d: expected the operation's Tx in the context, got %v", i, got)
+		}
+	}
+}
+
 func TestTxExecError(t *testing.T) {
 	db := NewDB()
 	expectedErr := errors.New("exec failed")
@@ -932,3 +965,246 @@ func TestTxConcurrentCommitRollbackRace(t *testing.T) {
 		t.Error("Transaction should be finalized after concurrent operations")
 	}
 }
+
+func TestTxAfterCommitSuccessFiresOnCommit(t *testing.T) {
+	db := NewDB()
+
+	var order []string
+	var gotSQL string
+	db.hooks.addHook(AfterTransaction, func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error {
+		order = append(order, "after-transaction")
+		return nil
+	})
+	db.hooks.addHook(AfterCommitSuccess, func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error {
+		order = append(order, "after-commit-success")
+		gotSQL = sql
+		return nil
+	})
+
+	db.activeOps.Add(1)
+	tx := &Tx{tx: &mockTx{}, db: db}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		t.Fatalf("Commit returned unexpected error: %v", err)
+	}
+	if len(order) != 2 || order[0] != "after-transaction" || order[1] != "after-commit-success" {
+		t.Errorf("expected AfterTransaction then AfterCommitSuccess, got %v", order)
+	}
+	if gotSQL != TxCommit {
+		t.Errorf("AfterCommitSuccess should receive %q, got %q", TxCommit, gotSQL)
+	}
+}
+
+func TestTxAfterCommitSuccessSkippedOnRollback(t *testing.T) {
+	db := NewDB()
+
+	called := false
+	db.hooks.addHook(AfterCommitSuccess, func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error {
+		called = true
+		return nil
+	})
+
+	db.activeOps.Add(1)
+	tx := &Tx{tx: &mockTx{}, db: db}
+
+	if err := tx.Rollback(context.Background()); err != nil {
+		t.Fatalf("Rollback returned unexpected error: %v", err)
+	}
+	// Commit after Rollback is a no-op and must not fire the hook either.
+	_ = tx.Commit(context.Background())
+
+	if called {
+		t.Error("AfterCommitSuccess should not fire on rollback")
+	}
+}
+
+func TestTxAfterCommitSuccessSkippedOnCommitError(t *testing.T) {
+	db := NewDB()
+	commitErr := errors.New("commit failed")
+
+	called := false
+	db.hooks.addHook(AfterCommitSuccess, func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error {
+		called = true
+		return nil
+	})
+
+	mock := &mockTx{
+		commitFunc: func(ctx context.Context) error {
+			return commitErr
+		},
+	}
+
+	db.activeOps.Add(1)
+	tx := &Tx{tx: mock, db: db}
+
+	if err := tx.Commit(context.Background()); !errors.Is(err, commitErr) {
+		t.Errorf("Commit should return underlying error: got %v", err)
+	}
+	if called {
+		t.Error("AfterCommitSuccess should not fire when the commit fails")
+	}
+}
+
+func TestTxAfterCommitSuccessHookErrorPropagation(t *testing.T) {
+	db := NewDB()
+	hookErr := errors.New("publish failed")
+
+	db.hooks.addHook(AfterCommitSuccess, func(ctx context.Context, sql string, args []interface{}, tag pgconn.CommandTag, operationErr error) error {
+		return hookErr
+	})
+
+	db.activeOps.Add(1)
+	tx := &Tx{tx: &mockTx{}, db: db}
+
+	if err := tx.Commit(context.Background()); !errors.Is(err, hookErr) {
+		t.Errorf("Commit should return wrapped hook error: got %v, want error wrapping %v", err, hookErr)
+	}
+}
+
+func TestTxInfo(t *testing.T) {
+	db := NewDB()
+	ctx, release := withConnAffinity(context.Background(), db, &fakeAffinityPool{})
+	defer release()
+
+	before := time.Now()
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+
+	info := tx.Info()
+	if info.StartedAt.Before(before) || info.StartedAt.After(time.Now()) {
+		t.Errorf("StartedAt %v not within BeginTx call", info.StartedAt)
+	}
+	if info.IsoLevel != pgx.Serializable || info.AccessMode != pgx.ReadOnly {
+		t.Errorf("expected serializable read only, got %q %q", info.IsoLevel, info.AccessMode)
+	}
+	if info.Finalized || info.Statements != 0 {
+		t.Errorf("fresh tx should be unfinalized with no statements, got %+v", info)
+	}
+
+	_, _ = tx.Exec(ctx, "SELECT 1")
+	_, _ = tx.Query(ctx, "SELECT 2")
+	_ = tx.QueryRow(ctx, "SELECT 3")
+	if got := tx.Info().Statements; got != 3 {
+		t.Errorf("expected 3 statements, got %d", got)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	_, _ = tx.Exec(ctx, "SELECT 4")
+
+	info = tx.Info()
+	if !info.Finalized {
+		t.Error("Info should report finalized after Commit")
+	}
+	if info.Statements != 3 {
+		t.Errorf("calls after finalization should not count, got %d", info.Statements)
+	}
+}
+
+func TestTxInfoDefaultOptions(t *testing.T) {
+	db := NewDB()
+	ctx, release := withConnAffinity(context.Background(), db, &fakeAffinityPool{})
+	defer release()
+
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if info := tx.Info(); info.IsoLevel != "" || info.AccessMode != "" {
+		t.Errorf("default options should leave the levels empty, got %+v", info)
+	}
+}
+
+func TestMaxOpenTransactions(t *testing.T) {
+	db := NewDB()
+	db.maxOpenTx = 2
+	ctx, release := withConnAffinity(context.Background(), db, &fakeAffinityPool{})
+	defer release()
+
+	first, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("first BeginTx failed: %v", err)
+	}
+	second, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("second BeginTx failed: %v", err)
+	}
+
+	beforeCalls := 0
+	db.hooks.addHook(BeforeTransaction, func(context.Context, string, []interface{}, pgconn.CommandTag, error) error {
+		beforeCalls++
+		return nil
+	})
+	if _, err := db.BeginTx(ctx, pgx.TxOptions{}); !errors.Is(err, ErrTooManyOpenTransactions) {
+		t.Fatalf("expected ErrTooManyOpenTransactions beyond the limit, got %v", err)
+	}
+	if beforeCalls != 0 {
+		t.Error("a rejected BeginTx should not fire BeforeTransaction hooks")
+	}
+
+	if err := first.Commit(ctx); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	_ = first.Rollback(ctx) // already finalized; must not free a second slot
+	third, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("committing should free a slot, got %v", err)
+	}
+	if _, err := db.BeginTx(ctx, pgx.TxOptions{}); !errors.Is(err, ErrTooManyOpenTransactions) {
+		t.Errorf("a double finalize must not free two slots, got %v", err)
+	}
+
+	_ = second.Rollback(ctx)
+	_ = third.Rollback(ctx)
+	if got := db.openTx.Load(); got != 0 {
+		t.Errorf("expected no open transactions after finalizing all, got %d", got)
+	}
+}
+
+func TestMaxOpenTransactionsReleasesSlotOnBeginFailure(t *testing.T) {
+	db := NewDB()
+	db.maxOpenTx = 1
+	hookErr := errors.New("blocked")
+	db.hooks.addHook(BeforeTransaction, func(context.Context, string, []interface{}, pgconn.CommandTag, error) error {
+		return hookErr
+	})
+	ctx, release := withConnAffinity(context.Background(), db, &fakeAffinityPool{})
+	defer release()
+
+	for i := 0; i < 2; i++ {
+		if _, err := db.BeginTx(ctx, pgx.TxOptions{}); !errors.Is(err, hookErr) {
+			t.Fatalf("attempt %d: expected hook error, got %v", i, err)
+		}
+	}
+	if got := db.openTx.Load(); got != 0 {
+		t.Errorf("failed BeginTx calls should not hold slots, got %d", got)
+	}
+}
+
+func TestWithMaxOpenTransactions(t *testing.T) {
+	cfg := newConnectConfig()
+	if cfg.maxOpenTx != 0 {
+		t.Errorf("expected no limit by default, got %d", cfg.maxOpenTx)
+	}
+	WithMaxOpenTransactions(5)(cfg)
+	if cfg.maxOpenTx != 5 {
+		t.Errorf("expected limit 5, got %d", cfg.maxOpenTx)
+	}
+
+	db := NewDB()
+	ctx, release := withConnAffinity(context.Background(), db, &fakeAffinityPool{})
+	defer release()
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if tx.holdsSlot || db.openTx.Load() != 0 {
+		t.Error("transactions should not be counted without a limit")
+	}
+	_ = tx.Rollback(ctx)
+}
@@ -0,0 +1,90 @@
+package pgxkit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// defaultRollbackLogLimit caps how many statements a transaction keeps for the
+// rollback log when WithRollbackLog is given a non-positive limit.
+const defaultRollbackLogLimit = 50
+
+// rollbackLog configures statement logging for rolled-back transactions.
+type rollbackLog struct {
+	logger *slog.Logger
+	limit  int
+}
+
+// WithRollbackLog makes every transaction remember the statements it ran and,
+// if it rolls back (explicitly or because Commit failed), log them to logger
+// at Warn level for post-mortem debugging. Committed transactions log nothing.
+//
+// Only SQL text is kept; argument values are redacted to a count, so secrets
+// and PII passed as parameters never reach the log. At most limit statements
+// are kept per transaction, the most recent ones, since those are usually the
+// interesting ones; a non-positive limit uses 50. A nil logger disables the
+// option.
+//
+// Example:
+//
+//	err := db.Connect(ctx, dsn, pgxkit.WithRollbackLog(slog.Default(), 20))
+func WithRollbackLog(logger *slog.Logger, limit int) ConnectOption {
+	return func(c *connectConfig) {
+		if logger == nil {
+			c.rollbackLog = nil
+			return
+		}
+		if limit <= 0 {
+			limit = defaultRollbackLogLimit
+		}
+		c.rollbackLog = &rollbackLog{logger: logger, limit: limit}
+	}
+}
+
+// txStatements is the bounded statement buffer of one transaction.
+type txStatements struct {
+	limit   int
+	entries []string
+	dropped int
+}
+
+func (s *txStatements) record(sql string, args []interface{}) {
+	entry := sql
+	if len(args) > 0 {
+		entry = fmt.Sprintf("%s [%d args redacted]", sql, len(args))
+	}
+	if len(s.entries) >= s.limit {
+		s.entries = append(s.entries[1:], entry)
+		s.dropped++
+		return
+	}
+	s.entries = append(s.entries, entry)
+}
+
+// recordStatement adds sql to the transaction's rollback log, if enabled.
+func (t *Tx) recordStatement(sql string, args []interface{}) {
+	if t.statements != nil {
+		t.statements.record(sql, args)
+	}
+}
+
+// logRollback emits the recorded statements, if any, and clears the buffer.
+func (t *Tx) logRollback(ctx context.Context, reason string, err error) {
+	if t.statements == nil || len(t.statements.entries) == 0 {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("reason", reason),
+		slog.Int("statement_count", len(t.statements.entries)+t.statements.dropped),
+		slog.Any("statements", t.statements.entries),
+	}
+	if t.statements.dropped > 0 {
+		attrs = append(attrs, slog.Int("dropped", t.statements.dropped))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	t.db.rollbackLog.logger.LogAttrs(ctx, slog.LevelWarn, "transaction rolled back", attrs...)
+	t.statements = nil
+}
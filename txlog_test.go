@@ -0,0 +1,151 @@
+package pgxkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func newRollbackLogDB(buf *bytes.Buffer, limit int) *DB {
+	cfg := newConnectConfig()
+	WithRollbackLog(slog.New(slog.NewJSONHandler(buf, nil)), limit)(cfg)
+	db := NewDB()
+	db.rollbackLog = cfg.rollbackLog
+	return db
+}
+
+// beginLoggedTx starts a Tx through BeginTx, using a fake affinity pool so no
+// database is needed.
+func beginLoggedTx(t *testing.T, db *DB) (*Tx, context.Context) {
+	t.Helper()
+	ctx, release := withConnAffinity(context.Background(), db, &fakeAffinityPool{})
+	t.Cleanup(release)
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	return tx, ctx
+}
+
+func TestWithRollbackLogOption(t *testing.T) {
+	cfg := newConnectConfig()
+	WithRollbackLog(slog.Default(), 0)(cfg)
+	if cfg.rollbackLog == nil || cfg.rollbackLog.limit != defaultRollbackLogLimit {
+		t.Errorf("expected default limit %d, got %+v", defaultRollbackLogLimit, cfg.rollbackLog)
+	}
+	WithRollbackLog(nil, 10)(cfg)
+	if cfg.rollbackLog != nil {
+		t.Error("nil logger should disable the rollback log")
+	}
+}
+
+func TestRollbackLogEmitsStatementsOnRollback(t *testing.T) {
+	var buf bytes.Buffer
+	db := newRollbackLogDB(&buf, 10)
+	tx, ctx := beginLoggedTx(t, db)
+
+	_, _ = tx.Exec(ctx, "UPDATE accounts SET balance = balance - $1 WHERE id = $2", 100, "secret-id")
+	_ = tx.QueryRow(ctx, "SELECT balance FROM accounts WHERE id = $1", "secret-id")
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+
+	var entry struct {
+		Msg            string   `json:"msg"`
+		Level          string   `json:"level"`
+		Reason         string   `json:"reason"`
+		StatementCount int      `json:"statement_count"`
+		Statements     []string `json:"statements"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected one JSON log line, got %q: %v", buf.String(), err)
+	}
+	if entry.Msg != "transaction rolled back" || entry.Level != "WARN" || entry.Reason != "rollback" {
+		t.Errorf("unexpected log entry: %+v", entry)
+	}
+	if entry.StatementCount != 2 || len(entry.Statements) != 2 {
+		t.Fatalf("expected 2 statements, got %+v", entry)
+	}
+	if entry.Statements[0] != "UPDATE accounts SET balance = balance - $1 WHERE id = $2 [2 args redacted]" {
+		t.Errorf("unexpected first statement: %q", entry.Statements[0])
+	}
+	if strings.Contains(buf.String(), "secret-id") {
+		t.Error("argument values must not be logged")
+	}
+}
+
+func TestRollbackLogDiscardedOnCommit(t *testing.T) {
+	var buf bytes.Buffer
+	db := newRollbackLogDB(&buf, 10)
+	tx, ctx := beginLoggedTx(t, db)
+
+	_, _ = tx.Exec(ctx, "INSERT INTO t VALUES (1)")
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	_ = tx.Rollback(ctx) // deferred rollback after commit is a no-op
+	if buf.Len() != 0 {
+		t.Errorf("committed transaction should log nothing, got %q", buf.String())
+	}
+}
+
+func TestRollbackLogOnCommitFailure(t *testing.T) {
+	var buf bytes.Buffer
+	db := newRollbackLogDB(&buf, 10)
+	commitErr := errors.New("deferred constraint violated")
+	db.activeOps.Add(1)
+	tx := &Tx{
+		tx:         &mockTx{commitFunc: func(ctx context.Context) error { return commitErr }},
+		db:         db,
+		statements: &txStatements{limit: 10},
+	}
+	ctx := context.Background()
+	_, _ = tx.Exec(ctx, "INSERT INTO t VALUES (1)")
+	_ = tx.Commit(ctx)
+
+	out := buf.String()
+	if !strings.Contains(out, `"reason":"commit failed"`) || !strings.Contains(out, "deferred constraint violated") {
+		t.Errorf("failed commit should log statements with the error, got %q", out)
+	}
+}
+
+func TestRollbackLogCapsBuffer(t *testing.T) {
+	var buf bytes.Buffer
+	db := newRollbackLogDB(&buf, 2)
+	tx, ctx := beginLoggedTx(t, db)
+
+	for _, sql := range []string{"SELECT 1", "SELECT 2", "SELECT 3"} {
+		_, _ = tx.Exec(ctx, sql)
+	}
+	_ = tx.Rollback(ctx)
+
+	var entry struct {
+		StatementCount int      `json:"statement_count"`
+		Dropped        int      `json:"dropped"`
+		Statements     []string `json:"statements"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("unmarshal log: %v", err)
+	}
+	if entry.StatementCount != 3 || entry.Dropped != 1 {
+		t.Errorf("expected 3 total / 1 dropped, got %+v", entry)
+	}
+	if strings.Join(entry.Statements, ",") != "SELECT 2,SELECT 3" {
+		t.Errorf("buffer should keep the most recent statements, got %v", entry.Statements)
+	}
+}
+
+func TestRollbackLogDisabledByDefault(t *testing.T) {
+	db := NewDB()
+	tx, ctx := beginLoggedTx(t, db)
+	if tx.statements != nil {
+		t.Error("statements should not be recorded without WithRollbackLog")
+	}
+	_ = tx.Rollback(ctx)
+}
@@ -0,0 +1,107 @@
+package pgxkit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TypeRegisterFunc registers one or more custom types on a freshly dialed
+// connection, typically via conn.LoadType and conn.TypeMap().RegisterType.
+type TypeRegisterFunc func(ctx context.Context, conn *pgx.Conn) error
+
+// TypeRegistry collects custom type registrations (citext, enums, composite
+// types, custom codecs) so they can be applied to every new connection with a
+// single WithTypeRegistry option instead of a chain of WithOnConnect hooks.
+// A registry may be shared by several DBs. It is safe for concurrent use.
+type TypeRegistry struct {
+	mu    sync.RWMutex
+	funcs []TypeRegisterFunc
+}
+
+// NewTypeRegistry creates an empty TypeRegistry.
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{}
+}
+
+// Register adds fn to the registry. Registrations run in the order they were
+// added, so a type that depends on another (an array of a composite, say)
+// should be registered after it. A nil fn is ignored.
+//
+// Registrations added after Connect apply only to connections dialed from
+// then on.
+func (r *TypeRegistry) Register(fn TypeRegisterFunc) *TypeRegistry {
+	if fn == nil {
+		return r
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.funcs = append(r.funcs, fn)
+	return r
+}
+
+// Len returns the number of registrations.
+func (r *TypeRegistry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.funcs)
+}
+
+// apply runs every registration against conn, stopping at the first error.
+func (r *TypeRegistry) apply(ctx context.Context, conn *pgx.Conn) error {
+	r.mu.RLock()
+	funcs := append([]TypeRegisterFunc(nil), r.funcs...)
+	r.mu.RUnlock()
+
+	for i, fn := range funcs {
+		if err := fn(ctx, conn); err != nil {
+			return fmt.Errorf("type registration %d failed: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// LoadTypes returns a TypeRegisterFunc that loads each named type (an enum,
+// composite, domain or extension type such as hstore) from the database and
+// registers it on the connection, in order. List element types before the
+// array types that use them: LoadTypes("address", "_address").
+func LoadTypes(names ...string) TypeRegisterFunc {
+	return func(ctx context.Context, conn *pgx.Conn) error {
+		for _, name := range names {
+			dt, err := conn.LoadType(ctx, name)
+			if err != nil {
+				return fmt.Errorf("failed to load type %s: %w", name, err)
+			}
+			conn.TypeMap().RegisterType(dt)
+		}
+		return nil
+	}
+}
+
+// WithTypeRegistry applies every registration in reg to each new connection
+// on both the read and write pools, from the pool's AfterConnect callback.
+// Registrations run before any WithOnConnect hooks, so those hooks can already
+// use the custom types. If a registration fails the connection is discarded
+// and the error is returned to whoever was acquiring it.
+//
+// Example:
+//
+//	types := pgxkit.NewTypeRegistry().
+//	    Register(func(ctx context.Context, conn *pgx.Conn) error {
+//	        t, err := conn.LoadType(ctx, "mood")
+//	        if err != nil {
+//	            return err
+//	        }
+//	        conn.TypeMap().RegisterType(t)
+//	        return nil
+//	    })
+//	err := db.Connect(ctx, dsn, pgxkit.WithTypeRegistry(types))
+//
+// A nil reg is ignored.
+func WithTypeRegistry(reg *TypeRegistry) ConnectOption {
+	return func(c *connectConfig) {
+		c.typeRegistry = reg
+	}
+}
@@ -0,0 +1,133 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestWithTypeRegistryRunsRegistrationsOnConnect(t *testing.T) {
+	var calls []string
+	reg := NewTypeRegistry().
+		Register(func(ctx context.Context, conn *pgx.Conn) error {
+			calls = append(calls, "citext")
+			return nil
+		}).
+		Register(func(ctx context.Context, conn *pgx.Conn) error {
+			calls = append(calls, "money_codec")
+			return nil
+		}).
+		Register(nil)
+	if reg.Len() != 2 {
+		t.Fatalf("expected 2 registrations, got %d", reg.Len())
+	}
+
+	cfg := newConnectConfig()
+	WithTypeRegistry(reg)(cfg)
+	WithOnConnect(func(conn *pgx.Conn) error {
+		calls = append(calls, "on_connect")
+		return nil
+	})(cfg)
+
+	poolConfig := &pgxpool.Config{ConnConfig: &pgx.ConnConfig{}}
+	cfg.applyConnConfig(poolConfig)
+	cfg.hooks.configurePool(poolConfig)
+
+	if err := poolConfig.AfterConnect(context.Background(), nil); err != nil {
+		t.Fatalf("AfterConnect failed: %v", err)
+	}
+	if got := strings.Join(calls, ","); got != "citext,money_codec,on_connect" {
+		t.Errorf("expected registrations in order before OnConnect hooks, got %s", got)
+	}
+}
+
+func TestWithTypeRegistryStopsAtFirstError(t *testing.T) {
+	loadErr := errors.New("type \"mood\" does not exist")
+	secondRan := false
+	reg := NewTypeRegistry().
+		Register(func(ctx context.Context, conn *pgx.Conn) error { return loadErr }).
+		Register(func(ctx context.Context, conn *pgx.Conn) error {
+			secondRan = true
+			return nil
+		})
+
+	originalRan := false
+	poolConfig := &pgxpool.Config{
+		ConnConfig: &pgx.ConnConfig{},
+		AfterConnect: func(ctx context.Context, conn *pgx.Conn) error {
+			originalRan = true
+			return nil
+		},
+	}
+	cfg := newConnectConfig()
+	WithTypeRegistry(reg)(cfg)
+	cfg.applyConnConfig(poolConfig)
+
+	err := poolConfig.AfterConnect(context.Background(), nil)
+	if !errors.Is(err, loadErr) {
+		t.Fatalf("expected registration error, got %v", err)
+	}
+	if !originalRan {
+		t.Error("an existing AfterConnect should still run first")
+	}
+	if secondRan {
+		t.Error("registrations after a failure should not run")
+	}
+}
+
+func TestWithTypeRegistryNil(t *testing.T) {
+	cfg := newConnectConfig()
+	WithTypeRegistry(nil)(cfg)
+	poolConfig := &pgxpool.Config{ConnConfig: &pgx.ConnConfig{}}
+	cfg.applyConnConfig(poolConfig)
+	if poolConfig.AfterConnect != nil {
+		t.Error("a nil registry should not install AfterConnect")
+	}
+}
+
+func TestTypeRegistryIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `DO $$ BEGIN
+		CREATE TYPE registry_test_mood AS ENUM ('sad', 'ok', 'happy');
+	EXCEPTION WHEN duplicate_object THEN NULL; END $$`)
+	if err != nil {
+		t.Fatalf("Failed to create test type: %v", err)
+	}
+	defer CleanupTestData("DROP TYPE IF EXISTS registry_test_mood")
+
+	loadType := func(name string) TypeRegisterFunc {
+		return func(ctx context.Context, conn *pgx.Conn) error {
+			dt, err := conn.LoadType(ctx, name)
+			if err != nil {
+				return err
+			}
+			conn.TypeMap().RegisterType(dt)
+			return nil
+		}
+	}
+	reg := NewTypeRegistry().
+		Register(loadType("registry_test_mood")).
+		Register(loadType("_registry_test_mood"))
+
+	db := NewDB()
+	if err := db.Connect(ctx, os.Getenv("TEST_DATABASE_URL"), WithTypeRegistry(reg)); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer db.Shutdown(ctx)
+
+	var moods []string
+	err = db.QueryRow(ctx, "SELECT $1::registry_test_mood[]", []string{"ok", "happy"}).Scan(&moods)
+	if err != nil {
+		t.Fatalf("query with registered array type failed: %v", err)
+	}
+	if strings.Join(moods, ",") != "ok,happy" {
+		t.Errorf("expected [ok happy], got %v", moods)
+	}
+}
@@ -1,7 +1,14 @@
 package pgxkit
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -49,6 +56,43 @@ func FromPgxTextToString(t pgtype.Text) string {
 	return t.String
 }
 
+// ErrUnknownEnumValue is returned by FromPgxEnum when the database holds a
+// value outside the allowed set, usually because a variant was added to the
+// Postgres enum type but not to the Go code.
+var ErrUnknownEnumValue = errors.New("unknown enum value")
+
+// ToPgxEnum converts a string-kind Go enum value to pgtype.Text for a
+// Postgres enum column.
+func ToPgxEnum[T ~string](v T) pgtype.Text {
+	return pgtype.Text{String: string(v), Valid: true}
+}
+
+// FromPgxEnum converts a scanned Postgres enum value to T, checking it
+// against allowed. A value not in allowed returns an error wrapping
+// ErrUnknownEnumValue. If the pgtype.Text is invalid (NULL), returns the zero
+// value of T and no error.
+//
+// Example:
+//
+//	type Status string
+//	const (
+//	    StatusActive   Status = "active"
+//	    StatusDisabled Status = "disabled"
+//	)
+//	status, err := pgxkit.FromPgxEnum(row.Status, StatusActive, StatusDisabled)
+func FromPgxEnum[T ~string](t pgtype.Text, allowed ...T) (T, error) {
+	var zero T
+	if !t.Valid {
+		return zero, nil
+	}
+	for _, v := range allowed {
+		if string(v) == t.String {
+			return v, nil
+		}
+	}
+	return zero, fmt.Errorf("%w %q for %T", ErrUnknownEnumValue, t.String, zero)
+}
+
 // =============================================================================
 // INTEGER CONVERSIONS
 // =============================================================================
@@ -89,8 +133,14 @@ func FromPgxInt4(i pgtype.Int4) *int32 {
 	return &i.Int32
 }
 
+// ErrIntOverflow is returned by checked conversions when a value does not fit
+// in the target PostgreSQL integer type.
+var ErrIntOverflow = errors.New("integer out of range")
+
 // ToPgxInt4FromInt converts an int pointer to pgtype.Int4.
 // If the input is nil, returns an invalid pgtype.Int4 (NULL in database).
+// Values outside the int32 range are truncated; use ToPgxInt4FromIntChecked
+// when the input is not known to fit.
 func ToPgxInt4FromInt(i *int) pgtype.Int4 {
 	if i == nil {
 		return pgtype.Int4{Valid: false}
@@ -98,8 +148,22 @@ func ToPgxInt4FromInt(i *int) pgtype.Int4 {
 	return pgtype.Int4{Int32: int32(*i), Valid: true}
 }
 
+// ToPgxInt4FromIntChecked converts an int pointer to pgtype.Int4, returning
+// an error wrapping ErrIntOverflow if the value is outside the int32 range.
+// If the input is nil, returns an invalid pgtype.Int4 (NULL in database).
+func ToPgxInt4FromIntChecked(i *int) (pgtype.Int4, error) {
+	if i == nil {
+		return pgtype.Int4{Valid: false}, nil
+	}
+	if *i < math.MinInt32 || *i > math.MaxInt32 {
+		return pgtype.Int4{}, fmt.Errorf("%w: %d does not fit in int4", ErrIntOverflow, *i)
+	}
+	return pgtype.Int4{Int32: int32(*i), Valid: true}, nil
+}
+
 // FromPgxInt4ToInt converts a pgtype.Int4 to an int pointer.
-// If the pgtype.Int4 is invalid (NULL), returns nil.
+// If the pgtype.Int4 is invalid (NULL), returns nil. Every int32 fits in an
+// int, so this direction cannot overflow.
 func FromPgxInt4ToInt(i pgtype.Int4) *int {
 	if !i.Valid {
 		return nil
@@ -319,6 +383,21 @@ func FromPgxTimestamptz(t pgtype.Timestamptz) time.Time {
 	return t.Time
 }
 
+// FromPgxTimestamptzIn converts a pgtype.Timestamptz to a time.Time in loc.
+// PostgreSQL stores timestamptz as a UTC instant and keeps no zone, and pgx
+// returns it in the local zone, so use this to present the value in a
+// user's zone. A nil loc means UTC. If the pgtype.Timestamptz is invalid
+// (NULL), returns zero time.
+func FromPgxTimestamptzIn(t pgtype.Timestamptz, loc *time.Location) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.Time.In(loc)
+}
+
 // FromPgxTimestamptzPtr converts a pgtype.Timestamptz to a time.Time pointer.
 // If the pgtype.Timestamptz is invalid (NULL), returns nil.
 func FromPgxTimestamptzPtr(t pgtype.Timestamptz) *time.Time {
@@ -361,6 +440,9 @@ func ToPgxTime(t *time.Time) pgtype.Time {
 // FromPgxTime converts a pgtype.Time to a time.Time pointer.
 // If the pgtype.Time is invalid (NULL), returns nil.
 // The returned time will be on the current date with the time component.
+// That date is made up: the result changes from one day to the next and
+// across local DST changes, so comparing or storing it is error-prone.
+// Prefer FromPgxTimeToDuration, which returns the time of day alone.
 func FromPgxTime(t pgtype.Time) *time.Time {
 	if !t.Valid {
 		return nil
@@ -372,6 +454,207 @@ func FromPgxTime(t pgtype.Time) *time.Time {
 	return &result
 }
 
+// FromPgxTimeToDuration converts a pgtype.Time to the time elapsed since
+// midnight, the natural Go representation of a time-of-day column.
+// If the pgtype.Time is invalid (NULL), returns nil.
+func FromPgxTimeToDuration(t pgtype.Time) *time.Duration {
+	if !t.Valid {
+		return nil
+	}
+	d := time.Duration(t.Microseconds) * time.Microsecond
+	return &d
+}
+
+// =============================================================================
+// INTERVAL CONVERSIONS
+// =============================================================================
+
+// FromPgxIntervalToISO formats a pgtype.Interval as an ISO 8601 duration,
+// e.g. "P1Y2M3DT4H5M6.5S". Months are split into years and months and
+// microseconds into hours, minutes and seconds; days are kept separate, as
+// PostgreSQL does. Like PostgreSQL's iso_8601 IntervalStyle, each component
+// carries its own sign ("P-1DT2H"). A zero interval is "PT0S".
+// If the pgtype.Interval is invalid (NULL), returns an empty string.
+func FromPgxIntervalToISO(i pgtype.Interval) string {
+	if !i.Valid {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('P')
+	if y := i.Months / 12; y != 0 {
+		fmt.Fprintf(&b, "%dY", y)
+	}
+	if m := i.Months % 12; m != 0 {
+		fmt.Fprintf(&b, "%dM", m)
+	}
+	if i.Days != 0 {
+		fmt.Fprintf(&b, "%dD", i.Days)
+	}
+	if i.Microseconds != 0 {
+		b.WriteByte('T')
+		us := i.Microseconds
+		if h := us / 3_600_000_000; h != 0 {
+			fmt.Fprintf(&b, "%dH", h)
+		}
+		if m := us / 60_000_000 % 60; m != 0 {
+			fmt.Fprintf(&b, "%dM", m)
+		}
+		if rem := us % 60_000_000; rem != 0 {
+			sign := ""
+			if rem < 0 {
+				sign, rem = "-", -rem
+			}
+			secs := strconv.FormatInt(rem/1_000_000, 10)
+			if frac := rem % 1_000_000; frac != 0 {
+				secs += strings.TrimRight(fmt.Sprintf(".%06d", frac), "0")
+			}
+			fmt.Fprintf(&b, "%s%sS", sign, secs)
+		}
+	}
+	if b.Len() == 1 {
+		return "PT0S"
+	}
+	return b.String()
+}
+
+// ToPgxIntervalFromISO parses an ISO 8601 duration such as "P1Y2M10DT2H30M"
+// into a pgtype.Interval. Years and months become Months, weeks and days
+// become Days, and hours, minutes and seconds become Microseconds.
+// Components may be signed ("P-1DT2H"), a leading "-" negates the whole
+// duration, and seconds may have up to six fractional digits. Fractions on
+// other units are rejected since they have no exact interval equivalent.
+func ToPgxIntervalFromISO(s string) (pgtype.Interval, error) {
+	invalid := func(reason string) (pgtype.Interval, error) {
+		return pgtype.Interval{}, fmt.Errorf("invalid ISO 8601 duration %q: %s", s, reason)
+	}
+
+	rest := s
+	negate := false
+	if strings.HasPrefix(rest, "-") {
+		negate, rest = true, rest[1:]
+	}
+	if !strings.HasPrefix(rest, "P") {
+		return invalid("must start with P")
+	}
+	rest = rest[1:]
+	if rest == "" || rest == "T" {
+		return invalid("no components")
+	}
+
+	var months, days, micros int64
+	ok := true
+	inTime := false
+	seen := ""
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime {
+				return invalid("repeated T")
+			}
+			inTime, seen, rest = true, "", rest[1:]
+			if rest == "" {
+				return invalid("no time components after T")
+			}
+			continue
+		}
+		end := strings.IndexFunc(rest, func(r rune) bool {
+			return !(r >= '0' && r <= '9' || r == '-' || r == '+' || r == '.' || r == ',')
+		})
+		if end <= 0 {
+			return invalid("expected a number")
+		}
+		number, unit := strings.ReplaceAll(rest[:end], ",", "."), rest[end]
+		rest = rest[end+1:]
+
+		order := "YMWD"
+		if inTime {
+			order = "HMS"
+		}
+		pos := strings.IndexByte(order, unit)
+		if pos < 0 {
+			return invalid(fmt.Sprintf("unexpected unit %q", unit))
+		}
+		if last := strings.IndexAny(order, seen); seen != "" && pos <= last {
+			return invalid(fmt.Sprintf("unit %q out of order", unit))
+		}
+		seen = string(unit)
+
+		if inTime && unit == 'S' {
+			us, err := parseISOSeconds(number)
+			if err != nil {
+				return invalid(err.Error())
+			}
+			if micros, ok = addMicros(micros, us); !ok {
+				return invalid("out of range")
+			}
+			continue
+		}
+		n, err := strconv.ParseInt(number, 10, 32)
+		if err != nil {
+			return invalid(fmt.Sprintf("bad %c value %q", unit, number))
+		}
+		switch {
+		case inTime && unit == 'H':
+			micros, ok = addMicros(micros, n*3_600_000_000)
+		case inTime && unit == 'M':
+			micros, ok = addMicros(micros, n*60_000_000)
+		case unit == 'Y':
+			months += n * 12
+		case unit == 'M':
+			months += n
+		case unit == 'W':
+			days += n * 7
+		case unit == 'D':
+			days += n
+		}
+		if !ok {
+			return invalid("out of range")
+		}
+	}
+
+	if months > math.MaxInt32 || months < math.MinInt32 || days > math.MaxInt32 || days < math.MinInt32 || micros == math.MinInt64 {
+		return invalid("out of range")
+	}
+	if negate {
+		months, days, micros = -months, -days, -micros
+	}
+	return pgtype.Interval{Months: int32(months), Days: int32(days), Microseconds: micros, Valid: true}, nil
+}
+
+// parseISOSeconds converts a seconds value with an optional fraction of up to
+// six digits to microseconds.
+func parseISOSeconds(number string) (int64, error) {
+	whole, frac, hasFrac := strings.Cut(number, ".")
+	neg := strings.HasPrefix(whole, "-")
+	secs, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || secs > math.MaxInt64/1_000_000 || secs < math.MinInt64/1_000_000 {
+		return 0, fmt.Errorf("bad S value %q", number)
+	}
+	us := secs * 1_000_000
+	if hasFrac {
+		if frac == "" || len(frac) > 6 || strings.Trim(frac, "0123456789") != "" {
+			return 0, fmt.Errorf("bad fractional seconds %q", number)
+		}
+		f, _ := strconv.ParseInt(frac+strings.Repeat("0", 6-len(frac)), 10, 64)
+		if neg {
+			f = -f
+		}
+		var ok bool
+		if us, ok = addMicros(us, f); !ok {
+			return 0, fmt.Errorf("bad S value %q", number)
+		}
+	}
+	return us, nil
+}
+
+// addMicros returns a+b, or false if the sum overflows int64.
+func addMicros(a, b int64) (int64, bool) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, false
+	}
+	return sum, true
+}
+
 // =============================================================================
 // JSON CONVERSIONS
 // =============================================================================
@@ -398,8 +681,13 @@ func ToPgxTextArray(s []string) pgtype.Array[pgtype.Text] {
 	return pgtype.Array[pgtype.Text]{Elements: elements, Valid: true}
 }
 
+// ErrNullArrayElement is returned by the strict array conversions when an
+// array holds a NULL element.
+var ErrNullArrayElement = errors.New("array contains a NULL element")
+
 // FromPgxTextArray converts a pgtype.Array[pgtype.Text] to a string slice.
-// If the array is invalid (NULL), returns nil.
+// If the array is invalid (NULL), returns nil. NULL elements become empty
+// strings; use FromPgxTextArrayStrict to reject them instead.
 func FromPgxTextArray(a pgtype.Array[pgtype.Text]) []string {
 	if !a.Valid {
 		return nil
@@ -431,8 +719,21 @@ func ToPgxInt8Array(s []int64) pgtype.Array[pgtype.Int8] {
 	return pgtype.Array[pgtype.Int8]{Elements: elements, Valid: true}
 }
 
+// FromPgxTextArrayStrict is FromPgxTextArray for arrays that should never
+// contain NULL: a NULL element returns an error wrapping ErrNullArrayElement
+// instead of silently becoming "". A NULL array still returns nil.
+func FromPgxTextArrayStrict(a pgtype.Array[pgtype.Text]) ([]string, error) {
+	for i, elem := range a.Elements {
+		if !elem.Valid {
+			return nil, fmt.Errorf("%w at index %d", ErrNullArrayElement, i)
+		}
+	}
+	return FromPgxTextArray(a), nil
+}
+
 // FromPgxInt8Array converts a pgtype.Array[pgtype.Int8] to an int64 slice.
-// If the array is invalid (NULL), returns nil.
+// If the array is invalid (NULL), returns nil. NULL elements become 0; use
+// FromPgxInt8ArrayStrict to reject them instead.
 func FromPgxInt8Array(a pgtype.Array[pgtype.Int8]) []int64 {
 	if !a.Valid {
 		return nil
@@ -449,6 +750,462 @@ func FromPgxInt8Array(a pgtype.Array[pgtype.Int8]) []int64 {
 	return result
 }
 
+// FromPgxInt8ArrayStrict is FromPgxInt8Array for arrays that should never
+// contain NULL: a NULL element returns an error wrapping ErrNullArrayElement
+// instead of silently becoming 0. A NULL array still returns nil.
+func FromPgxInt8ArrayStrict(a pgtype.Array[pgtype.Int8]) ([]int64, error) {
+	for i, elem := range a.Elements {
+		if !elem.Valid {
+			return nil, fmt.Errorf("%w at index %d", ErrNullArrayElement, i)
+		}
+	}
+	return FromPgxInt8Array(a), nil
+}
+
+// ToPgxTimestamptzArray converts a time.Time slice to
+// pgtype.Array[pgtype.Timestamptz]. Zero times become NULL elements, so a
+// slice read with FromPgxTimestamptzArray round-trips. If the input is nil,
+// returns an invalid array (NULL in database).
+func ToPgxTimestamptzArray(s []time.Time) pgtype.Array[pgtype.Timestamptz] {
+	if s == nil {
+		return pgtype.Array[pgtype.Timestamptz]{Valid: false}
+	}
+
+	elements := make([]pgtype.Timestamptz, len(s))
+	for i, t := range s {
+		elements[i] = pgtype.Timestamptz{Time: t, Valid: !t.IsZero()}
+	}
+
+	return pgtype.Array[pgtype.Timestamptz]{Elements: elements, Valid: true}
+}
+
+// FromPgxTimestamptzArray converts a pgtype.Array[pgtype.Timestamptz] to a
+// time.Time slice. If the array is invalid (NULL), returns nil. NULL elements
+// become the zero time.
+func FromPgxTimestamptzArray(a pgtype.Array[pgtype.Timestamptz]) []time.Time {
+	if !a.Valid {
+		return nil
+	}
+
+	result := make([]time.Time, len(a.Elements))
+	for i, elem := range a.Elements {
+		if elem.Valid {
+			result[i] = elem.Time
+		}
+		// Invalid elements become the zero time
+	}
+
+	return result
+}
+
+// =============================================================================
+// BIT / VARBIT CONVERSIONS
+// =============================================================================
+
+// ToPgxBits converts a bool slice to pgtype.Bits for bit/varbit columns.
+// Element 0 is the leftmost (most significant) bit, matching PostgreSQL's
+// B'...' literal order. If the input is nil, returns an invalid pgtype.Bits
+// (NULL in database); an empty slice is a valid zero-length bit string.
+func ToPgxBits(b []bool) pgtype.Bits {
+	if b == nil {
+		return pgtype.Bits{Valid: false}
+	}
+	bytes := make([]byte, (len(b)+7)/8)
+	for i, set := range b {
+		if set {
+			bytes[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return pgtype.Bits{Bytes: bytes, Len: int32(len(b)), Valid: true}
+}
+
+// FromPgxBits converts a pgtype.Bits to a bool slice of length Len.
+// If the pgtype.Bits is invalid (NULL), returns nil.
+func FromPgxBits(b pgtype.Bits) []bool {
+	if !b.Valid {
+		return nil
+	}
+	result := make([]bool, b.Len)
+	for i := range result {
+		if i/8 < len(b.Bytes) {
+			result[i] = b.Bytes[i/8]&(0x80>>(i%8)) != 0
+		}
+	}
+	return result
+}
+
+// =============================================================================
+// RANGE CONVERSIONS
+// =============================================================================
+
+// RangeBounds is the Go-side view of a PostgreSQL range value.
+// A nil Lower or Upper means that end is unbounded. Inclusive reports whether
+// the lower and upper bounds are included ("[" and "]") and is always false
+// for an unbounded end. Empty ranges have no bounds at all.
+//
+// PostgreSQL canonicalizes discrete ranges (int4range, int8range, daterange)
+// to the [lower, upper) form, so a range written as [1,3] reads back as [1,4).
+type RangeBounds[T any] struct {
+	Lower     *T
+	Upper     *T
+	Inclusive [2]bool
+	Empty     bool
+}
+
+// EmptyPgxRange returns the empty range ('empty' in SQL) for any element
+// type. An empty range is a valid, non-NULL value that contains nothing.
+func EmptyPgxRange[T any]() pgtype.Range[T] {
+	return pgtype.Range[T]{LowerType: pgtype.Empty, UpperType: pgtype.Empty, Valid: true}
+}
+
+// ToPgxInt4Range builds an int4range. A nil lower or upper leaves that end
+// unbounded; inclusive gives the lower and upper bound types.
+func ToPgxInt4Range(lower, upper *int32, inclusive [2]bool) pgtype.Range[pgtype.Int4] {
+	return toPgxRange(ToPgxInt4(lower), ToPgxInt4(upper), lower != nil, upper != nil, inclusive)
+}
+
+// FromPgxInt4Range converts an int4range to RangeBounds.
+// If the range is invalid (NULL), returns nil.
+func FromPgxInt4Range(r pgtype.Range[pgtype.Int4]) *RangeBounds[int32] {
+	return fromPgxRange(r, FromPgxInt4)
+}
+
+// ToPgxInt8Range builds an int8range. A nil lower or upper leaves that end
+// unbounded; inclusive gives the lower and upper bound types.
+func ToPgxInt8Range(lower, upper *int64, inclusive [2]bool) pgtype.Range[pgtype.Int8] {
+	return toPgxRange(ToPgxInt8(lower), ToPgxInt8(upper), lower != nil, upper != nil, inclusive)
+}
+
+// FromPgxInt8Range converts an int8range to RangeBounds.
+// If the range is invalid (NULL), returns nil.
+func FromPgxInt8Range(r pgtype.Range[pgtype.Int8]) *RangeBounds[int64] {
+	return fromPgxRange(r, FromPgxInt8)
+}
+
+// ToPgxTimestamptzRange builds a tstzrange. A nil lower or upper leaves that
+// end unbounded; inclusive gives the lower and upper bound types.
+func ToPgxTimestamptzRange(lower, upper *time.Time, inclusive [2]bool) pgtype.Range[pgtype.Timestamptz] {
+	return toPgxRange(ToPgxTimestamptz(lower), ToPgxTimestamptz(upper), lower != nil, upper != nil, inclusive)
+}
+
+// FromPgxTimestamptzRange converts a tstzrange to RangeBounds.
+// If the range is invalid (NULL), returns nil.
+func FromPgxTimestamptzRange(r pgtype.Range[pgtype.Timestamptz]) *RangeBounds[time.Time] {
+	return fromPgxRange(r, FromPgxTimestamptzPtr)
+}
+
+// ToPgxDateRange builds a daterange from the dates of lower and upper. A nil
+// lower or upper leaves that end unbounded; inclusive gives the lower and
+// upper bound types.
+func ToPgxDateRange(lower, upper *time.Time, inclusive [2]bool) pgtype.Range[pgtype.Date] {
+	return toPgxRange(ToPgxDate(lower), ToPgxDate(upper), lower != nil, upper != nil, inclusive)
+}
+
+// FromPgxDateRange converts a daterange to RangeBounds.
+// If the range is invalid (NULL), returns nil.
+func FromPgxDateRange(r pgtype.Range[pgtype.Date]) *RangeBounds[time.Time] {
+	return fromPgxRange(r, FromPgxDate)
+}
+
+func toPgxRange[T any](lower, upper T, hasLower, hasUpper bool, inclusive [2]bool) pgtype.Range[T] {
+	return pgtype.Range[T]{
+		Lower:     lower,
+		Upper:     upper,
+		LowerType: boundType(hasLower, inclusive[0]),
+		UpperType: boundType(hasUpper, inclusive[1]),
+		Valid:     true,
+	}
+}
+
+func boundType(bounded, inclusive bool) pgtype.BoundType {
+	switch {
+	case !bounded:
+		return pgtype.Unbounded
+	case inclusive:
+		return pgtype.Inclusive
+	default:
+		return pgtype.Exclusive
+	}
+}
+
+func fromPgxRange[P, T any](r pgtype.Range[P], convert func(P) *T) *RangeBounds[T] {
+	if !r.Valid {
+		return nil
+	}
+	if r.LowerType == pgtype.Empty || r.UpperType == pgtype.Empty {
+		return &RangeBounds[T]{Empty: true}
+	}
+	var b RangeBounds[T]
+	if r.LowerType != pgtype.Unbounded {
+		b.Lower = convert(r.Lower)
+		b.Inclusive[0] = r.LowerType == pgtype.Inclusive
+	}
+	if r.UpperType != pgtype.Unbounded {
+		b.Upper = convert(r.Upper)
+		b.Inclusive[1] = r.UpperType == pgtype.Inclusive
+	}
+	return &b
+}
+
+// =============================================================================
+// FULL-TEXT SEARCH CONVERSIONS
+// =============================================================================
+
+// FromPgxTSVector extracts the lexemes from a tsvector read as text
+// (e.g. SELECT to_tsvector(...)::text). Quoting is undone and positions and
+// weights are dropped, so "'cat':2A 'fat':1" yields ["cat", "fat"].
+// If the pgtype.Text is invalid (NULL), returns nil.
+func FromPgxTSVector(v pgtype.Text) []string {
+	if !v.Valid {
+		return nil
+	}
+	result := []string{}
+	s := v.String
+	for i := 0; i < len(s); {
+		if s[i] == ' ' {
+			i++
+			continue
+		}
+		var lexeme strings.Builder
+		if s[i] == '\'' {
+			i++
+			for i < len(s) {
+				c := s[i]
+				if c == '\\' && i+1 < len(s) {
+					lexeme.WriteByte(s[i+1])
+					i += 2
+					continue
+				}
+				if c == '\'' {
+					if i+1 < len(s) && s[i+1] == '\'' {
+						lexeme.WriteByte('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				lexeme.WriteByte(c)
+				i++
+			}
+		} else {
+			for i < len(s) && s[i] != ' ' && s[i] != ':' {
+				lexeme.WriteByte(s[i])
+				i++
+			}
+		}
+		// Skip the position list (":1A,2") up to the next lexeme.
+		for i < len(s) && s[i] != ' ' {
+			i++
+		}
+		result = append(result, lexeme.String())
+	}
+	return result
+}
+
+// ToPgxTSQuery builds an argument for to_tsquery from user-supplied terms,
+// quoting each one so operators and punctuation in the input can't break the
+// query syntax. Terms are joined with op, which must be "&", "|" or "<->";
+// any other value is treated as "&". Empty terms are skipped, and no terms
+// yields "".
+//
+//	rows, err := db.Query(ctx,
+//	    "SELECT id FROM docs WHERE body_tsv @@ to_tsquery('english', $1)",
+//	    pgxkit.ToPgxTSQuery(strings.Fields(search), "&"))
+func ToPgxTSQuery(terms []string, op string) string {
+	switch op {
+	case "&", "|", "<->":
+	default:
+		op = "&"
+	}
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if term == "" {
+			continue
+		}
+		term = strings.ReplaceAll(term, `\`, `\\`)
+		term = strings.ReplaceAll(term, "'", "''")
+		quoted = append(quoted, "'"+term+"'")
+	}
+	return strings.Join(quoted, " "+op+" ")
+}
+
+// =============================================================================
+// HSTORE CONVERSIONS
+// =============================================================================
+
+// ToPgxHstore converts a Go map to pgtype.Hstore. Values are pointers because
+// hstore values may be NULL. A nil map becomes a NULL hstore and an empty map
+// an empty, non-NULL one. The map is copied, so later changes to m are not
+// reflected in the result.
+func ToPgxHstore(m map[string]*string) pgtype.Hstore {
+	if m == nil {
+		return nil
+	}
+	h := make(pgtype.Hstore, len(m))
+	for k, v := range m {
+		h[k] = v
+	}
+	return h
+}
+
+// FromPgxHstore converts pgtype.Hstore to a Go map. NULL values are nil
+// pointers. If the hstore is NULL, returns nil; an empty hstore returns an
+// empty map.
+func FromPgxHstore(h pgtype.Hstore) map[string]*string {
+	if h == nil {
+		return nil
+	}
+	m := make(map[string]*string, len(h))
+	for k, v := range h {
+		m[k] = v
+	}
+	return m
+}
+
+// =============================================================================
+// GENERIC NULLABLE
+// =============================================================================
+
+// Nullable holds a value of any Go type that may be NULL in the database. It
+// covers types without a dedicated pgtype wrapper and avoids a new pair of
+// To/From helpers for each one:
+//
+//	var nickname pgxkit.Nullable[string]
+//	err := db.QueryRow(ctx, "SELECT nickname FROM users WHERE id = $1", id).Scan(&nickname)
+//	_, err = db.Exec(ctx, "UPDATE users SET nickname = $1", pgxkit.ToNullable(newNickname))
+//
+// Nullable implements sql.Scanner and driver.Valuer, which pgx uses for both
+// scanning and query arguments. As in database/sql.Null, the value field is
+// named V because Value is the driver.Valuer method.
+type Nullable[T any] struct {
+	V     T
+	Valid bool
+}
+
+// ToNullable converts a pointer to Nullable. If the input is nil, returns an
+// invalid Nullable (NULL in database). A non-nil pointer to a zero value is
+// valid.
+func ToNullable[T any](v *T) Nullable[T] {
+	if v == nil {
+		return Nullable[T]{}
+	}
+	return Nullable[T]{V: *v, Valid: true}
+}
+
+// FromNullable converts a Nullable to a pointer.
+// If the Nullable is invalid (NULL), returns nil.
+func FromNullable[T any](n Nullable[T]) *T {
+	if !n.Valid {
+		return nil
+	}
+	return &n.V
+}
+
+// Scan implements sql.Scanner. NULL resets n to its zero value. If *T is
+// itself a sql.Scanner (uuid.UUID, for example) it is used; otherwise src
+// must be a T, or a string, []byte or number that converts to T without loss.
+func (n *Nullable[T]) Scan(src any) error {
+	if src == nil {
+		*n = Nullable[T]{}
+		return nil
+	}
+	var value T
+	if scanner, ok := any(&value).(sql.Scanner); ok {
+		if err := scanner.Scan(src); err != nil {
+			return err
+		}
+	} else if v, ok := src.(T); ok {
+		value = v
+	} else if err := convertScanned(src, reflect.ValueOf(&value).Elem()); err != nil {
+		return err
+	}
+	n.V, n.Valid = value, true
+	return nil
+}
+
+// Value implements driver.Valuer. An invalid Nullable is NULL. If T is itself
+// a driver.Valuer it is used; otherwise the value is passed to pgx as is.
+func (n Nullable[T]) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	if valuer, ok := any(n.V).(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	return n.V, nil
+}
+
+// convertScanned stores src into dst when the conversion is lossless: text to
+// a string kind, or a number to a numeric kind that can hold it.
+func convertScanned(src any, dst reflect.Value) error {
+	sv := reflect.ValueOf(src)
+	fail := func() error {
+		return fmt.Errorf("cannot scan %T into Nullable[%s]", src, dst.Type())
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		switch v := src.(type) {
+		case string:
+			dst.SetString(v)
+		case []byte:
+			dst.SetString(string(v))
+		default:
+			return fail()
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch sv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i = sv.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if sv.Uint() > math.MaxInt64 {
+				return fmt.Errorf("%w: %v does not fit in %s", ErrIntOverflow, src, dst.Type())
+			}
+			i = int64(sv.Uint())
+		default:
+			return fail()
+		}
+		if dst.OverflowInt(i) {
+			return fmt.Errorf("%w: %v does not fit in %s", ErrIntOverflow, src, dst.Type())
+		}
+		dst.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		switch sv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if sv.Int() < 0 {
+				return fmt.Errorf("%w: %v does not fit in %s", ErrIntOverflow, src, dst.Type())
+			}
+			u = uint64(sv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			u = sv.Uint()
+		default:
+			return fail()
+		}
+		if dst.OverflowUint(u) {
+			return fmt.Errorf("%w: %v does not fit in %s", ErrIntOverflow, src, dst.Type())
+		}
+		dst.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		switch sv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			dst.SetFloat(sv.Float())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			dst.SetFloat(float64(sv.Int()))
+		default:
+			return fail()
+		}
+	default:
+		if !sv.Type().ConvertibleTo(dst.Type()) || sv.Kind() != dst.Kind() {
+			return fail()
+		}
+		dst.Set(sv.Convert(dst.Type()))
+	}
+	return nil
+}
+
 // =============================================================================
 // BYTES CONVERSIONS
 // =============================================================================
@@ -1,10 +1,18 @@
 package pgxkit
 
 import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -66,6 +74,46 @@ func TestFromPgxTextToString(t *testing.T) {
 	}
 }
 
+type testStatus string
+
+const (
+	testStatusActive   testStatus = "active"
+	testStatusDisabled testStatus = "disabled"
+)
+
+func TestToPgxEnum(t *testing.T) {
+	result := ToPgxEnum(testStatusActive)
+	if !result.Valid || result.String != "active" {
+		t.Errorf("Expected valid 'active', got %+v", result)
+	}
+}
+
+func TestFromPgxEnum(t *testing.T) {
+	// Test with an allowed value
+	status, err := FromPgxEnum(pgtype.Text{String: "disabled", Valid: true}, testStatusActive, testStatusDisabled)
+	if err != nil || status != testStatusDisabled {
+		t.Errorf("Expected disabled, got %q, %v", status, err)
+	}
+
+	// Test with a value missing from the allowed set
+	status, err = FromPgxEnum(pgtype.Text{String: "suspended", Valid: true}, testStatusActive, testStatusDisabled)
+	if !errors.Is(err, ErrUnknownEnumValue) {
+		t.Errorf("Expected ErrUnknownEnumValue, got %v", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), `"suspended"`) {
+		t.Errorf("Expected error to name the value, got %v", err)
+	}
+	if status != "" {
+		t.Errorf("Expected zero value on error, got %q", status)
+	}
+
+	// Test with invalid pgtype.Text
+	status, err = FromPgxEnum(pgtype.Text{Valid: false}, testStatusActive, testStatusDisabled)
+	if err != nil || status != "" {
+		t.Errorf("Expected zero value and no error for NULL, got %q, %v", status, err)
+	}
+}
+
 // =============================================================================
 // INTEGER TESTS
 // =============================================================================
@@ -147,6 +195,39 @@ func TestToPgxInt4FromInt(t *testing.T) {
 	}
 }
 
+func TestToPgxInt4FromIntChecked(t *testing.T) {
+	for _, val := range []int{42, math.MaxInt32, math.MinInt32} {
+		result, err := ToPgxInt4FromIntChecked(&val)
+		if err != nil {
+			t.Errorf("unexpected error for %d: %v", val, err)
+		}
+		if !result.Valid || int(result.Int32) != val {
+			t.Errorf("Expected valid int32 %d, got valid=%v, int32=%v", val, result.Valid, result.Int32)
+		}
+	}
+
+	result, err := ToPgxInt4FromIntChecked(nil)
+	if err != nil || result.Valid {
+		t.Errorf("Expected invalid int32 and no error for nil, got valid=%v, err=%v", result.Valid, err)
+	}
+}
+
+func TestToPgxInt4FromIntCheckedOverflow(t *testing.T) {
+	if strconv.IntSize == 32 {
+		t.Skip("int cannot exceed int32 range on 32-bit platforms")
+	}
+	limit := int64(math.MaxInt32)
+	for _, val := range []int{int(limit + 1), int(-limit - 2), int(limit * 4)} {
+		result, err := ToPgxInt4FromIntChecked(&val)
+		if !errors.Is(err, ErrIntOverflow) {
+			t.Errorf("Expected ErrIntOverflow for %d, got %v", val, err)
+		}
+		if result.Valid {
+			t.Errorf("Expected invalid result on overflow for %d", val)
+		}
+	}
+}
+
 func TestFromPgxInt4ToInt(t *testing.T) {
 	// Test with valid pgtype.Int4
 	pgInt := pgtype.Int4{Int32: 42, Valid: true}
@@ -509,6 +590,63 @@ func TestFromPgxTimestamptz(t *testing.T) {
 	}
 }
 
+func TestFromPgxTimeToDuration(t *testing.T) {
+	tests := []struct {
+		name         string
+		microseconds int64
+		want         time.Duration
+	}{
+		{"midnight", 0, 0},
+		{"noon", 12 * 3600 * 1_000_000, 12 * time.Hour},
+		{"end of day", 86_399_999_999, 24*time.Hour - time.Microsecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FromPgxTimeToDuration(pgtype.Time{Microseconds: tt.microseconds, Valid: true})
+			if result == nil || *result != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, result)
+			}
+		})
+	}
+
+	if result := FromPgxTimeToDuration(pgtype.Time{Valid: false}); result != nil {
+		t.Errorf("Expected nil for invalid time, got %v", *result)
+	}
+}
+
+func TestFromPgxTimestamptzIn(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+	// 2024-07-04 16:30 UTC is 12:30 EDT.
+	stored := pgtype.Timestamptz{Time: time.Date(2024, 7, 4, 16, 30, 0, 0, time.UTC), Valid: true}
+
+	result := FromPgxTimestamptzIn(stored, newYork)
+	if result.Location() != newYork {
+		t.Errorf("Expected location %v, got %v", newYork, result.Location())
+	}
+	if result.Year() != 2024 || result.Month() != time.July || result.Day() != 4 || result.Hour() != 12 || result.Minute() != 30 {
+		t.Errorf("Expected 2024-07-04 12:30 in New York, got %v", result)
+	}
+	if !result.Equal(stored.Time) {
+		t.Errorf("Expected the same instant, got %v", result)
+	}
+
+	// Early morning UTC is still the previous evening in New York (EST in January).
+	result = FromPgxTimestamptzIn(pgtype.Timestamptz{Time: time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC), Valid: true}, newYork)
+	if result.Day() != 1 || result.Hour() != 22 {
+		t.Errorf("Expected 2024-01-01 22:00 in New York, got %v", result)
+	}
+
+	if result := FromPgxTimestamptzIn(stored, nil); result.Location() != time.UTC {
+		t.Errorf("Expected UTC for nil location, got %v", result.Location())
+	}
+	if result := FromPgxTimestamptzIn(pgtype.Timestamptz{Valid: false}, newYork); !result.IsZero() {
+		t.Errorf("Expected zero time for invalid timestamptz, got %v", result)
+	}
+}
+
 func TestFromPgxTimestamptzPtr(t *testing.T) {
 	// Test with valid pgtype.Timestamptz
 	now := time.Now()
@@ -558,6 +696,101 @@ func TestFromPgxDate(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// INTERVAL TESTS
+// =============================================================================
+
+func TestFromPgxIntervalToISO(t *testing.T) {
+	tests := []struct {
+		in   pgtype.Interval
+		want string
+	}{
+		{pgtype.Interval{Valid: true}, "PT0S"},
+		{pgtype.Interval{Months: 14, Days: 3, Microseconds: 4*3_600_000_000 + 5*60_000_000 + 6_500_000, Valid: true}, "P1Y2M3DT4H5M6.5S"},
+		{pgtype.Interval{Months: 12, Valid: true}, "P1Y"},
+		{pgtype.Interval{Days: 10, Valid: true}, "P10D"},
+		{pgtype.Interval{Microseconds: 90 * 60_000_000, Valid: true}, "PT1H30M"},
+		{pgtype.Interval{Microseconds: 1, Valid: true}, "PT0.000001S"},
+		{pgtype.Interval{Days: -1, Microseconds: 2 * 3_600_000_000, Valid: true}, "P-1DT2H"},
+		{pgtype.Interval{Months: -14, Microseconds: -(90*60_000_000 + 1_250_000), Valid: true}, "P-1Y-2MT-1H-30M-1.25S"},
+		{pgtype.Interval{Valid: false}, ""},
+	}
+	for _, tt := range tests {
+		if got := FromPgxIntervalToISO(tt.in); got != tt.want {
+			t.Errorf("FromPgxIntervalToISO(%+v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestToPgxIntervalFromISO(t *testing.T) {
+	tests := []struct {
+		in   string
+		want pgtype.Interval
+	}{
+		{"P1Y2M3DT4H5M6.5S", pgtype.Interval{Months: 14, Days: 3, Microseconds: 4*3_600_000_000 + 5*60_000_000 + 6_500_000, Valid: true}},
+		{"PT0S", pgtype.Interval{Valid: true}},
+		{"P2W", pgtype.Interval{Days: 14, Valid: true}},
+		{"PT36H", pgtype.Interval{Microseconds: 36 * 3_600_000_000, Valid: true}},
+		{"PT0,5S", pgtype.Interval{Microseconds: 500_000, Valid: true}},
+		{"P-1DT2H", pgtype.Interval{Days: -1, Microseconds: 2 * 3_600_000_000, Valid: true}},
+		{"-P1MT1.5S", pgtype.Interval{Months: -1, Microseconds: -1_500_000, Valid: true}},
+		{"PT-1.25S", pgtype.Interval{Microseconds: -1_250_000, Valid: true}},
+	}
+	for _, tt := range tests {
+		got, err := ToPgxIntervalFromISO(tt.in)
+		if err != nil {
+			t.Errorf("ToPgxIntervalFromISO(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ToPgxIntervalFromISO(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestToPgxIntervalFromISOErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"1D",
+		"P",
+		"PT",
+		"P1DT",
+		"P1H",
+		"PT1D",
+		"P1D2Y",
+		"P1DT1H1H",
+		"P1.5D",
+		"PT1.1234567S",
+		"PT1.S",
+		"P1X",
+		"PxD",
+		"P99999999999Y",
+		"P1DTT1H",
+		"PT2147483647H9223372036854S",
+		"PT-2147483647H-9223372036854S",
+		"PT9223372036854.999999S",
+		"-PT-9223372036854.775808S",
+	} {
+		if _, err := ToPgxIntervalFromISO(in); err == nil {
+			t.Errorf("ToPgxIntervalFromISO(%q) should fail", in)
+		}
+	}
+}
+
+func TestIntervalISORoundTrip(t *testing.T) {
+	for _, in := range []pgtype.Interval{
+		{Months: 14, Days: 3, Microseconds: 4*3_600_000_000 + 5*60_000_000 + 6_500_000, Valid: true},
+		{Days: -1, Microseconds: 2 * 3_600_000_000, Valid: true},
+		{Months: -14, Microseconds: -(90*60_000_000 + 1_250_000), Valid: true},
+		{Microseconds: 123_456_789, Valid: true},
+	} {
+		got, err := ToPgxIntervalFromISO(FromPgxIntervalToISO(in))
+		if err != nil || got != in {
+			t.Errorf("round trip of %+v gave %+v, %v", in, got, err)
+		}
+	}
+}
+
 // =============================================================================
 // JSON TESTS
 // =============================================================================
@@ -646,6 +879,580 @@ func TestFromPgxInt8Array(t *testing.T) {
 	}
 }
 
+func TestFromPgxTextArrayStrict(t *testing.T) {
+	withNull := pgtype.Array[pgtype.Text]{Elements: []pgtype.Text{
+		{String: "a", Valid: true},
+		{Valid: false},
+	}, Valid: true}
+
+	if lenient := FromPgxTextArray(withNull); len(lenient) != 2 || lenient[1] != "" {
+		t.Errorf("lenient conversion should turn NULL into \"\", got %q", lenient)
+	}
+	result, err := FromPgxTextArrayStrict(withNull)
+	if !errors.Is(err, ErrNullArrayElement) || !strings.Contains(err.Error(), "index 1") || result != nil {
+		t.Errorf("expected ErrNullArrayElement at index 1, got %q, %v", result, err)
+	}
+
+	result, err = FromPgxTextArrayStrict(ToPgxTextArray([]string{"a", "b"}))
+	if err != nil || len(result) != 2 || result[1] != "b" {
+		t.Errorf("expected [a b], got %q, %v", result, err)
+	}
+	result, err = FromPgxTextArrayStrict(pgtype.Array[pgtype.Text]{Valid: false})
+	if err != nil || result != nil {
+		t.Errorf("NULL array should give nil without error, got %q, %v", result, err)
+	}
+}
+
+func TestFromPgxInt8ArrayStrict(t *testing.T) {
+	withNull := pgtype.Array[pgtype.Int8]{Elements: []pgtype.Int8{
+		{Valid: false},
+		{Int64: 2, Valid: true},
+	}, Valid: true}
+
+	if lenient := FromPgxInt8Array(withNull); len(lenient) != 2 || lenient[0] != 0 {
+		t.Errorf("lenient conversion should turn NULL into 0, got %v", lenient)
+	}
+	result, err := FromPgxInt8ArrayStrict(withNull)
+	if !errors.Is(err, ErrNullArrayElement) || !strings.Contains(err.Error(), "index 0") || result != nil {
+		t.Errorf("expected ErrNullArrayElement at index 0, got %v, %v", result, err)
+	}
+
+	result, err = FromPgxInt8ArrayStrict(ToPgxInt8Array([]int64{1, 2}))
+	if err != nil || len(result) != 2 || result[1] != 2 {
+		t.Errorf("expected [1 2], got %v, %v", result, err)
+	}
+}
+
+func TestTimestamptzArrayRoundTrip(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	data := []time.Time{start, {}, start.Add(time.Hour)}
+
+	pgArray := ToPgxTimestamptzArray(data)
+	if !pgArray.Valid || len(pgArray.Elements) != 3 {
+		t.Fatalf("Expected valid array with 3 elements, got %+v", pgArray)
+	}
+	if pgArray.Elements[1].Valid {
+		t.Error("Expected the zero time to become a NULL element")
+	}
+
+	result := FromPgxTimestamptzArray(pgArray)
+	if len(result) != len(data) {
+		t.Fatalf("Expected %d elements, got %d", len(data), len(result))
+	}
+	for i := range data {
+		if !result[i].Equal(data[i]) {
+			t.Errorf("Element %d: expected %v, got %v", i, data[i], result[i])
+		}
+	}
+
+	if ToPgxTimestamptzArray(nil).Valid {
+		t.Error("Expected invalid array for nil slice")
+	}
+	if result := FromPgxTimestamptzArray(pgtype.Array[pgtype.Timestamptz]{Valid: false}); result != nil {
+		t.Errorf("Expected nil for invalid array, got %v", result)
+	}
+}
+
+// =============================================================================
+// BIT / VARBIT TESTS
+// =============================================================================
+
+func TestToPgxBits(t *testing.T) {
+	// B'10110' packs MSB-first into 0b10110000
+	result := ToPgxBits([]bool{true, false, true, true, false})
+	if !result.Valid || result.Len != 5 || len(result.Bytes) != 1 || result.Bytes[0] != 0xB0 {
+		t.Errorf("Expected valid 5-bit 0xB0, got valid=%v len=%d bytes=%x", result.Valid, result.Len, result.Bytes)
+	}
+
+	// Test with empty slice
+	result = ToPgxBits([]bool{})
+	if !result.Valid || result.Len != 0 || len(result.Bytes) != 0 {
+		t.Errorf("Expected valid empty bit string, got valid=%v len=%d bytes=%x", result.Valid, result.Len, result.Bytes)
+	}
+
+	// Test with nil
+	result = ToPgxBits(nil)
+	if result.Valid {
+		t.Errorf("Expected invalid bits for nil, got valid=%v", result.Valid)
+	}
+}
+
+func TestFromPgxBits(t *testing.T) {
+	result := FromPgxBits(pgtype.Bits{Bytes: []byte{0xB0}, Len: 5, Valid: true})
+	expected := []bool{true, false, true, true, false}
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d bits, got %d", len(expected), len(result))
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("bit %d: expected %v, got %v", i, expected[i], result[i])
+		}
+	}
+
+	// Test with invalid pgtype.Bits
+	result = FromPgxBits(pgtype.Bits{Valid: false})
+	if result != nil {
+		t.Errorf("Expected nil for invalid bits, got %v", result)
+	}
+}
+
+func TestPgxBitsRoundTrip(t *testing.T) {
+	for _, n := range []int{0, 1, 7, 8, 9, 15, 16, 17, 63, 64, 65} {
+		in := make([]bool, n)
+		for i := range in {
+			in[i] = i%3 == 0 || i%5 == 0
+		}
+		bits := ToPgxBits(in)
+		if int(bits.Len) != n || len(bits.Bytes) != (n+7)/8 {
+			t.Errorf("len %d: unexpected packing len=%d bytes=%d", n, bits.Len, len(bits.Bytes))
+		}
+		out := FromPgxBits(bits)
+		if len(out) != n {
+			t.Fatalf("len %d: round trip returned %d bits", n, len(out))
+		}
+		for i := range in {
+			if in[i] != out[i] {
+				t.Errorf("len %d: bit %d mismatch after round trip", n, i)
+			}
+		}
+	}
+}
+
+// =============================================================================
+// RANGE TESTS
+// =============================================================================
+
+func TestToPgxInt4Range(t *testing.T) {
+	lo, hi := int32(1), int32(10)
+	tests := []struct {
+		name      string
+		lower     *int32
+		upper     *int32
+		inclusive [2]bool
+		wantLower pgtype.BoundType
+		wantUpper pgtype.BoundType
+	}{
+		{"[1,10)", &lo, &hi, [2]bool{true, false}, pgtype.Inclusive, pgtype.Exclusive},
+		{"(1,10]", &lo, &hi, [2]bool{false, true}, pgtype.Exclusive, pgtype.Inclusive},
+		{"[1,10]", &lo, &hi, [2]bool{true, true}, pgtype.Inclusive, pgtype.Inclusive},
+		{"(,10)", nil, &hi, [2]bool{true, false}, pgtype.Unbounded, pgtype.Exclusive},
+		{"[1,)", &lo, nil, [2]bool{true, true}, pgtype.Inclusive, pgtype.Unbounded},
+		{"(,)", nil, nil, [2]bool{}, pgtype.Unbounded, pgtype.Unbounded},
+	}
+	for _, tt := range tests {
+		r := ToPgxInt4Range(tt.lower, tt.upper, tt.inclusive)
+		if !r.Valid || r.LowerType != tt.wantLower || r.UpperType != tt.wantUpper {
+			t.Errorf("%s: got valid=%v bounds %v/%v", tt.name, r.Valid, r.LowerType, r.UpperType)
+		}
+		if tt.lower != nil && (!r.Lower.Valid || r.Lower.Int32 != *tt.lower) {
+			t.Errorf("%s: unexpected lower %+v", tt.name, r.Lower)
+		}
+		if tt.upper != nil && (!r.Upper.Valid || r.Upper.Int32 != *tt.upper) {
+			t.Errorf("%s: unexpected upper %+v", tt.name, r.Upper)
+		}
+
+		b := FromPgxInt4Range(r)
+		if b == nil || b.Empty {
+			t.Fatalf("%s: expected non-empty bounds, got %+v", tt.name, b)
+		}
+		if (b.Lower == nil) != (tt.lower == nil) || (b.Upper == nil) != (tt.upper == nil) {
+			t.Errorf("%s: round trip changed boundedness: %+v", tt.name, b)
+		}
+		want := tt.inclusive
+		if tt.lower == nil {
+			want[0] = false
+		}
+		if tt.upper == nil {
+			want[1] = false
+		}
+		if b.Inclusive != want {
+			t.Errorf("%s: inclusive = %v, want %v", tt.name, b.Inclusive, want)
+		}
+	}
+}
+
+func TestPgxRangeEmptyAndNull(t *testing.T) {
+	empty := EmptyPgxRange[pgtype.Int8]()
+	if !empty.Valid || empty.LowerType != pgtype.Empty || empty.UpperType != pgtype.Empty {
+		t.Errorf("unexpected empty range %+v", empty)
+	}
+	b := FromPgxInt8Range(empty)
+	if b == nil || !b.Empty || b.Lower != nil || b.Upper != nil {
+		t.Errorf("expected empty bounds, got %+v", b)
+	}
+
+	if b := FromPgxInt8Range(pgtype.Range[pgtype.Int8]{Valid: false}); b != nil {
+		t.Errorf("expected nil for NULL range, got %+v", b)
+	}
+	if b := FromPgxDateRange(pgtype.Range[pgtype.Date]{Valid: false}); b != nil {
+		t.Errorf("expected nil for NULL range, got %+v", b)
+	}
+}
+
+func TestPgxTimeRanges(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+
+	tstz := ToPgxTimestamptzRange(&start, &end, [2]bool{true, false})
+	b := FromPgxTimestamptzRange(tstz)
+	if b == nil || !b.Lower.Equal(start) || !b.Upper.Equal(end) || b.Inclusive != [2]bool{true, false} {
+		t.Errorf("unexpected tstzrange bounds %+v", b)
+	}
+
+	dates := ToPgxDateRange(&start, nil, [2]bool{true, false})
+	if dates.UpperType != pgtype.Unbounded || !dates.Lower.Valid {
+		t.Errorf("unexpected daterange %+v", dates)
+	}
+	dr := FromPgxDateRange(dates)
+	if dr == nil || dr.Upper != nil || !dr.Lower.Equal(start) {
+		t.Errorf("unexpected daterange bounds %+v", dr)
+	}
+}
+
+func TestPgxRange_Integration(t *testing.T) {
+	pool := requireTestPool(t)
+	ctx := context.Background()
+
+	lo, hi := int32(1), int32(3)
+	var got pgtype.Range[pgtype.Int4]
+	if err := pool.QueryRow(ctx, "SELECT $1::int4range", ToPgxInt4Range(&lo, &hi, [2]bool{true, true})).Scan(&got); err != nil {
+		t.Fatalf("int4range round trip failed: %v", err)
+	}
+	// Discrete ranges come back canonicalized to [lower, upper).
+	b := FromPgxInt4Range(got)
+	if b == nil || *b.Lower != 1 || *b.Upper != 4 || b.Inclusive != [2]bool{true, false} {
+		t.Errorf("expected [1,4), got %+v", b)
+	}
+
+	var contains bool
+	if err := pool.QueryRow(ctx, "SELECT $1::int8range @> 5::int8", ToPgxInt8Range(nil, nil, [2]bool{})).Scan(&contains); err != nil || !contains {
+		t.Errorf("unbounded range should contain 5, got %v, %v", contains, err)
+	}
+
+	var isEmpty bool
+	if err := pool.QueryRow(ctx, "SELECT isempty($1::int4range)", EmptyPgxRange[pgtype.Int4]()).Scan(&isEmpty); err != nil || !isEmpty {
+		t.Errorf("expected empty range, got %v, %v", isEmpty, err)
+	}
+	if err := pool.QueryRow(ctx, "SELECT int4range(5, 5)").Scan(&got); err != nil {
+		t.Fatalf("select empty range failed: %v", err)
+	}
+	if b := FromPgxInt4Range(got); b == nil || !b.Empty {
+		t.Errorf("int4range(5,5) should read back empty, got %+v", b)
+	}
+}
+
+// =============================================================================
+// FULL-TEXT SEARCH TESTS
+// =============================================================================
+
+func TestFromPgxTSVector(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"'a':1 'cat':2A 'fat':3,5B", []string{"a", "cat", "fat"}},
+		{`'it''s' 'back\\slash':4 'x y'`, []string{"it's", `back\slash`, "x y"}},
+		{"'c++':1 ':colon'", []string{"c++", ":colon"}},
+		{"plain other:2", []string{"plain", "other"}},
+		{"", []string{}},
+	}
+	for _, tt := range tests {
+		got := FromPgxTSVector(pgtype.Text{String: tt.in, Valid: true})
+		if len(got) != len(tt.want) {
+			t.Errorf("FromPgxTSVector(%q) = %q, want %q", tt.in, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("FromPgxTSVector(%q) = %q, want %q", tt.in, got, tt.want)
+				break
+			}
+		}
+	}
+
+	// Test with invalid pgtype.Text
+	if result := FromPgxTSVector(pgtype.Text{Valid: false}); result != nil {
+		t.Errorf("Expected nil for invalid text, got %v", result)
+	}
+}
+
+func TestToPgxTSQuery(t *testing.T) {
+	tests := []struct {
+		terms []string
+		op    string
+		want  string
+	}{
+		{[]string{"fat", "cat"}, "&", "'fat' & 'cat'"},
+		{[]string{"fat", "cat"}, "|", "'fat' | 'cat'"},
+		{[]string{"fat", "cat"}, "<->", "'fat' <-> 'cat'"},
+		{[]string{"fat", "cat"}, "; DROP", "'fat' & 'cat'"},
+		{[]string{"it's", `a\b`, "!(x|y):*"}, "&", `'it''s' & 'a\\b' & '!(x|y):*'`},
+		{[]string{"", "only", ""}, "&", "'only'"},
+		{nil, "&", ""},
+	}
+	for _, tt := range tests {
+		if got := ToPgxTSQuery(tt.terms, tt.op); got != tt.want {
+			t.Errorf("ToPgxTSQuery(%q, %q) = %q, want %q", tt.terms, tt.op, got, tt.want)
+		}
+	}
+}
+
+func TestToPgxTSQuery_Integration(t *testing.T) {
+	pool := requireTestPool(t)
+	ctx := context.Background()
+
+	terms := []string{"it's", "fat&cat", `back\slash`, "(paren)", "!not", "star:*"}
+	for _, op := range []string{"&", "|", "<->"} {
+		var matched bool
+		err := pool.QueryRow(ctx,
+			"SELECT to_tsvector('simple', $1) @@ to_tsquery('simple', $2)",
+			strings.Join(terms, " "), ToPgxTSQuery(terms, op)).Scan(&matched)
+		if err != nil {
+			t.Fatalf("op %q: to_tsquery rejected built query: %v", op, err)
+		}
+	}
+
+	var tsv pgtype.Text
+	if err := pool.QueryRow(ctx, "SELECT to_tsvector('simple', 'The fat cats')::text").Scan(&tsv); err != nil {
+		t.Fatalf("select tsvector: %v", err)
+	}
+	got := FromPgxTSVector(tsv)
+	if len(got) != 3 || got[0] != "cats" || got[1] != "fat" || got[2] != "the" {
+		t.Errorf("unexpected lexemes %q from %q", got, tsv.String)
+	}
+}
+
+// =============================================================================
+// HSTORE TESTS
+// =============================================================================
+
+func TestHstoreConversions(t *testing.T) {
+	if h := ToPgxHstore(nil); h != nil {
+		t.Errorf("nil map should become a NULL hstore, got %v", h)
+	}
+	if m := FromPgxHstore(nil); m != nil {
+		t.Errorf("NULL hstore should become a nil map, got %v", m)
+	}
+
+	empty := ToPgxHstore(map[string]*string{})
+	if empty == nil || len(empty) != 0 {
+		t.Errorf("empty map should become an empty, non-NULL hstore, got %#v", empty)
+	}
+	if m := FromPgxHstore(pgtype.Hstore{}); m == nil || len(m) != 0 {
+		t.Errorf("empty hstore should become an empty map, got %#v", m)
+	}
+
+	value := "blue"
+	in := map[string]*string{
+		"color":           &value,
+		"retired":         nil,
+		`quote"d`:         &value,
+		`back\slash`:      &value,
+		"comma, => arrow": &value,
+		"":                &value,
+	}
+	out := FromPgxHstore(ToPgxHstore(in))
+	if len(out) != len(in) {
+		t.Fatalf("expected %d keys, got %d", len(in), len(out))
+	}
+	for k, v := range in {
+		got, ok := out[k]
+		if !ok {
+			t.Errorf("key %q missing", k)
+			continue
+		}
+		if (v == nil) != (got == nil) || (v != nil && *v != *got) {
+			t.Errorf("key %q: got %v, want %v", k, got, v)
+		}
+	}
+
+	in["added"] = &value
+	if _, ok := out["added"]; ok {
+		t.Error("result should not share storage with the input map")
+	}
+}
+
+func TestHstoreIntegration(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+	ctx := context.Background()
+
+	// A dedicated connection keeps the hstore type registration away from the
+	// shared test pool; closing it drops the registration.
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close(context.Background()) })
+
+	var existed bool
+	if err := conn.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'hstore')").Scan(&existed); err != nil {
+		t.Fatalf("check hstore extension: %v", err)
+	}
+	if !existed {
+		if _, err := conn.Exec(ctx, "CREATE EXTENSION hstore"); err != nil {
+			t.Skipf("hstore extension not available: %v", err)
+		}
+		t.Cleanup(func() {
+			if _, err := conn.Exec(context.Background(), "DROP EXTENSION IF EXISTS hstore"); err != nil {
+				t.Errorf("drop hstore extension: %v", err)
+			}
+		})
+	}
+
+	dt, err := conn.LoadType(ctx, "hstore")
+	if err != nil {
+		t.Fatalf("load hstore type: %v", err)
+	}
+	conn.TypeMap().RegisterType(dt)
+
+	value := `a "quoted", value`
+	in := map[string]*string{"tag=>key": &value, "gone": nil}
+
+	var h pgtype.Hstore
+	if err := conn.QueryRow(ctx, "SELECT $1::hstore", ToPgxHstore(in)).Scan(&h); err != nil {
+		t.Fatalf("hstore round trip: %v", err)
+	}
+	out := FromPgxHstore(h)
+	if len(out) != 2 || out["gone"] != nil || out["tag=>key"] == nil || *out["tag=>key"] != value {
+		t.Errorf("unexpected hstore round trip result %v", out)
+	}
+}
+
+// =============================================================================
+// GENERIC NULLABLE TESTS
+// =============================================================================
+
+func TestNullableConversions(t *testing.T) {
+	s := "hello"
+	if n := ToNullable(&s); !n.Valid || n.V != "hello" {
+		t.Errorf("expected valid hello, got %+v", n)
+	}
+	if got := FromNullable(ToNullable(&s)); got == nil || *got != "hello" {
+		t.Errorf("expected hello round trip, got %v", got)
+	}
+
+	i := 42
+	if got := FromNullable(ToNullable(&i)); got == nil || *got != 42 {
+		t.Errorf("expected 42 round trip, got %v", got)
+	}
+
+	now := time.Now()
+	if got := FromNullable(ToNullable(&now)); got == nil || !got.Equal(now) {
+		t.Errorf("expected time round trip, got %v", got)
+	}
+
+	if n := ToNullable[string](nil); n.Valid {
+		t.Errorf("nil pointer should be invalid, got %+v", n)
+	}
+	if got := FromNullable(Nullable[int]{}); got != nil {
+		t.Errorf("invalid Nullable should give nil, got %v", *got)
+	}
+
+	zero := 0
+	n := ToNullable(&zero)
+	if !n.Valid {
+		t.Error("pointer to a zero value should be valid")
+	}
+	if got := FromNullable(n); got == nil || *got != 0 {
+		t.Errorf("zero value should survive the round trip, got %v", got)
+	}
+	empty := ""
+	if got := FromNullable(ToNullable(&empty)); got == nil {
+		t.Error("empty string should not become NULL")
+	}
+}
+
+func TestNullableValue(t *testing.T) {
+	v, err := Nullable[int]{}.Value()
+	if err != nil || v != nil {
+		t.Errorf("invalid Nullable should be NULL, got %v, %v", v, err)
+	}
+	v, err = Nullable[int]{V: 0, Valid: true}.Value()
+	if err != nil || v != 0 {
+		t.Errorf("valid zero should be 0, got %v, %v", v, err)
+	}
+
+	id := uuid.MustParse("3f0b6d2a-6f1e-4c5e-9a43-2f2b7f0c1d11")
+	v, err = Nullable[uuid.UUID]{V: id, Valid: true}.Value()
+	if err != nil || v != id.String() {
+		t.Errorf("T's own Valuer should be used, got %v, %v", v, err)
+	}
+}
+
+func TestNullableScan(t *testing.T) {
+	var s Nullable[string]
+	if err := s.Scan([]byte("bytes")); err != nil || !s.Valid || s.V != "bytes" {
+		t.Errorf("expected bytes, got %+v, %v", s, err)
+	}
+	if err := s.Scan(nil); err != nil || s.Valid || s.V != "" {
+		t.Errorf("NULL should reset to zero value, got %+v, %v", s, err)
+	}
+
+	var i32 Nullable[int32]
+	if err := i32.Scan(int64(7)); err != nil || !i32.Valid || i32.V != 7 {
+		t.Errorf("expected 7, got %+v, %v", i32, err)
+	}
+	if err := i32.Scan(int64(math.MaxInt32) + 1); !errors.Is(err, ErrIntOverflow) {
+		t.Errorf("expected ErrIntOverflow, got %v", err)
+	}
+
+	var u Nullable[uint16]
+	if err := u.Scan(int64(-1)); !errors.Is(err, ErrIntOverflow) {
+		t.Errorf("expected ErrIntOverflow for negative uint, got %v", err)
+	}
+
+	var f Nullable[float64]
+	if err := f.Scan(int64(3)); err != nil || f.V != 3 {
+		t.Errorf("expected 3, got %+v, %v", f, err)
+	}
+
+	var ts Nullable[time.Time]
+	now := time.Now()
+	if err := ts.Scan(now); err != nil || !ts.V.Equal(now) {
+		t.Errorf("expected time, got %+v, %v", ts, err)
+	}
+	if err := ts.Scan("not a time"); err == nil {
+		t.Error("expected error scanning a string into Nullable[time.Time]")
+	}
+
+	var id Nullable[uuid.UUID]
+	if err := id.Scan("3f0b6d2a-6f1e-4c5e-9a43-2f2b7f0c1d11"); err != nil || !id.Valid {
+		t.Errorf("T's own Scanner should be used, got %+v, %v", id, err)
+	}
+
+	var n Nullable[int]
+	if err := n.Scan("12"); err == nil {
+		t.Error("text should not be converted to a number")
+	}
+}
+
+func TestNullableIntegration(t *testing.T) {
+	pool := requireTestPool(t)
+	ctx := context.Background()
+
+	var name Nullable[string]
+	var count Nullable[int32]
+	var at Nullable[time.Time]
+	err := pool.QueryRow(ctx, "SELECT $1::text, $2::int4, $3::timestamptz",
+		Nullable[string]{V: "", Valid: true}, Nullable[int32]{}, Nullable[time.Time]{V: time.Unix(0, 0), Valid: true},
+	).Scan(&name, &count, &at)
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if !name.Valid || name.V != "" {
+		t.Errorf("empty string should stay non-NULL, got %+v", name)
+	}
+	if count.Valid {
+		t.Errorf("NULL int4 should be invalid, got %+v", count)
+	}
+	if !at.Valid || !at.V.Equal(time.Unix(0, 0)) {
+		t.Errorf("expected epoch, got %+v", at)
+	}
+}
+
 // =============================================================================
 // BYTES TESTS
 // =============================================================================
@@ -0,0 +1,146 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// WorkerRetryConfig tunes DB.WorkerTransaction. Zero fields take the
+// defaults noted on each; a negative MaxDeadlockRetries or IdleBackoff
+// disables that behaviour.
+type WorkerRetryConfig struct {
+	// TxOptions are passed to BeginTx for every attempt.
+	TxOptions pgx.TxOptions
+
+	// MaxDeadlockRetries is how many times an attempt that fails with a
+	// deadlock (SQLSTATE 40P01) is retried. Default 3.
+	MaxDeadlockRetries int
+	// BaseDelay and MaxDelay bound the exponential backoff between deadlock
+	// retries. Each wait is jittered to between half and all of the current
+	// delay so workers that deadlocked together don't retry in lockstep.
+	// Defaults 10ms and 500ms.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// IdleBackoff is how long WorkerTransaction waits, jittered the same
+	// way, before reporting that no work was claimed, so a polling loop
+	// doesn't spin on an empty queue. Default 1s.
+	IdleBackoff time.Duration
+}
+
+func (c WorkerRetryConfig) withDefaults() WorkerRetryConfig {
+	if c.MaxDeadlockRetries == 0 {
+		c.MaxDeadlockRetries = 3
+	}
+	if c.BaseDelay <= 0 {
+		c.BaseDelay = 10 * time.Millisecond
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = 500 * time.Millisecond
+	}
+	if c.MaxDelay < c.BaseDelay {
+		c.MaxDelay = c.BaseDelay
+	}
+	if c.IdleBackoff == 0 {
+		c.IdleBackoff = time.Second
+	}
+	return c
+}
+
+// WorkerTransaction runs fn in a transaction tuned for job-queue workers that
+// claim rows with FOR UPDATE SKIP LOCKED. fn reports whether it claimed any
+// work; finding none is not an error. The transaction is committed whenever
+// fn returns a nil error and rolled back otherwise.
+//
+// Only deadlocks are retried, with jittered exponential backoff, because they
+// are the one failure a claim loop routinely hits and can safely repeat; any
+// other error is returned as is. When fn claims nothing, WorkerTransaction
+// waits IdleBackoff before returning (false, nil). Cancelling ctx during a
+// wait returns ctx.Err().
+//
+// Example:
+//
+//	for {
+//	    _, err := db.WorkerTransaction(ctx, pgxkit.WorkerRetryConfig{}, func(tx *pgxkit.Tx) (bool, error) {
+//	        var id int64
+//	        err := tx.QueryRow(ctx, `SELECT id FROM jobs WHERE state = 'queued'
+//	            ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED`).Scan(&id)
+//	        if errors.Is(err, pgx.ErrNoRows) {
+//	            return false, nil
+//	        }
+//	        if err != nil {
+//	            return false, err
+//	        }
+//	        return true, runJob(ctx, tx, id)
+//	    })
+//	    if ctx.Err() != nil {
+//	        return
+//	    }
+//	    if err != nil {
+//	        log.Printf("worker: %v", err)
+//	    }
+//	}
+func (db *DB) WorkerTransaction(ctx context.Context, cfg WorkerRetryConfig, fn func(tx *Tx) (claimed bool, err error)) (bool, error) {
+	cfg = cfg.withDefaults()
+	delay := cfg.BaseDelay
+
+	for attempt := 0; ; attempt++ {
+		claimed, err := db.workerAttempt(ctx, cfg.TxOptions, fn)
+		if err == nil {
+			if !claimed && cfg.IdleBackoff > 0 {
+				if err := sleepJittered(ctx, cfg.IdleBackoff); err != nil {
+					return false, err
+				}
+			}
+			return claimed, nil
+		}
+
+		if _, deadlock := pgErrorWithCode(err, "40P01"); !deadlock {
+			return false, err
+		}
+		if attempt >= cfg.MaxDeadlockRetries {
+			return false, fmt.Errorf("worker transaction deadlocked after %d attempts: %w", attempt+1, err)
+		}
+		if err := sleepJittered(ctx, delay); err != nil {
+			return false, err
+		}
+		delay = min(delay*2, cfg.MaxDelay)
+	}
+}
+
+// workerAttempt runs fn in one transaction, committing on success.
+func (db *DB) workerAttempt(ctx context.Context, opts pgx.TxOptions, fn func(tx *Tx) (bool, error)) (bool, error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return false, err
+	}
+	claimed, err := fn(tx)
+	if err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			return false, errors.Join(err, rbErr)
+		}
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return claimed, nil
+}
+
+// sleepJittered waits a random duration between d/2 and d, or until ctx ends.
+func sleepJittered(ctx context.Context, d time.Duration) error {
+	wait := d/2 + rand.N(d/2+1)
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,141 @@
+package pgxkit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fastWorkerConfig keeps backoff waits short enough for unit tests.
+var fastWorkerConfig = WorkerRetryConfig{
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    2 * time.Millisecond,
+	IdleBackoff: -1,
+}
+
+func TestWorkerTransactionClaimed(t *testing.T) {
+	db := NewDB()
+	ctx, release := withConnAffinity(context.Background(), db, &fakeAffinityPool{})
+	defer release()
+
+	calls := 0
+	claimed, err := db.WorkerTransaction(ctx, fastWorkerConfig, func(tx *Tx) (bool, error) {
+		calls++
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("WorkerTransaction failed: %v", err)
+	}
+	if !claimed || calls != 1 {
+		t.Errorf("expected one claiming call, got claimed=%v calls=%d", claimed, calls)
+	}
+	if db.InFlight() != 0 {
+		t.Errorf("transaction should be finalized, %d operations in flight", db.InFlight())
+	}
+}
+
+func TestWorkerTransactionNoWorkBacksOff(t *testing.T) {
+	db := NewDB()
+	ctx, release := withConnAffinity(context.Background(), db, &fakeAffinityPool{})
+	defer release()
+
+	cfg := fastWorkerConfig
+	cfg.IdleBackoff = 40 * time.Millisecond
+	start := time.Now()
+	claimed, err := db.WorkerTransaction(ctx, cfg, func(tx *Tx) (bool, error) {
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("no work should not be an error, got %v", err)
+	}
+	if claimed {
+		t.Error("expected claimed=false")
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("expected an idle backoff of at least 20ms, returned after %v", elapsed)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := db.WorkerTransaction(cancelled, cfg, func(tx *Tx) (bool, error) {
+		return false, nil
+	}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancellation during idle backoff, got %v", err)
+	}
+}
+
+func TestWorkerTransactionRetriesDeadlocks(t *testing.T) {
+	db := NewDB()
+	ctx, release := withConnAffinity(context.Background(), db, &fakeAffinityPool{})
+	defer release()
+
+	calls := 0
+	claimed, err := db.WorkerTransaction(ctx, fastWorkerConfig, func(tx *Tx) (bool, error) {
+		calls++
+		if calls < 3 {
+			return false, &pgconn.PgError{Code: "40P01"}
+		}
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("expected deadlocks to be retried, got %v", err)
+	}
+	if !claimed || calls != 3 {
+		t.Errorf("expected success on the third attempt, got claimed=%v calls=%d", claimed, calls)
+	}
+
+	calls = 0
+	_, err = db.WorkerTransaction(ctx, fastWorkerConfig, func(tx *Tx) (bool, error) {
+		calls++
+		return false, &pgconn.PgError{Code: "40P01"}
+	})
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "40P01" {
+		t.Fatalf("expected the deadlock error after exhausting retries, got %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("expected 1 attempt + 3 retries, got %d", calls)
+	}
+}
+
+func TestWorkerTransactionDoesNotRetryOtherErrors(t *testing.T) {
+	db := NewDB()
+	ctx, release := withConnAffinity(context.Background(), db, &fakeAffinityPool{})
+	defer release()
+
+	for _, failure := range []error{
+		&pgconn.PgError{Code: "40001"},
+		errors.New("job failed"),
+	} {
+		calls := 0
+		_, err := db.WorkerTransaction(ctx, fastWorkerConfig, func(tx *Tx) (bool, error) {
+			calls++
+			return true, failure
+		})
+		if !errors.Is(err, failure) {
+			t.Errorf("expected %v to be returned, got %v", failure, err)
+		}
+		if calls != 1 {
+			t.Errorf("%v should not be retried, got %d attempts", failure, calls)
+		}
+	}
+}
+
+func TestWorkerRetryConfigDefaults(t *testing.T) {
+	cfg := WorkerRetryConfig{}.withDefaults()
+	if cfg.MaxDeadlockRetries != 3 || cfg.BaseDelay != 10*time.Millisecond ||
+		cfg.MaxDelay != 500*time.Millisecond || cfg.IdleBackoff != time.Second {
+		t.Errorf("unexpected defaults: %+v", cfg)
+	}
+
+	cfg = WorkerRetryConfig{MaxDeadlockRetries: -1, BaseDelay: time.Second, MaxDelay: time.Millisecond}.withDefaults()
+	if cfg.MaxDeadlockRetries != -1 {
+		t.Errorf("negative MaxDeadlockRetries should disable retries, got %d", cfg.MaxDeadlockRetries)
+	}
+	if cfg.MaxDelay != time.Second {
+		t.Errorf("MaxDelay should be raised to BaseDelay, got %v", cfg.MaxDelay)
+	}
+}